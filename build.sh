@@ -22,4 +22,5 @@ rm -rf ./bin/Dynamo*
 
 # Build and install the necessary binaries for scripts to run
 go get github.com/go-ini/ini
+go get google.golang.org/grpc google.golang.org/protobuf
 go install ./src/mydynamo/...
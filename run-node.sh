@@ -0,0 +1,3 @@
+#!/bin/bash
+# shellcheck disable=SC2068
+./bin/DynamoNode $@
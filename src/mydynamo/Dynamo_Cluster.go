@@ -0,0 +1,326 @@
+package mydynamo
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Arguments of the DropHandedOffEntries RPC
+type HandoffArgs struct {
+	Node    DynamoNode          //The node the entries were handed off to
+	Digests map[string][]uint64 //Digests of the handed off entries by key, see `entryDigest`
+}
+
+// Returns the nodes in the cluster known by this server, starting with this server
+func (s *DynamoServer) GetNodes(_ Empty, result *[]DynamoNode) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+
+	nodes, _ := s.topology()
+	if len(nodes) == 0 {
+		nodes = []DynamoNode{s.selfNode}
+	}
+	*result = nodes
+	return nil
+}
+
+// Returns the local entries of the keys the given node is in the preference lists of
+// This is an internal method used by a joining server to stream the key ranges it owns from this server (through
+// RPC).
+func (s *DynamoServer) GetEntriesOfNode(node DynamoNode, result *map[string][]ObjectEntry) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+
+	entries := make(map[string][]ObjectEntry)
+	for _, key := range s.storage.GetKeys() {
+		if !s.isPreferredNodeOfKey(node, key) {
+			continue
+		}
+
+		s.storage.RLock(key)
+		localEntries, err := s.storage.Get(key)
+		s.storage.RUnlock(key)
+		if err != nil {
+			return err
+		}
+		if len(localEntries) > 0 {
+			entries[key] = localEntries
+		}
+	}
+
+	*result = entries
+	return nil
+}
+
+// Adds the node to the cluster of this server, and returns the nodes in the cluster with the node
+// This is an internal method used by a joining server to be assigned its ring positions (through RPC). The hash ring
+// with the node is sent to all servers in the cluster. Nodes joining through the same server are added one at a time.
+func (s *DynamoServer) AddNode(node DynamoNode, result *[]DynamoNode) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+	if s.virtualNodes <= 0 {
+		return errors.New("joining a cluster requires virtual nodes")
+	}
+
+	s.joinMutex.Lock()
+	defer s.joinMutex.Unlock()
+
+	nodes, _ := s.topology()
+	if len(nodes) == 0 {
+		return errors.New("server is not in a cluster yet")
+	}
+	newNodes := append(removeDynamoNode(nodes, node), node)
+	if err := s.sendTopology(newNodes); err != nil {
+		return err
+	}
+
+	*result = newNodes
+	return nil
+}
+
+// Adds this server to the cluster of the seed server
+// The seed server assigns this server its ring positions, then this server streams the entries of the keys it owns
+// from the other servers, which then drop the streamed entries of the keys they no longer own. Requires virtual nodes.
+// If the seed server fails to send the hash ring to some servers after sending it to this server, this server still
+// streams the entries of its keys from the servers that know the ring, and returns the error of the seed server.
+func (s *DynamoServer) Join(seed DynamoNode, _ *Empty) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+	if s.virtualNodes <= 0 {
+		return errors.New("joining a cluster requires virtual nodes")
+	}
+
+	var newNodes []DynamoNode
+	err := s.connections.Call(seed, "MyDynamo.AddNode", s.selfNode, &newNodes)
+	if err != nil {
+		err = fmt.Errorf("failed to join the cluster of seed %v: %v", seed, err)
+		if newNodes, _ = s.topology(); !containsDynamoNode(newNodes, s.selfNode) {
+			return err
+		}
+	}
+
+	for _, node := range newNodes {
+		if node == s.selfNode {
+			continue
+		}
+
+		var entries map[string][]ObjectEntry
+		if callErr := s.connections.Call(node, "MyDynamo.GetEntriesOfNode", s.selfNode, &entries); callErr != nil {
+			log.Println(DYNAMO_SERVER, "Failed to stream entries from", node, ":", callErr)
+			err = callErr
+			continue
+		}
+		digests := make(map[string][]uint64, len(entries))
+		for key, keyEntries := range entries {
+			for _, entry := range keyEntries {
+				if putErr := s.putLocalEntry(NewPutArgsFromEntry(key, entry), true); putErr != nil {
+					return putErr
+				}
+				digests[key] = append(digests[key], entryDigest(entry))
+			}
+		}
+
+		var droppedCount int
+		if callErr := s.connections.Call(node, "MyDynamo.DropHandedOffEntries", HandoffArgs{Node: s.selfNode, Digests: digests}, &droppedCount); callErr != nil {
+			// The entries are left on the node, which no longer serves reads of their keys
+			log.Println(DYNAMO_SERVER, "Failed to drop the entries handed off by", node, ":", callErr)
+		}
+	}
+	return err
+}
+
+// Removes the local entries handed off to a joining node from the keys this server no longer owns, and returns the
+// number of entries removed
+// This is an internal method used by a joining server once it has stored the entries streamed from this server
+// (through RPC). Entries this server received after streaming the key are kept, and replicated to the owners of the
+// key by gossip.
+func (s *DynamoServer) DropHandedOffEntries(args HandoffArgs, result *int) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+
+	droppedCount := 0
+	for key, digests := range args.Digests {
+		if s.isPreferredNodeOfKey(s.selfNode, key) || !s.isPreferredNodeOfKey(args.Node, key) {
+			continue
+		}
+
+		count, err := s.dropEntriesOfKey(key, digests)
+		if err != nil {
+			return err
+		}
+		droppedCount += count
+	}
+
+	*result = droppedCount
+	return nil
+}
+
+// Removes the local entries of the key with the given digests, and returns the number of entries removed
+// Like the removal of collected tombstones, the removal is not written to the write-ahead log, so the entries come back
+// if the server restarts before its next snapshot.
+func (s *DynamoServer) dropEntriesOfKey(key string, digests []uint64) (int, error) {
+	s.storage.Lock(key)
+	defer s.storage.Unlock(key)
+
+	localEntries, err := s.storage.Get(key)
+	if err != nil {
+		return 0, err
+	}
+
+	keptEntries := make([]ObjectEntry, 0, len(localEntries))
+	removedRecords := make([]PutRecord, 0)
+	for _, entry := range localEntries {
+		if containsDigest(digests, entryDigest(entry)) {
+			removedRecords = append(removedRecords, NewPutRecord(key, entry.Context))
+		} else {
+			keptEntries = append(keptEntries, entry)
+		}
+	}
+	if len(removedRecords) == 0 {
+		return 0, nil
+	}
+
+	if err := s.storage.Put(key, keptEntries); err != nil {
+		return 0, err
+	}
+	s.nodePutRecords.ExecAtomic(func() {
+		for _, putRecord := range removedRecords {
+			s.nodePutRecords.DeletePutRecord(putRecord)
+		}
+	})
+	return len(removedRecords), nil
+}
+
+// Adds this server to the cluster of the first seed server it can join, seeds equal to this server are skipped
+// This server must not be in a cluster yet. Returns the error of the last seed if no seed could be joined.
+func (s *DynamoServer) JoinSeeds(seeds []DynamoNode, _ *Empty) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+
+	err := errors.New("no seed to join")
+	for _, seed := range seeds {
+		if seed == s.selfNode {
+			continue
+		}
+
+		var empty Empty
+		err = s.Join(seed, &empty)
+		if nodes, _ := s.topology(); containsDynamoNode(nodes, s.selfNode) {
+			// Entries that failed to stream are left to be replicated by gossip and read repair
+			if err != nil {
+				log.Println(DYNAMO_SERVER, err)
+			}
+			log.Println(DYNAMO_SERVER, "Joined the cluster of seed", seed)
+			return nil
+		}
+		log.Println(DYNAMO_SERVER, err)
+	}
+	return err
+}
+
+// Removes this server from its cluster and shuts it down
+// The hash ring without this server is sent to the other servers, then this server streams its entries to the new
+// owners of its keys and hands off its hints before shutting down. The server is not shut down if the entries of a key
+// could not be streamed to any of its owners. Requires virtual nodes.
+func (s *DynamoServer) Decommission(_ Empty, _ *Empty) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+	if s.virtualNodes <= 0 {
+		return errors.New("decommissioning a node requires virtual nodes")
+	}
+
+	nodes, _ := s.topology()
+	newNodes := removeDynamoNode(nodes, s.selfNode)
+	if len(newNodes) == 0 {
+		return errors.New("cannot decommission the last node of the cluster")
+	}
+
+	if err := s.sendTopology(newNodes); err != nil {
+		return err
+	}
+	// This server is no longer in the ring, so Puts it receives are forwarded to the new owners
+	s.setTopology(newNodes)
+
+	for _, key := range s.storage.GetKeys() {
+		s.storage.RLock(key)
+		localEntries, err := s.storage.Get(key)
+		s.storage.RUnlock(key)
+		if err != nil {
+			return err
+		}
+
+		streamedCount := 0
+		for _, node := range s.preferenceListOfKey(key) {
+			if s.streamEntriesTo(node, key, localEntries) {
+				streamedCount++
+			}
+		}
+		if streamedCount == 0 && len(localEntries) > 0 {
+			return fmt.Errorf("failed to stream %q to any of its owners", key)
+		}
+	}
+	if _, err := s.deliverHints(); err != nil {
+		log.Println(DYNAMO_SERVER, "Failed to hand off hints:", err)
+	}
+
+	// Shut down once the response is sent
+	time.AfterFunc(time.Duration(DECOMMISSION_SHUTDOWN_DELAY_MS)*time.Millisecond, s.Shutdown)
+	return nil
+}
+
+// Puts the entries of the key to the node, and returns true if all entries are put
+func (s *DynamoServer) streamEntriesTo(node DynamoNode, key string, entries []ObjectEntry) bool {
+	for _, entry := range entries {
+		var result bool
+		if err := s.connections.Call(node, "MyDynamo.PutRaw", NewPutArgsFromEntry(key, entry), &result); err != nil || !result {
+			log.Println(DYNAMO_SERVER, "Failed to stream", key, "to", node, ":", err)
+			return false
+		}
+	}
+	return true
+}
+
+// Sends the list of nodes to each node in the list
+// Nodes joining the cluster are sent the list first, so the other nodes never forward requests to a node that does not
+// know the cluster yet.
+func (s *DynamoServer) sendTopology(nodes []DynamoNode) error {
+	currentNodes, _ := s.topology()
+	order := make([]int, 0, len(nodes))
+	for i, node := range nodes {
+		if !containsDynamoNode(currentNodes, node) {
+			order = append(order, i)
+		}
+	}
+	for i, node := range nodes {
+		if containsDynamoNode(currentNodes, node) {
+			order = append(order, i)
+		}
+	}
+
+	var err error
+	for _, i := range order {
+		node := nodes[i]
+		nodeList := append(append([]DynamoNode{}, nodes[i:]...), nodes[:i]...)
+		if node == s.selfNode {
+			s.setTopology(nodeList)
+			s.membership.SetNodes(nodeList)
+			continue
+		}
+
+		var empty Empty
+		if callErr := s.connections.Call(node, "MyDynamo.SendPreferenceList", nodeList, &empty); callErr != nil {
+			log.Println(DYNAMO_SERVER, "Failed to send nodes to", node, ":", callErr)
+			err = callErr
+		}
+	}
+	return err
+}
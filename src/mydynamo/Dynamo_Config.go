@@ -0,0 +1,336 @@
+package mydynamo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Configurations of a Dynamo cluster, loaded from the "mydynamo" section of the config file
+type DynamoConfig struct {
+	StartingPort int    //Port of the first server, the ith server listens on StartingPort + i
+	RValue       int    //Number of nodes to read from on each Get
+	WValue       int    //Number of nodes to write to on each Put
+	NValue       int    //Number of nodes storing each key, all nodes in the cluster if 0
+	ClusterSize  int    //Number of servers in the cluster
+	DataDir      string //Root directory of the per-node data directories, persistence is disabled if empty
+
+	SnapshotIntervalSeconds int //Seconds between two snapshots of a node's state, snapshots are disabled if 0
+	SnapshotRetention       int //Number of most recent snapshots kept on the disk
+
+	StorageEngine      string            //Storage engine of the nodes, "memory" or "disk"
+	NodeStorageEngines map[string]string //Storage engines of specific nodes by node ID, overriding StorageEngine
+
+	VirtualNodes int //Number of virtual nodes of each node on the hash ring, keys are not placed by the ring if 0
+
+	SloppyQuorum            bool //Whether to put writes for unavailable nodes to the next nodes as hints
+	HintedHandoffIntervalMs int  //Milliseconds between two attempts to hand off hints, hints are not handed off if 0
+
+	GossipIntervalMs int    //Milliseconds between two rounds of background gossip, background gossip is disabled if 0
+	GossipJitterMs   int    //Maximum random milliseconds added to each gossip interval
+	GossipFanout     int    //Number of random peers to gossip with in each round, all peers if 0
+	GossipMode       string //Direction entries are replicated by gossip, "push", "pull" or "push-pull"
+
+	ReadRepair string //When Get writes the merged entries back to stale replicas, "off", "async" or "sync"
+
+	RequestTimeoutMs int //Milliseconds to wait for each replica on Put and Get before trying the next one, no limit if 0
+
+	ConnectionPoolMaxIdle           int //Maximum number of idle connections kept to each other node
+	ConnectionHealthCheckIntervalMs int //Milliseconds between two health checks of idle connections, disabled if 0
+
+	HeartbeatIntervalMs int //Milliseconds between two heartbeats of a node, membership gossip is disabled if 0
+	SuspectTimeoutMs    int //Milliseconds without a new heartbeat before a node is suspected
+	DeadTimeoutMs       int //Milliseconds without a new heartbeat before a node is considered dead
+
+	TombstoneGracePeriodMs int //Milliseconds a tombstone is kept for at least before it can be garbage collected
+	TombstoneGCIntervalMs  int //Milliseconds between two tombstone garbage collections, disabled if 0
+
+	VectorClockMaxEntries int    //Maximum number of node entries of the vector clock of a put value, unbounded if 0
+	Causality             string //Causality mechanism of the put values, "vector-clock" or "dotted-version-vector"
+
+	Transport            string //Transports served by each node, "rpc", "grpc" or "both"
+	ReplicationTransport string //Transport used by the nodes to replicate entries to each other, "rpc" or "grpc"
+	GRPCPortOffset       int    //Offset added to the port of a node to get its gRPC port, if both transports are served
+}
+
+// Creates a new DynamoConfig with default values
+func NewDynamoConfig() DynamoConfig {
+	return DynamoConfig{
+		StartingPort: 8080,
+		RValue:       1,
+		WValue:       1,
+		NValue:       0,
+		ClusterSize:  1,
+		DataDir:      "",
+
+		SnapshotIntervalSeconds: DEFAULT_SNAPSHOT_INTERVAL_SECONDS,
+		SnapshotRetention:       DEFAULT_SNAPSHOT_RETENTION,
+
+		StorageEngine:      STORAGE_ENGINE_MEMORY,
+		NodeStorageEngines: make(map[string]string),
+
+		VirtualNodes: 0,
+
+		SloppyQuorum:            false,
+		HintedHandoffIntervalMs: DEFAULT_HINTED_HANDOFF_INTERVAL_MS,
+
+		GossipIntervalMs: 0,
+		GossipJitterMs:   0,
+		GossipFanout:     0,
+		GossipMode:       GOSSIP_MODE_PUSH,
+
+		ReadRepair: READ_REPAIR_OFF,
+
+		RequestTimeoutMs: DEFAULT_REQUEST_TIMEOUT_MS,
+
+		ConnectionPoolMaxIdle:           DEFAULT_CONNECTION_POOL_MAX_IDLE,
+		ConnectionHealthCheckIntervalMs: DEFAULT_CONNECTION_HEALTH_CHECK_INTERVAL_MS,
+
+		HeartbeatIntervalMs: 0,
+		SuspectTimeoutMs:    DEFAULT_SUSPECT_TIMEOUT_MS,
+		DeadTimeoutMs:       DEFAULT_DEAD_TIMEOUT_MS,
+
+		TombstoneGracePeriodMs: DEFAULT_TOMBSTONE_GRACE_PERIOD_MS,
+		TombstoneGCIntervalMs:  0,
+
+		VectorClockMaxEntries: 0,
+		Causality:             CAUSALITY_VECTOR_CLOCK,
+
+		Transport:            TRANSPORT_RPC,
+		ReplicationTransport: TRANSPORT_RPC,
+		GRPCPortOffset:       DEFAULT_GRPC_PORT_OFFSET,
+	}
+}
+
+// Returns the storage engine of the node with the given ID
+func (c *DynamoConfig) StorageEngineOfNode(id string) string {
+	if storageEngine, ok := c.NodeStorageEngines[id]; ok {
+		return storageEngine
+	}
+	return c.StorageEngine
+}
+
+// Sets the configuration with the given label (the key name in the config file) from a string value
+// Labels in the form "storage_engine.<node ID>" set the storage engine of a single node.
+// Returns an error if the label is unknown or the value is of the wrong type.
+func (c *DynamoConfig) SetOption(label string, value string) error {
+	var err error
+
+	if strings.HasPrefix(label, STORAGE_ENGINE+".") {
+		if err = checkStorageEngineName(value); err != nil {
+			return fmt.Errorf("invalid value %q for config label %q: %v", value, label, err)
+		}
+		c.NodeStorageEngines[strings.TrimPrefix(label, STORAGE_ENGINE+".")] = value
+		return nil
+	}
+
+	switch label {
+	case SERVER_PORT:
+		c.StartingPort, err = strconv.Atoi(value)
+	case R_VALUE:
+		c.RValue, err = strconv.Atoi(value)
+	case W_VALUE:
+		c.WValue, err = strconv.Atoi(value)
+	case N_VALUE:
+		c.NValue, err = strconv.Atoi(value)
+		if err == nil && c.NValue < 1 {
+			err = errors.New("must be at least 1")
+		}
+	case CLUSTER_SIZE:
+		c.ClusterSize, err = strconv.Atoi(value)
+	case DATA_DIR:
+		c.DataDir = value
+	case SNAPSHOT_INTERVAL:
+		c.SnapshotIntervalSeconds, err = strconv.Atoi(value)
+		if err == nil && c.SnapshotIntervalSeconds < 0 {
+			err = errors.New("must not be negative")
+		}
+	case SNAPSHOT_RETENTION:
+		c.SnapshotRetention, err = strconv.Atoi(value)
+		if err == nil && c.SnapshotRetention < 1 {
+			err = errors.New("must be at least 1")
+		}
+	case STORAGE_ENGINE:
+		err = checkStorageEngineName(value)
+		c.StorageEngine = value
+	case VIRTUAL_NODES:
+		c.VirtualNodes, err = strconv.Atoi(value)
+		if err == nil && c.VirtualNodes < 0 {
+			err = errors.New("must not be negative")
+		}
+	case SLOPPY_QUORUM:
+		c.SloppyQuorum, err = strconv.ParseBool(value)
+	case HINTED_HANDOFF_INTERVAL:
+		c.HintedHandoffIntervalMs, err = strconv.Atoi(value)
+		if err == nil && c.HintedHandoffIntervalMs < 0 {
+			err = errors.New("must not be negative")
+		}
+	case GOSSIP_INTERVAL:
+		c.GossipIntervalMs, err = strconv.Atoi(value)
+		if err == nil && c.GossipIntervalMs < 0 {
+			err = errors.New("must not be negative")
+		}
+	case GOSSIP_JITTER:
+		c.GossipJitterMs, err = strconv.Atoi(value)
+		if err == nil && c.GossipJitterMs < 0 {
+			err = errors.New("must not be negative")
+		}
+	case GOSSIP_FANOUT:
+		c.GossipFanout, err = strconv.Atoi(value)
+		if err == nil && c.GossipFanout < 0 {
+			err = errors.New("must not be negative")
+		}
+	case GOSSIP_MODE:
+		if value != GOSSIP_MODE_PUSH && value != GOSSIP_MODE_PULL && value != GOSSIP_MODE_PUSH_PULL {
+			err = fmt.Errorf("must be %q, %q or %q", GOSSIP_MODE_PUSH, GOSSIP_MODE_PULL, GOSSIP_MODE_PUSH_PULL)
+		}
+		c.GossipMode = value
+	case READ_REPAIR:
+		if value != READ_REPAIR_OFF && value != READ_REPAIR_ASYNC && value != READ_REPAIR_SYNC {
+			err = fmt.Errorf("must be %q, %q or %q", READ_REPAIR_OFF, READ_REPAIR_ASYNC, READ_REPAIR_SYNC)
+		}
+		c.ReadRepair = value
+	case REQUEST_TIMEOUT:
+		c.RequestTimeoutMs, err = strconv.Atoi(value)
+		if err == nil && c.RequestTimeoutMs < 0 {
+			err = errors.New("must not be negative")
+		}
+	case CONNECTION_POOL_MAX_IDLE:
+		c.ConnectionPoolMaxIdle, err = strconv.Atoi(value)
+		if err == nil && c.ConnectionPoolMaxIdle < 0 {
+			err = errors.New("must not be negative")
+		}
+	case CONNECTION_HEALTH_CHECK_INTERVAL:
+		c.ConnectionHealthCheckIntervalMs, err = strconv.Atoi(value)
+		if err == nil && c.ConnectionHealthCheckIntervalMs < 0 {
+			err = errors.New("must not be negative")
+		}
+	case HEARTBEAT_INTERVAL:
+		c.HeartbeatIntervalMs, err = strconv.Atoi(value)
+		if err == nil && c.HeartbeatIntervalMs < 0 {
+			err = errors.New("must not be negative")
+		}
+	case SUSPECT_TIMEOUT:
+		c.SuspectTimeoutMs, err = strconv.Atoi(value)
+		if err == nil && c.SuspectTimeoutMs <= 0 {
+			err = errors.New("must be positive")
+		}
+	case DEAD_TIMEOUT:
+		c.DeadTimeoutMs, err = strconv.Atoi(value)
+		if err == nil && c.DeadTimeoutMs <= 0 {
+			err = errors.New("must be positive")
+		}
+	case TOMBSTONE_GRACE_PERIOD:
+		c.TombstoneGracePeriodMs, err = strconv.Atoi(value)
+		if err == nil && c.TombstoneGracePeriodMs < 0 {
+			err = errors.New("must not be negative")
+		}
+	case TOMBSTONE_GC_INTERVAL:
+		c.TombstoneGCIntervalMs, err = strconv.Atoi(value)
+		if err == nil && c.TombstoneGCIntervalMs < 0 {
+			err = errors.New("must not be negative")
+		}
+	case VECTOR_CLOCK_MAX_ENTRIES:
+		c.VectorClockMaxEntries, err = strconv.Atoi(value)
+		if err == nil && c.VectorClockMaxEntries < 0 {
+			err = errors.New("must not be negative")
+		}
+	case CAUSALITY:
+		if value != CAUSALITY_VECTOR_CLOCK && value != CAUSALITY_DOTTED_VERSION_VECTOR {
+			err = fmt.Errorf("must be %q or %q", CAUSALITY_VECTOR_CLOCK, CAUSALITY_DOTTED_VERSION_VECTOR)
+		}
+		c.Causality = value
+	case TRANSPORT:
+		if value != TRANSPORT_RPC && value != TRANSPORT_GRPC && value != TRANSPORT_BOTH {
+			err = fmt.Errorf("must be %q, %q or %q", TRANSPORT_RPC, TRANSPORT_GRPC, TRANSPORT_BOTH)
+		}
+		c.Transport = value
+	case REPLICATION_TRANSPORT:
+		if value != TRANSPORT_RPC && value != TRANSPORT_GRPC {
+			err = fmt.Errorf("must be %q or %q", TRANSPORT_RPC, TRANSPORT_GRPC)
+		}
+		c.ReplicationTransport = value
+	case GRPC_PORT_OFFSET:
+		c.GRPCPortOffset, err = strconv.Atoi(value)
+		if err == nil && c.GRPCPortOffset <= 0 {
+			err = errors.New("must be positive")
+		}
+	default:
+		return fmt.Errorf("unknown config label %q", label)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid value %q for config label %q: %v", value, label, err)
+	}
+	return nil
+}
+
+// Returns the "address:port" of the gRPC endpoint of the node
+// If both transports are served, the gRPC endpoint listens on the port of the node plus the gRPC port offset.
+func (c *DynamoConfig) GRPCAddressOf(node DynamoNode) string {
+	return node.Address + ":" + grpcPortOf(node, c.Transport, c.GRPCPortOffset)
+}
+
+// Returns the port of the gRPC endpoint of the node
+func grpcPortOf(node DynamoNode, transport string, portOffset int) string {
+	port, err := strconv.Atoi(node.Port)
+	if transport != TRANSPORT_BOTH || err != nil {
+		return node.Port
+	}
+	return strconv.Itoa(port + portOffset)
+}
+
+// Returns the number of nodes storing each key
+func (c *DynamoConfig) ReplicationFactor() int {
+	if c.NValue == 0 {
+		return c.ClusterSize
+	}
+	return c.NValue
+}
+
+// Checks the configurations that depend on each other
+// Returns an error unless R, W <= N <= cluster size, if the nodes replicate through a transport they do not serve, or
+// if a node uses a disk-backed storage engine while no data directory is configured.
+func (c *DynamoConfig) Validate() error {
+	n := c.ReplicationFactor()
+	if n > c.ClusterSize {
+		return fmt.Errorf("%s %d must not exceed %s %d", N_VALUE, n, CLUSTER_SIZE, c.ClusterSize)
+	}
+	if c.RValue > n {
+		return fmt.Errorf("%s %d must not exceed %s %d", R_VALUE, c.RValue, N_VALUE, n)
+	}
+	if c.WValue > n {
+		return fmt.Errorf("%s %d must not exceed %s %d", W_VALUE, c.WValue, N_VALUE, n)
+	}
+	if c.SuspectTimeoutMs > c.DeadTimeoutMs {
+		return fmt.Errorf("%s %d must not exceed %s %d", SUSPECT_TIMEOUT, c.SuspectTimeoutMs, DEAD_TIMEOUT, c.DeadTimeoutMs)
+	}
+	if c.ReplicationTransport == TRANSPORT_GRPC && c.Transport == TRANSPORT_RPC {
+		return fmt.Errorf("%s %q requires %s %q or %q", REPLICATION_TRANSPORT, TRANSPORT_GRPC, TRANSPORT, TRANSPORT_GRPC, TRANSPORT_BOTH)
+	}
+	if c.Transport == TRANSPORT_GRPC && c.ReplicationTransport != TRANSPORT_GRPC {
+		return fmt.Errorf("%s %q requires %s %q", TRANSPORT, TRANSPORT_GRPC, REPLICATION_TRANSPORT, TRANSPORT_GRPC)
+	}
+
+	if c.DataDir != "" {
+		return nil
+	}
+	if c.StorageEngine != STORAGE_ENGINE_MEMORY {
+		return fmt.Errorf("storage engine %q requires config label %q", c.StorageEngine, DATA_DIR)
+	}
+	for id, storageEngine := range c.NodeStorageEngines {
+		if storageEngine != STORAGE_ENGINE_MEMORY {
+			return fmt.Errorf("storage engine %q of node %q requires config label %q", storageEngine, id, DATA_DIR)
+		}
+	}
+	return nil
+}
+
+// Returns an error if there is no storage engine with the given name
+func checkStorageEngineName(name string) error {
+	if name != STORAGE_ENGINE_MEMORY && name != STORAGE_ENGINE_DISK {
+		return fmt.Errorf("must be %q or %q", STORAGE_ENGINE_MEMORY, STORAGE_ENGINE_DISK)
+	}
+	return nil
+}
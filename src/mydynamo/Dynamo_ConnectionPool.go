@@ -0,0 +1,321 @@
+package mydynamo
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/rpc"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Counters of the connections made by a connection pool
+type ConnectionPoolStats struct {
+	Dials       int64 //Connections dialed to other nodes
+	Reuses      int64 //Calls made on idle connections instead of dialing new connections
+	Reconnects  int64 //Calls retried on a new connection because the idle connection was closed
+	Evictions   int64 //Idle connections closed because they failed a health check
+	Unreachable int64 //Calls failed because the node could not be reached
+}
+
+// Error of a call that did not complete within its timeout
+var errCallTimedOut = errors.New("request timed out")
+
+// Connection to a node and the RPC client making calls on it
+type pooledConn struct {
+	client *rpc.Client
+	conn   net.Conn
+}
+
+// Pool of reusable RPC connections from a server to the other nodes
+// Connections are borrowed for each call and kept idle afterwards, up to `maxIdle` connections to each node. Idle
+// connections closed by the other node are replaced by new connections when a call is made on them, or evicted by
+// `ConnectionPool.HealthCheck`. The pool is safe for concurrent use.
+type ConnectionPool struct {
+	mutex          sync.Mutex
+	idle           map[DynamoNode][]*pooledConn //Idle connections to each node, the most recently used last
+	maxIdle        int                          //Maximum number of idle connections kept to each node
+	timeout        time.Duration                //Time to wait for a new connection or a call without deadline, no limit if 0
+	closed         bool
+	stats          ConnectionPoolStats             //Updated atomically
+	grpcTransport  string                          //Transports served by the nodes, the pool does not use gRPC if empty
+	grpcPortOffset int                             //Offset added to the port of a node to get its gRPC port
+	grpcConns      map[DynamoNode]*grpc.ClientConn //gRPC connection to each node
+}
+
+// Creates a new ConnectionPool keeping at most `maxIdle` idle connections to each node, and giving up on new
+// connections and calls without a deadline not completed within `timeout`
+func NewConnectionPool(maxIdle int, timeout time.Duration) *ConnectionPool {
+	return &ConnectionPool{
+		idle:      make(map[DynamoNode][]*pooledConn),
+		maxIdle:   maxIdle,
+		timeout:   timeout,
+		grpcConns: make(map[DynamoNode]*grpc.ClientConn),
+	}
+}
+
+// Calls the RPC method on the node with a pooled connection
+// If an idle connection turns out to be closed, the call is retried once on a new connection. Returns the error of
+// the call, or of dialing the node if it is unreachable. If gRPC is enabled, the replication methods are called through
+// gRPC instead. The call times out like `ConnectionPool.CallContext` after the timeout of the pool.
+func (p *ConnectionPool) Call(node DynamoNode, method string, args interface{}, reply interface{}) error {
+	ctx, cancel := contextWithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	return p.CallContext(ctx, node, method, args, reply)
+}
+
+// Calls the RPC method on the node like `ConnectionPool.Call`, but returns `errCallTimedOut` if the node does not
+// respond before the deadline of the context, and the error of the context once it is cancelled
+// The connection of a call that timed out or was cancelled is closed, so no goroutine is left waiting for the response.
+func (p *ConnectionPool) CallContext(ctx context.Context, node DynamoNode, method string, args interface{}, reply interface{}) error {
+	ok, err := p.callGRPC(ctx, node, method, args, reply)
+	if !ok {
+		err = p.call(ctx, node, method, args, reply)
+	}
+	if isUnreachableError(err) {
+		atomic.AddInt64(&p.stats.Unreachable, 1)
+	}
+	return err
+}
+
+// Calls the RPC method on the node with a pooled connection, see `ConnectionPool.CallContext`
+func (p *ConnectionPool) call(ctx context.Context, node DynamoNode, method string, args interface{}, reply interface{}) error {
+	conn, reused, err := p.get(node)
+	if err != nil {
+		return err
+	}
+
+	err = conn.call(ctx, method, args, reply)
+	if errors.Is(err, rpc.ErrShutdown) && reused {
+		// The node closed the idle connection, e.g. it restarted, so the request was never sent
+		_ = conn.client.Close()
+		atomic.AddInt64(&p.stats.Reconnects, 1)
+		if conn, err = p.dial(node); err != nil {
+			return err
+		}
+		err = conn.call(ctx, method, args, reply)
+	}
+
+	// Errors returned by the node leave the connection usable, any other error breaks it
+	var serverError rpc.ServerError
+	if err == nil || errors.As(err, &serverError) {
+		p.put(node, conn)
+	} else {
+		_ = conn.client.Close()
+	}
+	return err
+}
+
+// Calls the RPC method on the connection, which must not be used by other calls, until the context is done
+// The deadline of the context is set on the connection, and cancelling the context expires it, which interrupts the
+// call. A call that ends after the context is done returns the error of the context, so its connection is closed.
+func (c *pooledConn) call(ctx context.Context, method string, args interface{}, reply interface{}) error {
+	if ctx.Done() == nil {
+		return c.client.Call(method, args, reply)
+	}
+	if err := ctx.Err(); err != nil {
+		return contextError(err)
+	}
+
+	deadline, _ := ctx.Deadline()
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	callDone := make(chan struct{})
+	interrupted := make(chan bool, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = c.conn.SetDeadline(time.Now())
+			interrupted <- true
+		case <-callDone:
+			interrupted <- false
+		}
+	}()
+	err := c.client.Call(method, args, reply)
+	close(callDone)
+	if <-interrupted {
+		return contextError(ctx.Err())
+	}
+	if resetErr := c.conn.SetDeadline(time.Time{}); err == nil {
+		err = resetErr
+	}
+	return err
+}
+
+// Returns the error of a call whose context is done, `errCallTimedOut` if its deadline was exceeded
+func contextError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errCallTimedOut
+	}
+	return err
+}
+
+// Returns a context with the timeout, or without a deadline if the timeout is 0
+func contextWithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// Returns an idle connection to the node, or a new connection if there is none
+// The returned bool is true if the connection is an idle connection.
+func (p *ConnectionPool) get(node DynamoNode) (*pooledConn, bool, error) {
+	p.mutex.Lock()
+	conns := p.idle[node]
+	if len(conns) > 0 {
+		conn := conns[len(conns)-1]
+		p.idle[node] = conns[:len(conns)-1]
+		p.mutex.Unlock()
+
+		atomic.AddInt64(&p.stats.Reuses, 1)
+		return conn, true, nil
+	}
+	p.mutex.Unlock()
+
+	conn, err := p.dial(node)
+	return conn, false, err
+}
+
+// Dials a new connection to the node, and switches it to RPC with an HTTP CONNECT request like `rpc.DialHTTP`
+// Both the dial and the request must complete within the timeout of the pool, so unreachable nodes fail fast.
+func (p *ConnectionPool) dial(node DynamoNode) (*pooledConn, error) {
+	atomic.AddInt64(&p.stats.Dials, 1)
+	conn, err := net.DialTimeout("tcp", node.Address+":"+node.Port, p.timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.timeout > 0 {
+		err = conn.SetDeadline(time.Now().Add(p.timeout))
+	}
+	if err == nil {
+		_, err = io.WriteString(conn, "CONNECT "+rpc.DefaultRPCPath+" HTTP/1.0\n\n")
+	}
+	var response *http.Response
+	if err == nil {
+		response, err = http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: "CONNECT"})
+	}
+	if err == nil && response.Status != "200 Connected to Go RPC" {
+		err = fmt.Errorf("unexpected HTTP response: %s", response.Status)
+	}
+	if err == nil {
+		err = conn.SetDeadline(time.Time{})
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return &pooledConn{client: rpc.NewClient(conn), conn: conn}, nil
+}
+
+// Keeps the connection to the node idle, or closes it if there are enough idle connections
+func (p *ConnectionPool) put(node DynamoNode, conn *pooledConn) {
+	p.mutex.Lock()
+	if !p.closed && len(p.idle[node]) < p.maxIdle {
+		p.idle[node] = append(p.idle[node], conn)
+		conn = nil
+	}
+	p.mutex.Unlock()
+
+	if conn != nil {
+		_ = conn.client.Close()
+	}
+}
+
+// Pings the idle connections and closes the ones that do not respond within the timeout of the pool, or
+// CONNECTION_PING_TIMEOUT_MS without one
+func (p *ConnectionPool) HealthCheck() {
+	p.mutex.Lock()
+	idle := p.idle
+	p.idle = make(map[DynamoNode][]*pooledConn)
+	p.mutex.Unlock()
+
+	timeout := p.timeout
+	if timeout <= 0 {
+		timeout = time.Duration(CONNECTION_PING_TIMEOUT_MS) * time.Millisecond
+	}
+	for node, conns := range idle {
+		for _, conn := range conns {
+			var empty Empty
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			err := conn.call(ctx, "MyDynamo.Ping", empty, &empty)
+			cancel()
+			if err != nil {
+				log.Println(DYNAMO_SERVER, "Evicted connection to", node, ":", err)
+				atomic.AddInt64(&p.stats.Evictions, 1)
+				_ = conn.client.Close()
+				continue
+			}
+			p.put(node, conn)
+		}
+	}
+}
+
+// Returns the counters of the connections made by the pool
+func (p *ConnectionPool) Stats() ConnectionPoolStats {
+	return ConnectionPoolStats{
+		Dials:       atomic.LoadInt64(&p.stats.Dials),
+		Reuses:      atomic.LoadInt64(&p.stats.Reuses),
+		Reconnects:  atomic.LoadInt64(&p.stats.Reconnects),
+		Evictions:   atomic.LoadInt64(&p.stats.Evictions),
+		Unreachable: atomic.LoadInt64(&p.stats.Unreachable),
+	}
+}
+
+// Closes the idle connections, connections returned to the pool afterwards are closed as well
+func (p *ConnectionPool) Close() {
+	p.mutex.Lock()
+	idle := p.idle
+	p.idle = make(map[DynamoNode][]*pooledConn)
+	p.closed = true
+	p.mutex.Unlock()
+
+	for _, conns := range idle {
+		for _, conn := range conns {
+			_ = conn.client.Close()
+		}
+	}
+	p.closeGRPC()
+}
+
+// Checks the health of the pooled connections periodically until the server is shut down
+func (s *DynamoServer) runConnectionHealthCheckLoop() {
+	ticker := time.NewTicker(s.poolHealthCheck)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.lifecycle.done:
+			return
+		case <-ticker.C:
+		}
+
+		s.connections.HealthCheck()
+	}
+}
+
+// Responds to health checks of pooled connections
+// Unlike other methods, this method succeeds while the server is crashed, as the connection itself is healthy.
+func (s *DynamoServer) Ping(_ Empty, _ *Empty) error {
+	return nil
+}
+
+// Returns the counters of the connections made by this server to other servers
+func (s *DynamoServer) GetConnectionPoolStats(_ Empty, result *ConnectionPoolStats) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+
+	*result = s.connections.Stats()
+	return nil
+}
@@ -19,6 +19,128 @@ const MYDYNAMO string = "mydynamo"
 const SERVER_PORT string = "starting_port"
 const W_VALUE string = "w_value"
 const R_VALUE string = "r_value"
+const N_VALUE string = "n_value"
 const CLUSTER_SIZE string = "cluster_size"
+const DATA_DIR string = "data_dir"
+const SNAPSHOT_INTERVAL string = "snapshot_interval_seconds"
+const SNAPSHOT_RETENTION string = "snapshot_retention"
+const STORAGE_ENGINE string = "storage_engine"
+const VIRTUAL_NODES string = "virtual_nodes"
+const SLOPPY_QUORUM string = "sloppy_quorum"
+const HINTED_HANDOFF_INTERVAL string = "hinted_handoff_interval_ms"
+const GOSSIP_INTERVAL string = "gossip_interval_ms"
+const GOSSIP_JITTER string = "gossip_jitter_ms"
+const GOSSIP_FANOUT string = "gossip_fanout"
+const GOSSIP_MODE string = "gossip_mode"
+const READ_REPAIR string = "read_repair"
+const REQUEST_TIMEOUT string = "request_timeout_ms"
+const CONNECTION_POOL_MAX_IDLE string = "connection_pool_max_idle"
+const CONNECTION_HEALTH_CHECK_INTERVAL string = "connection_health_check_interval_ms"
+const HEARTBEAT_INTERVAL string = "heartbeat_interval_ms"
+const SUSPECT_TIMEOUT string = "suspect_timeout_ms"
+const DEAD_TIMEOUT string = "dead_timeout_ms"
+const TOMBSTONE_GRACE_PERIOD string = "tombstone_grace_period_ms"
+const TOMBSTONE_GC_INTERVAL string = "tombstone_gc_interval_ms"
+const VECTOR_CLOCK_MAX_ENTRIES string = "vector_clock_max_entries"
+const CAUSALITY string = "causality"
+const TRANSPORT string = "transport"
+const REPLICATION_TRANSPORT string = "replication_transport"
+const GRPC_PORT_OFFSET string = "grpc_port_offset"
 
 const RPC_CLIENT_CONNECT_RETRY_MAX int = 3
+
+//Persistence constants
+const DATA_DIR_PERM = 0755
+const WAL_SEGMENT_PATTERN string = "wal-%016d.log"
+const WAL_FILE_PERM = 0644
+const SNAPSHOT_FILE_PATTERN string = "snapshot-%016d.snap"
+const SNAPSHOT_FILE_PERM = 0644
+const DEFAULT_SNAPSHOT_INTERVAL_SECONDS int = 300
+const DEFAULT_SNAPSHOT_RETENTION int = 2
+
+//Storage engine constants
+const STORAGE_ENGINE_MEMORY string = "memory"
+const STORAGE_ENGINE_DISK string = "disk"
+const DISK_STORAGE_DIR_NAME string = "storage"
+const DISK_STORAGE_SEGMENT_PATTERN string = "storage-%016d.data"
+const DISK_STORAGE_COMPACTION_MIN_BYTES int64 = 1 << 20
+
+//Gossip constants
+const GOSSIP_MODE_PUSH string = "push"
+const GOSSIP_MODE_PULL string = "pull"
+const GOSSIP_MODE_PUSH_PULL string = "push-pull"
+
+//Fan-out constants
+const DEFAULT_REQUEST_TIMEOUT_MS int = 1000
+
+//Connection pool constants
+const DEFAULT_CONNECTION_POOL_MAX_IDLE int = 2
+const DEFAULT_CONNECTION_HEALTH_CHECK_INTERVAL_MS int = 5000
+const CONNECTION_PING_TIMEOUT_MS int = 1000
+
+//Membership constants
+const MEMBER_ALIVE string = "alive"
+const MEMBER_SUSPECT string = "suspect"
+const MEMBER_DEAD string = "dead"
+const MEMBERSHIP_FANOUT int = 2
+const DEFAULT_SUSPECT_TIMEOUT_MS int = 3000
+const DEFAULT_DEAD_TIMEOUT_MS int = 10000
+
+//Cluster constants
+const DECOMMISSION_SHUTDOWN_DELAY_MS int = 100
+const JOIN_RETRY_INTERVAL_MS int = 1000
+
+//Tombstone constants
+const DEFAULT_TOMBSTONE_GRACE_PERIOD_MS int = 24 * 60 * 60 * 1000
+
+//Vector clock constants
+const CLOCK_EQUAL ClockOrder = 0
+const CLOCK_BEFORE ClockOrder = 1
+const CLOCK_AFTER ClockOrder = 2
+const CLOCK_CONCURRENT ClockOrder = 3
+
+//Context encoding constants
+const CONTEXT_ENCODING_VERSION byte = 1
+
+//Causality constants
+const CAUSALITY_VECTOR_CLOCK string = "vector-clock"
+const CAUSALITY_DOTTED_VERSION_VECTOR string = "dotted-version-vector"
+
+//Transport constants
+const TRANSPORT_RPC string = "rpc"
+const TRANSPORT_GRPC string = "grpc"
+const TRANSPORT_BOTH string = "both"
+const DEFAULT_GRPC_PORT_OFFSET int = 1000
+
+//HTTP gateway constants
+const HTTP_KV_PATH string = "/kv/"
+const HTTP_MAX_BODY_BYTES int64 = 16 << 20
+
+//DynamoDB API constants
+const DYNAMODB_API_PATH string = "/"
+const DYNAMODB_TARGET_PREFIX string = "DynamoDB_20120810."
+const DYNAMODB_ERROR_PREFIX string = "com.amazonaws.dynamodb.v20120810#"
+const DYNAMODB_CONTENT_TYPE string = "application/x-amz-json-1.0"
+const DYNAMODB_TABLES_KEY string = "__dynamodb/tables"
+const DYNAMODB_ITEM_KEY_PREFIX string = "__dynamodb/items/"
+const DYNAMODB_MAX_LIST_TABLES int = 100
+
+//Node constants
+const NODE_USAGE_STRING string = "usage: ./run-node [config file] [id] [address:port] [seed address:port]..."
+const NODE_ARG_COUNT int = 4
+const NODE_ID_INDEX int = 2
+const NODE_ADDRESS_INDEX int = 3
+const NODE_SEEDS_INDEX int = 4
+
+//Read repair constants
+const READ_REPAIR_OFF string = "off"
+const READ_REPAIR_ASYNC string = "async"
+const READ_REPAIR_SYNC string = "sync"
+
+//Anti-entropy constants
+const MERKLE_TREE_DEPTH int = 10
+const MERKLE_ROUND_TIMEOUT_MS int = 60000
+
+//Hinted handoff constants
+const HINTS_DIR_NAME string = "hints"
+const DEFAULT_HINTED_HANDOFF_INTERVAL_MS int = 1000
@@ -0,0 +1,222 @@
+package mydynamo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Binary encoding of vector clocks and contexts
+// A vector clock is encoded as | version | elements |, and a context as | version | elements | dot node ID |
+// dot counter |, where the version is CONTEXT_ENCODING_VERSION, and the elements are their uvarint number followed by
+// the elements sorted by node ID. Each element is the uvarint length of the node ID, the node ID, the uvarint counter
+// and the varint timestamp, which is 0 if the element has none. A zero dot has an empty node ID and a zero counter.
+// Decoders reject the other versions, so the format can change with a new version.
+// As types implementing `encoding.BinaryMarshaler`, vector clocks and contexts are also encoded this way by gob, in
+// RPCs and on the disk.
+
+// Returns the binary encoding of the vector clock
+func (s VectorClock) MarshalBinary() ([]byte, error) {
+	return s.appendBinary([]byte{CONTEXT_ENCODING_VERSION}, true), nil
+}
+
+// Decodes the binary encoding of a vector clock returned by `VectorClock.MarshalBinary` into the vector clock
+func (s *VectorClock) UnmarshalBinary(data []byte) error {
+	decoder, err := newContextDecoder(data)
+	if err != nil {
+		return err
+	}
+	clock, err := decoder.vectorClock()
+	if err != nil {
+		return err
+	}
+	if err := decoder.finish(); err != nil {
+		return err
+	}
+	*s = clock
+	return nil
+}
+
+// Returns the binary encoding of the context
+func (c Context) MarshalBinary() ([]byte, error) {
+	return c.appendBinary([]byte{CONTEXT_ENCODING_VERSION}, true), nil
+}
+
+// Decodes the binary encoding of a context returned by `Context.MarshalBinary` into the context
+func (c *Context) UnmarshalBinary(data []byte) error {
+	decoder, err := newContextDecoder(data)
+	if err != nil {
+		return err
+	}
+	clock, err := decoder.vectorClock()
+	if err != nil {
+		return err
+	}
+	nodeID, err := decoder.string()
+	if err != nil {
+		return err
+	}
+	counter, err := decoder.uvarint()
+	if err != nil {
+		return err
+	}
+	if err := decoder.finish(); err != nil {
+		return err
+	}
+	*c = Context{Clock: clock, Dot: Dot{NodeID: nodeID, Counter: counter}}
+	return nil
+}
+
+// Returns the binary encoding of the context with zero timestamps
+// The identities of two contexts are equal iff the contexts are, so it identifies the value put with the context.
+func (c Context) Identity() string {
+	return string(c.appendBinary([]byte{CONTEXT_ENCODING_VERSION}, false))
+}
+
+// Appends the encoding of the context after its version to the buffer
+func (c Context) appendBinary(buf []byte, withTimestamps bool) []byte {
+	buf = c.Clock.appendBinary(buf, withTimestamps)
+	buf = appendString(buf, c.Dot.NodeID)
+	return appendUvarint(buf, c.Dot.Counter)
+}
+
+// Appends the encoding of the vector clock after its version to the buffer, with zero timestamps if withTimestamps
+// is false
+func (s VectorClock) appendBinary(buf []byte, withTimestamps bool) []byte {
+	nodeIDs := make([]string, 0, len(s.NodeClocks))
+	for nodeID := range s.NodeClocks {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Strings(nodeIDs)
+
+	buf = appendUvarint(buf, uint64(len(nodeIDs)))
+	for _, nodeID := range nodeIDs {
+		buf = appendString(buf, nodeID)
+		buf = appendUvarint(buf, s.NodeClocks[nodeID])
+		var timestamp int64
+		if withTimestamps {
+			timestamp = s.Timestamps[nodeID]
+		}
+		buf = appendVarint(buf, timestamp)
+	}
+	return buf
+}
+
+// Appends the uvarint to the buffer
+func appendUvarint(buf []byte, value uint64) []byte {
+	var uvarint [binary.MaxVarintLen64]byte
+	return append(buf, uvarint[:binary.PutUvarint(uvarint[:], value)]...)
+}
+
+// Appends the varint to the buffer
+func appendVarint(buf []byte, value int64) []byte {
+	var varint [binary.MaxVarintLen64]byte
+	return append(buf, varint[:binary.PutVarint(varint[:], value)]...)
+}
+
+// Appends the uvarint length of the string and the string to the buffer
+func appendString(buf []byte, s string) []byte {
+	buf = appendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// Decoder of the binary encoding of vector clocks and contexts
+type contextDecoder struct {
+	data []byte
+}
+
+// Returns a decoder of the encoding after checking its version
+func newContextDecoder(data []byte) (*contextDecoder, error) {
+	if len(data) == 0 {
+		return nil, errors.New("context encoding: empty data")
+	}
+	if data[0] != CONTEXT_ENCODING_VERSION {
+		return nil, fmt.Errorf("context encoding: unsupported version %d", data[0])
+	}
+	return &contextDecoder{data: data[1:]}, nil
+}
+
+// Decodes the elements of a vector clock
+func (d *contextDecoder) vectorClock() (VectorClock, error) {
+	clock := NewVectorClock()
+
+	count, err := d.count()
+	if err != nil {
+		return clock, err
+	}
+	for i := 0; i < count; i++ {
+		nodeID, err := d.string()
+		if err != nil {
+			return clock, err
+		}
+		if clock.NodeClocks[nodeID], err = d.uvarint(); err != nil {
+			return clock, err
+		}
+		timestamp, err := d.varint()
+		if err != nil {
+			return clock, err
+		}
+		if timestamp != 0 {
+			clock.Timestamps[nodeID] = timestamp
+		}
+	}
+	if len(clock.NodeClocks) != count {
+		return clock, errors.New("context encoding: duplicate node ID")
+	}
+	return clock, nil
+}
+
+// Decodes a number of elements, which is at most the number of remaining bytes as each element takes a byte or more
+func (d *contextDecoder) count() (int, error) {
+	count, err := d.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	if count > uint64(len(d.data)) {
+		return 0, errors.New("context encoding: invalid number of elements")
+	}
+	return int(count), nil
+}
+
+// Decodes a uvarint
+func (d *contextDecoder) uvarint() (uint64, error) {
+	value, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		return 0, errors.New("context encoding: invalid uvarint")
+	}
+	d.data = d.data[n:]
+	return value, nil
+}
+
+// Decodes a varint
+func (d *contextDecoder) varint() (int64, error) {
+	value, n := binary.Varint(d.data)
+	if n <= 0 {
+		return 0, errors.New("context encoding: invalid varint")
+	}
+	d.data = d.data[n:]
+	return value, nil
+}
+
+// Decodes a string with its uvarint length
+func (d *contextDecoder) string() (string, error) {
+	length, err := d.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if length > uint64(len(d.data)) {
+		return "", errors.New("context encoding: truncated string")
+	}
+	s := string(d.data[:length])
+	d.data = d.data[length:]
+	return s, nil
+}
+
+// Returns an error if there are bytes left after the encoding
+func (d *contextDecoder) finish() error {
+	if len(d.data) > 0 {
+		return errors.New("context encoding: trailing data")
+	}
+	return nil
+}
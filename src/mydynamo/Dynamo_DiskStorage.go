@@ -0,0 +1,411 @@
+package mydynamo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Size of the record header in the disk storage:
+// CRC-32 checksum (4 bytes), flags (1 byte), key length (4 bytes) and value length (4 bytes)
+const diskRecordHeaderSize = 13
+
+// Flag of a record that removes its key
+const diskRecordFlagDelete byte = 1
+
+// Location of the latest record of a key in the disk storage
+type diskRecordLocation struct {
+	segment uint64
+	offset  int64
+	size    int64
+}
+
+// Disk-backed StorageEngine based on a log-structured file format (similar to Bitcask)
+// Every Put or Delete appends a record to the active segment file and is fsync'd. Only the index of keys (the
+// location of the latest record of each key) is kept in memory, so the values can be larger than the memory.
+// A record is written as | checksum | flags | key length | value length | key | value | where the value is the
+// gob-encoded entries and the checksum is the CRC-32 (Castagnoli) of everything after it. Segments are compacted
+// by rewriting the live records once the overwritten records take more space than the live ones.
+type DiskStorageEngine struct {
+	dir        string
+	keyDir     map[string]diskRecordLocation //Location of the latest record of each key
+	segments   map[uint64]*os.File           //Open segment files
+	activeSeq  uint64                        //Sequence number of the segment records are appended to
+	activeSize int64                         //Size of the active segment
+	liveBytes  int64                         //Size of the records referenced by keyDir
+	deadBytes  int64                         //Size of the overwritten and delete records
+	mu         *sync.RWMutex
+	KeyRWMutexMap
+}
+
+// Creates a new DiskStorageEngine storing its files in the given directory
+// The storage must be opened with `DiskStorageEngine.Open` before use.
+func NewDiskStorageEngine(dir string) *DiskStorageEngine {
+	return &DiskStorageEngine{
+		dir:           dir,
+		keyDir:        make(map[string]diskRecordLocation),
+		segments:      make(map[uint64]*os.File),
+		activeSeq:     0,
+		activeSize:    0,
+		liveBytes:     0,
+		deadBytes:     0,
+		mu:            &sync.RWMutex{},
+		KeyRWMutexMap: NewKeyRWMutexMap(),
+	}
+}
+
+// Returns the path of the segment file with the given sequence number
+func (d *DiskStorageEngine) segmentPath(seq uint64) string {
+	return filepath.Join(d.dir, fmt.Sprintf(DISK_STORAGE_SEGMENT_PATTERN, seq))
+}
+
+// Opens the segment files and rebuilds the index of keys by scanning them
+// A torn or corrupted record at the tail of the last segment is truncated.
+func (d *DiskStorageEngine) Open() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.activeSeq != 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(d.dir, DATA_DIR_PERM); err != nil {
+		return err
+	}
+
+	seqs, err := listSequencedFiles(d.dir, DISK_STORAGE_SEGMENT_PATTERN)
+	if err != nil {
+		return err
+	}
+	if len(seqs) == 0 {
+		seqs = append(seqs, 1)
+	}
+
+	for i, seq := range seqs {
+		file, err := os.OpenFile(d.segmentPath(seq), os.O_RDWR|os.O_CREATE, WAL_FILE_PERM)
+		if err != nil {
+			_ = d.closeSegments()
+			return err
+		}
+		d.segments[seq] = file
+
+		validSize, err := d.scanSegment(seq, file)
+		if err == nil {
+			continue
+		}
+		if i < len(seqs)-1 {
+			log.Println(DYNAMO_SERVER, "Skipping the rest of storage segment", d.segmentPath(seq), ":", err)
+			continue
+		}
+		log.Println(DYNAMO_SERVER, "Truncating storage segment", d.segmentPath(seq), "at offset", validSize, ":", err)
+		if err := file.Truncate(validSize); err != nil {
+			_ = d.closeSegments()
+			return err
+		}
+	}
+
+	d.activeSeq = seqs[len(seqs)-1]
+	info, err := d.segments[d.activeSeq].Stat()
+	if err != nil {
+		_ = d.closeSegments()
+		return err
+	}
+	d.activeSize = info.Size()
+	return nil
+}
+
+// Reads the records of a segment into the index of keys
+// Returns the size of the valid records in bytes, and a non-nil error if the scan stops at an invalid record.
+func (d *DiskStorageEngine) scanSegment(seq uint64, file *os.File) (int64, error) {
+	reader := bufio.NewReader(io.NewSectionReader(file, 0, 1<<62))
+	offset := int64(0)
+	for {
+		flags, key, size, err := skipDiskRecord(reader)
+		if err == io.EOF {
+			return offset, nil
+		}
+		if err != nil {
+			return offset, err
+		}
+
+		if oldLocation, ok := d.keyDir[key]; ok {
+			d.liveBytes -= oldLocation.size
+			d.deadBytes += oldLocation.size
+		}
+		if flags&diskRecordFlagDelete != 0 {
+			delete(d.keyDir, key)
+			d.deadBytes += size
+		} else {
+			d.keyDir[key] = diskRecordLocation{segment: seq, offset: offset, size: size}
+			d.liveBytes += size
+		}
+		offset += size
+	}
+}
+
+// Closes the storage files
+func (d *DiskStorageEngine) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	err := d.closeSegments()
+	d.activeSeq = 0
+	return err
+}
+
+// Closes all open segment files
+func (d *DiskStorageEngine) closeSegments() error {
+	var err error
+	for seq, file := range d.segments {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		delete(d.segments, seq)
+	}
+	return err
+}
+
+// Get the keys of entries in the storage
+func (d *DiskStorageEngine) GetKeys() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	keys := make([]string, 0, len(d.keyDir))
+	for key := range d.keyDir {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Get the entries associated with the given key by reading its latest record
+func (d *DiskStorageEngine) Get(key string) ([]ObjectEntry, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	entries := make([]ObjectEntry, 0)
+
+	location, ok := d.keyDir[key]
+	if !ok {
+		return entries, nil
+	}
+
+	record := make([]byte, location.size)
+	if _, err := d.segments[location.segment].ReadAt(record, location.offset); err != nil {
+		return nil, err
+	}
+
+	_, _, value, err := parseDiskRecord(record)
+	if err != nil {
+		return nil, err
+	}
+	if err := gob.NewDecoder(bytes.NewReader(value)).Decode(&entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Put the entries associated with the given key by appending a record
+// Putting an empty list of entries removes the key.
+func (d *DiskStorageEngine) Put(key string, entries []ObjectEntry) error {
+	if len(entries) == 0 {
+		return d.Delete(key)
+	}
+
+	var value bytes.Buffer
+	if err := gob.NewEncoder(&value).Encode(entries); err != nil {
+		return err
+	}
+
+	return d.appendRecord(0, key, value.Bytes())
+}
+
+// Delete the given key by appending a delete record
+func (d *DiskStorageEngine) Delete(key string) error {
+	d.mu.RLock()
+	_, ok := d.keyDir[key]
+	d.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+	return d.appendRecord(diskRecordFlagDelete, key, nil)
+}
+
+// Appends a record to the active segment, flushes it to the disk and updates the index of keys
+func (d *DiskStorageEngine) appendRecord(flags byte, key string, value []byte) error {
+	record := makeDiskRecord(flags, key, value)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.activeSeq == 0 {
+		return errors.New("disk storage is not open")
+	}
+
+	file := d.segments[d.activeSeq]
+	if _, err := file.WriteAt(record, d.activeSize); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+
+	size := int64(len(record))
+	if oldLocation, ok := d.keyDir[key]; ok {
+		d.liveBytes -= oldLocation.size
+		d.deadBytes += oldLocation.size
+	}
+	if flags&diskRecordFlagDelete != 0 {
+		delete(d.keyDir, key)
+		d.deadBytes += size
+	} else {
+		d.keyDir[key] = diskRecordLocation{segment: d.activeSeq, offset: d.activeSize, size: size}
+		d.liveBytes += size
+	}
+	d.activeSize += size
+
+	if d.deadBytes >= DISK_STORAGE_COMPACTION_MIN_BYTES && d.deadBytes > d.liveBytes {
+		if err := d.compact(); err != nil {
+			log.Println(DYNAMO_SERVER, "Failed to compact disk storage:", err)
+		}
+	}
+	return nil
+}
+
+// Rewrites the live records to a new segment and removes the old segments
+// Must be called with d.mu locked for writing.
+func (d *DiskStorageEngine) compact() error {
+	newSeq := d.activeSeq + 1
+	newFile, err := os.OpenFile(d.segmentPath(newSeq), os.O_RDWR|os.O_CREATE|os.O_TRUNC, WAL_FILE_PERM)
+	if err != nil {
+		return err
+	}
+
+	newKeyDir := make(map[string]diskRecordLocation, len(d.keyDir))
+	writer := bufio.NewWriter(newFile)
+	offset := int64(0)
+	for key, location := range d.keyDir {
+		record := make([]byte, location.size)
+		if _, err := d.segments[location.segment].ReadAt(record, location.offset); err == nil {
+			_, err = writer.Write(record)
+		}
+		if err != nil {
+			_ = newFile.Close()
+			_ = os.Remove(d.segmentPath(newSeq))
+			return err
+		}
+
+		newKeyDir[key] = diskRecordLocation{segment: newSeq, offset: offset, size: location.size}
+		offset += location.size
+	}
+
+	if err := writer.Flush(); err == nil {
+		err = newFile.Sync()
+	}
+	if err != nil {
+		_ = newFile.Close()
+		_ = os.Remove(d.segmentPath(newSeq))
+		return err
+	}
+
+	if err := syncDir(d.dir); err != nil {
+		_ = newFile.Close()
+		_ = os.Remove(d.segmentPath(newSeq))
+		return err
+	}
+
+	// The new segment has all live records, the old ones can be removed. They are removed oldest first, so if the
+	// removal is interrupted, the remaining ones are the latest and still have the delete records of the keys put
+	// in the removed ones.
+	oldSeqs := make([]uint64, 0, len(d.segments))
+	for seq := range d.segments {
+		oldSeqs = append(oldSeqs, seq)
+	}
+	sort.Slice(oldSeqs, func(i, j int) bool { return oldSeqs[i] < oldSeqs[j] })
+	for _, seq := range oldSeqs {
+		_ = d.segments[seq].Close()
+		if err := os.Remove(d.segmentPath(seq)); err != nil {
+			log.Println(DYNAMO_SERVER, "Failed to remove storage segment:", err)
+		}
+		delete(d.segments, seq)
+	}
+
+	d.segments[newSeq] = newFile
+	d.keyDir = newKeyDir
+	d.activeSeq = newSeq
+	d.activeSize = offset
+	d.liveBytes = offset
+	d.deadBytes = 0
+	return syncDir(d.dir)
+}
+
+// Encodes a record of the disk storage
+func makeDiskRecord(flags byte, key string, value []byte) []byte {
+	record := make([]byte, diskRecordHeaderSize+len(key)+len(value))
+	record[4] = flags
+	binary.BigEndian.PutUint32(record[5:9], uint32(len(key)))
+	binary.BigEndian.PutUint32(record[9:13], uint32(len(value)))
+	copy(record[diskRecordHeaderSize:], key)
+	copy(record[diskRecordHeaderSize+len(key):], value)
+	binary.BigEndian.PutUint32(record[0:4], crc32.Checksum(record[4:], walCRCTable))
+	return record
+}
+
+// Decodes and verifies a record of the disk storage
+func parseDiskRecord(record []byte) (byte, string, []byte, error) {
+	if len(record) < diskRecordHeaderSize {
+		return 0, "", nil, errors.New("incomplete record header")
+	}
+	keyLength := int64(binary.BigEndian.Uint32(record[5:9]))
+	valueLength := int64(binary.BigEndian.Uint32(record[9:13]))
+	if int64(len(record)) != diskRecordHeaderSize+keyLength+valueLength {
+		return 0, "", nil, errors.New("record length mismatch")
+	}
+	if crc32.Checksum(record[4:], walCRCTable) != binary.BigEndian.Uint32(record[0:4]) {
+		return 0, "", nil, errors.New("record checksum mismatch")
+	}
+
+	key := string(record[diskRecordHeaderSize : diskRecordHeaderSize+keyLength])
+	return record[4], key, record[diskRecordHeaderSize+keyLength:], nil
+}
+
+// Reads and verifies a record from the reader without keeping its value in memory
+// Returns the flags, the key and the size of the record in bytes. Returns io.EOF if there are no more records.
+func skipDiskRecord(reader io.Reader) (byte, string, int64, error) {
+	header := make([]byte, diskRecordHeaderSize)
+	if n, err := io.ReadFull(reader, header); err != nil {
+		if err == io.EOF && n == 0 {
+			return 0, "", 0, io.EOF
+		}
+		return 0, "", 0, errors.New("incomplete record header")
+	}
+
+	keyLength := int64(binary.BigEndian.Uint32(header[5:9]))
+	valueLength := int64(binary.BigEndian.Uint32(header[9:13]))
+
+	hash := crc32.New(walCRCTable)
+	hash.Write(header[4:])
+
+	var key bytes.Buffer
+	if _, err := io.CopyN(io.MultiWriter(&key, hash), reader, keyLength); err != nil {
+		return 0, "", 0, errors.New("incomplete record key")
+	}
+	if _, err := io.CopyN(hash, reader, valueLength); err != nil {
+		return 0, "", 0, errors.New("incomplete record value")
+	}
+	if hash.Sum32() != binary.BigEndian.Uint32(header[0:4]) {
+		return 0, "", 0, errors.New("record checksum mismatch")
+	}
+
+	return header[4], key.String(), diskRecordHeaderSize + keyLength + valueLength, nil
+}
@@ -0,0 +1,111 @@
+package mydynamo
+
+// A dot of a dotted version vector, the Counter-th update of a key coordinated by the node
+// Unlike the vector clock a value is put with, the dot of a value only stands for the update of the value, and not
+// for the earlier updates of the node, so concurrent puts coordinated by the same node get distinct dots.
+type Dot struct {
+	NodeID  string
+	Counter uint64
+}
+
+// Returns true if the dot is the zero value, which is the dot of values put with vector clocks
+func (d Dot) IsZero() bool {
+	return d.Counter == 0
+}
+
+// Returns the vector clock of the causal history of the value of the context, its clock including its dot
+// This is the clock a value put with the context descends from, and equals the clock of contexts without a dot.
+func (c Context) Version() VectorClock {
+	version := c.Clock.Merge(VectorClock{})
+	if !c.Dot.IsZero() && version.NodeClocks[c.Dot.NodeID] < c.Dot.Counter {
+		version.NodeClocks[c.Dot.NodeID] = c.Dot.Counter
+	}
+	return version
+}
+
+// Returns true if the contexts are of the same value
+// Contexts with dots are equal if their dots are, and contexts without dots if their vector clocks are.
+func (c Context) Equals(other Context) bool {
+	if c.Dot.IsZero() && other.Dot.IsZero() {
+		return c.Clock.Equals(other.Clock)
+	}
+	return c.Dot == other.Dot
+}
+
+// Returns true if the value of the context is in the causal history of the value of the other context, so the
+// other value supersedes it
+// A value with a dot is superseded by the values put with a causal context including the dot. Otherwise its vector
+// clock must be less than the version of the other value.
+func (c Context) LessThan(other Context) bool {
+	if c.Equals(other) {
+		return false
+	}
+	if !c.Dot.IsZero() {
+		return other.Clock.NodeClocks[c.Dot.NodeID] >= c.Dot.Counter
+	}
+	return c.Clock.LessThan(other.Version())
+}
+
+// Returns true if neither value of the contexts supersedes the other
+func (c Context) Concurrent(other Context) bool {
+	return c.Compare(other) == CLOCK_CONCURRENT
+}
+
+// Returns the order of the value of the context to the value of the other one, like `VectorClock.Compare`
+// Contexts without dots are compared by their vector clocks in a single pass.
+func (c Context) Compare(other Context) ClockOrder {
+	if c.Dot.IsZero() && other.Dot.IsZero() {
+		return c.Clock.Compare(other.Clock)
+	}
+
+	switch {
+	case c.Equals(other):
+		return CLOCK_EQUAL
+	case c.LessThan(other):
+		return CLOCK_BEFORE
+	case other.LessThan(c):
+		return CLOCK_AFTER
+	default:
+		return CLOCK_CONCURRENT
+	}
+}
+
+// Returns the context of a value put to the key through this server with the given context, with a new dot of this
+// server after all the updates of this server it knows for the key
+// Must be called with the key locked in the storage, and the new value stored before it is unlocked, so the dot
+// is not given to another value.
+func (s *DynamoServer) nextDottedContext(context Context, localEntries []ObjectEntry) Context {
+	clock := context.Version()
+	counter := clock.NodeClocks[s.nodeID]
+	for _, localEntry := range localEntries {
+		if localCounter := localEntry.Context.Version().NodeClocks[s.nodeID]; localCounter > counter {
+			counter = localCounter
+		}
+	}
+
+	if s.maxClockEntries > 0 {
+		clock.Prune(s.maxClockEntries)
+	}
+	return Context{
+		Clock: clock,
+		Dot:   Dot{NodeID: s.nodeID, Counter: counter + 1},
+	}
+}
+
+// Puts the value to this server with a new dot of this server like `DynamoServer.PutRaw`, and returns the PutArgs
+// of the value with its dotted context, to be put to the other servers
+func (s *DynamoServer) putRawDotted(putArgs PutArgs) (PutArgs, error) {
+	if err := s.checkCrashed(); err != nil {
+		return putArgs, err
+	}
+
+	s.storage.Lock(putArgs.Key)
+	defer s.storage.Unlock(putArgs.Key)
+
+	localEntries, err := s.storage.Get(putArgs.Key)
+	if err != nil {
+		return putArgs, err
+	}
+	putArgs.Context = s.nextDottedContext(putArgs.Context, localEntries)
+	return putArgs, s.putLockedEntry(putArgs, true)
+}
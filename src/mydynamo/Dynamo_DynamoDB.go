@@ -0,0 +1,561 @@
+package mydynamo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Subset of the DynamoDB JSON API, so AWS SDK clients can be pointed at a server
+// Requests are POSTed to `/` with the operation named by the `X-Amz-Target` header. Tables are kept in a catalog
+// stored under DYNAMODB_TABLES_KEY, and each item is stored under a key made of its table and key attributes, so both
+// are replicated like any other key. Requests are not authenticated.
+type dynamoDBGateway struct {
+	server *DynamoServer
+}
+
+// Attributes of an item, each an AttributeValue such as `{"S": "abc"}`
+type dynamoDBItem map[string]map[string]json.RawMessage
+
+type dynamoDBKeySchemaElement struct {
+	AttributeName string
+	KeyType       string //"HASH" or "RANGE"
+}
+
+type dynamoDBAttributeDefinition struct {
+	AttributeName string
+	AttributeType string //"S", "N" or "B"
+}
+
+type dynamoDBTableDescription struct {
+	TableName            string
+	TableArn             string
+	TableStatus          string
+	KeySchema            []dynamoDBKeySchemaElement
+	AttributeDefinitions []dynamoDBAttributeDefinition
+	CreationDateTime     float64 //Seconds since the epoch
+	ItemCount            int64
+	TableSizeBytes       int64
+}
+
+type dynamoDBCreateTableRequest struct {
+	TableName            string
+	KeySchema            []dynamoDBKeySchemaElement
+	AttributeDefinitions []dynamoDBAttributeDefinition
+}
+
+type dynamoDBCreateTableResponse struct {
+	TableDescription dynamoDBTableDescription
+}
+
+type dynamoDBListTablesRequest struct {
+	ExclusiveStartTableName string
+	Limit                   int
+}
+
+type dynamoDBListTablesResponse struct {
+	TableNames             []string
+	LastEvaluatedTableName string `json:",omitempty"`
+}
+
+type dynamoDBPutItemRequest struct {
+	TableName    string
+	Item         dynamoDBItem
+	ReturnValues string
+}
+
+type dynamoDBGetItemRequest struct {
+	TableName string
+	Key       dynamoDBItem
+}
+
+type dynamoDBDeleteItemRequest struct {
+	TableName    string
+	Key          dynamoDBItem
+	ReturnValues string
+}
+
+// Response of GetItem, PutItem and DeleteItem, with the item or its previous attributes if any
+type dynamoDBItemResponse struct {
+	Item       dynamoDBItem `json:",omitempty"`
+	Attributes dynamoDBItem `json:",omitempty"`
+}
+
+// Error of a DynamoDB operation, sent with its status and type
+type dynamoDBError struct {
+	status    int
+	errorType string
+	message   string
+}
+
+func (e *dynamoDBError) Error() string {
+	return e.errorType + ": " + e.message
+}
+
+type dynamoDBErrorResponse struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+var dynamoDBTableNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,255}$`)
+
+func newDynamoDBValidationError(format string, args ...interface{}) error {
+	return &dynamoDBError{status: http.StatusBadRequest, errorType: "ValidationException", message: fmt.Sprintf(format, args...)}
+}
+
+func newDynamoDBUnavailableError(err error) error {
+	return &dynamoDBError{status: http.StatusServiceUnavailable, errorType: "ServiceUnavailable", message: err.Error()}
+}
+
+func (g *dynamoDBGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != DYNAMODB_API_PATH {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeDynamoDBError(w, &dynamoDBError{
+			status:    http.StatusMethodNotAllowed,
+			errorType: "UnknownOperationException",
+			message:   fmt.Sprintf("method %s is not allowed", r.Method),
+		})
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, HTTP_MAX_BODY_BYTES))
+	if err != nil {
+		writeDynamoDBError(w, &dynamoDBError{status: http.StatusBadRequest, errorType: "SerializationException", message: err.Error()})
+		return
+	}
+
+	var response interface{}
+	switch target := r.Header.Get("X-Amz-Target"); target {
+	case DYNAMODB_TARGET_PREFIX + "CreateTable":
+		response, err = g.createTable(body)
+	case DYNAMODB_TARGET_PREFIX + "ListTables":
+		response, err = g.listTables(body)
+	case DYNAMODB_TARGET_PREFIX + "PutItem":
+		response, err = g.putItem(body)
+	case DYNAMODB_TARGET_PREFIX + "GetItem":
+		response, err = g.getItem(body)
+	case DYNAMODB_TARGET_PREFIX + "DeleteItem":
+		response, err = g.deleteItem(body)
+	default:
+		err = &dynamoDBError{status: http.StatusBadRequest, errorType: "UnknownOperationException", message: "unknown operation " + target}
+	}
+	if err != nil {
+		writeDynamoDBError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", DYNAMODB_CONTENT_TYPE)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Println(DYNAMO_SERVER, "Failed to write DynamoDB response:", err)
+	}
+}
+
+// Creates a table with the key schema of the request
+// Tables are created active. A table created concurrently through two servers is kept as created first.
+func (g *dynamoDBGateway) createTable(body []byte) (interface{}, error) {
+	var request dynamoDBCreateTableRequest
+	if err := decodeDynamoDBRequest(body, &request); err != nil {
+		return nil, err
+	}
+	if err := validateDynamoDBTable(request); err != nil {
+		return nil, err
+	}
+
+	tables, context, err := g.getTables()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := tables[request.TableName]; ok {
+		return nil, &dynamoDBError{
+			status:    http.StatusBadRequest,
+			errorType: "ResourceInUseException",
+			message:   "Table already exists: " + request.TableName,
+		}
+	}
+
+	table := dynamoDBTableDescription{
+		TableName:            request.TableName,
+		TableArn:             "arn:aws:dynamodb:local:000000000000:table/" + request.TableName,
+		TableStatus:          "ACTIVE",
+		KeySchema:            request.KeySchema,
+		AttributeDefinitions: request.AttributeDefinitions,
+		CreationDateTime:     float64(time.Now().UnixNano()) / float64(time.Second),
+	}
+	tables[request.TableName] = table
+	value, err := json.Marshal(tables)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.put(DYNAMODB_TABLES_KEY, context, value); err != nil {
+		return nil, err
+	}
+	return dynamoDBCreateTableResponse{TableDescription: table}, nil
+}
+
+// Lists the names of the tables in order, a page of at most `Limit` names at a time
+func (g *dynamoDBGateway) listTables(body []byte) (interface{}, error) {
+	var request dynamoDBListTablesRequest
+	if err := decodeDynamoDBRequest(body, &request); err != nil {
+		return nil, err
+	}
+	if request.Limit == 0 {
+		request.Limit = DYNAMODB_MAX_LIST_TABLES
+	}
+	if request.Limit < 0 || request.Limit > DYNAMODB_MAX_LIST_TABLES {
+		return nil, newDynamoDBValidationError("Limit must be between 1 and %d", DYNAMODB_MAX_LIST_TABLES)
+	}
+
+	tables, _, err := g.getTables()
+	if err != nil {
+		return nil, err
+	}
+	response := dynamoDBListTablesResponse{TableNames: make([]string, 0)}
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		if name > request.ExclusiveStartTableName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) > request.Limit {
+		names = names[:request.Limit]
+		response.LastEvaluatedTableName = names[len(names)-1]
+	}
+	response.TableNames = append(response.TableNames, names...)
+	return response, nil
+}
+
+// Puts the item, replacing the item with the same key
+// The item replaces all versions of the item read before putting it.
+func (g *dynamoDBGateway) putItem(body []byte) (interface{}, error) {
+	var request dynamoDBPutItemRequest
+	if err := decodeDynamoDBRequest(body, &request); err != nil {
+		return nil, err
+	}
+	if err := validateDynamoDBReturnValues(request.ReturnValues); err != nil {
+		return nil, err
+	}
+	table, err := g.getTable(request.TableName)
+	if err != nil {
+		return nil, err
+	}
+	key, err := table.itemKey(table.keyOf(request.Item))
+	if err != nil {
+		return nil, err
+	}
+
+	oldItem, context, err := g.getItemOfKey(key)
+	if err != nil {
+		return nil, err
+	}
+	value, err := json.Marshal(request.Item)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.put(key, context, value); err != nil {
+		return nil, err
+	}
+
+	if request.ReturnValues == "ALL_OLD" {
+		return dynamoDBItemResponse{Attributes: oldItem}, nil
+	}
+	return dynamoDBItemResponse{}, nil
+}
+
+// Gets the item of the key, the response has no item if there is none
+func (g *dynamoDBGateway) getItem(body []byte) (interface{}, error) {
+	var request dynamoDBGetItemRequest
+	if err := decodeDynamoDBRequest(body, &request); err != nil {
+		return nil, err
+	}
+	table, err := g.getTable(request.TableName)
+	if err != nil {
+		return nil, err
+	}
+	key, err := table.itemKey(request.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	item, _, err := g.getItemOfKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return dynamoDBItemResponse{Item: item}, nil
+}
+
+// Deletes the item of the key, by putting a tombstone replacing all versions of the item read before
+func (g *dynamoDBGateway) deleteItem(body []byte) (interface{}, error) {
+	var request dynamoDBDeleteItemRequest
+	if err := decodeDynamoDBRequest(body, &request); err != nil {
+		return nil, err
+	}
+	if err := validateDynamoDBReturnValues(request.ReturnValues); err != nil {
+		return nil, err
+	}
+	table, err := g.getTable(request.TableName)
+	if err != nil {
+		return nil, err
+	}
+	key, err := table.itemKey(request.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	oldItem, context, err := g.getItemOfKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if oldItem != nil {
+		if err := g.delete(key, context); err != nil {
+			return nil, err
+		}
+	}
+
+	if request.ReturnValues == "ALL_OLD" {
+		return dynamoDBItemResponse{Attributes: oldItem}, nil
+	}
+	return dynamoDBItemResponse{}, nil
+}
+
+// Returns the siblings of the key and the context combining them, to put a value replacing them
+func (g *dynamoDBGateway) getSiblings(key string) ([]ObjectEntry, Context, error) {
+	var result DynamoResult
+	if err := g.server.Get(key, &result); err != nil {
+		return nil, Context{}, newDynamoDBUnavailableError(err)
+	}
+	if result.Replies < g.server.rValue {
+		return nil, Context{}, newDynamoDBUnavailableError(fmt.Errorf("read from %d of %d servers", result.Replies, g.server.rValue))
+	}
+
+	clock := NewVectorClock()
+	for _, entry := range result.EntryList {
+		clock.Combine([]VectorClock{entry.Context.Version()})
+	}
+	return result.EntryList, NewContext(clock), nil
+}
+
+// Puts the value to the key, and returns an error if it was put to fewer than W servers
+func (g *dynamoDBGateway) put(key string, context Context, value []byte) error {
+	var result PutResult
+	err := g.server.PutWithResult(NewPutArgs(key, context, value), &result)
+	return g.checkPutResult(result, err)
+}
+
+// Deletes the key, and returns an error if its tombstone was put to fewer than W servers
+func (g *dynamoDBGateway) delete(key string, context Context) error {
+	var result PutResult
+	err := g.server.deleteWithResult(NewDeleteArgs(key, context), &result)
+	return g.checkPutResult(result, err)
+}
+
+// Returns the error of a put, or an error if the put did not succeed
+func (g *dynamoDBGateway) checkPutResult(result PutResult, err error) error {
+	if err != nil {
+		return newDynamoDBUnavailableError(err)
+	}
+	if !result.Success {
+		return newDynamoDBUnavailableError(fmt.Errorf("put to %d of %d servers", result.Acks, g.server.wValue))
+	}
+	return nil
+}
+
+// Returns the tables in the catalog and the context to put a catalog replacing it
+// The catalogs of concurrent siblings are merged.
+func (g *dynamoDBGateway) getTables() (map[string]dynamoDBTableDescription, Context, error) {
+	entries, context, err := g.getSiblings(DYNAMODB_TABLES_KEY)
+	if err != nil {
+		return nil, Context{}, err
+	}
+
+	tables := make(map[string]dynamoDBTableDescription)
+	for _, entry := range entries {
+		var siblingTables map[string]dynamoDBTableDescription
+		if err := json.Unmarshal(entry.Value, &siblingTables); err != nil {
+			return nil, Context{}, err
+		}
+		for name, table := range siblingTables {
+			if existing, ok := tables[name]; !ok || table.CreationDateTime < existing.CreationDateTime {
+				tables[name] = table
+			}
+		}
+	}
+	return tables, context, nil
+}
+
+// Returns the table with the name, or a ResourceNotFoundException if there is none
+func (g *dynamoDBGateway) getTable(name string) (dynamoDBTableDescription, error) {
+	tables, _, err := g.getTables()
+	if err != nil {
+		return dynamoDBTableDescription{}, err
+	}
+	table, ok := tables[name]
+	if !ok {
+		return dynamoDBTableDescription{}, &dynamoDBError{
+			status:    http.StatusBadRequest,
+			errorType: "ResourceNotFoundException",
+			message:   "Requested resource not found: Table: " + name + " not found",
+		}
+	}
+	return table, nil
+}
+
+// Returns the item stored under the key, nil if it has none, and the context to replace it
+// Concurrent versions of the item are resolved to the same version by every server: the version with the most
+// recent clock, then the greatest value.
+func (g *dynamoDBGateway) getItemOfKey(key string) (dynamoDBItem, Context, error) {
+	entries, context, err := g.getSiblings(key)
+	if err != nil {
+		return nil, Context{}, err
+	}
+	if len(entries) == 0 {
+		return nil, context, nil
+	}
+
+	latest := entries[0]
+	for _, entry := range entries[1:] {
+		latestSum, entrySum := clockSum(latest.Context.Version()), clockSum(entry.Context.Version())
+		if entrySum > latestSum || (entrySum == latestSum && bytes.Compare(entry.Value, latest.Value) > 0) {
+			latest = entry
+		}
+	}
+	var item dynamoDBItem
+	if err := json.Unmarshal(latest.Value, &item); err != nil {
+		return nil, Context{}, err
+	}
+	return item, context, nil
+}
+
+// Returns the sum of the counters of the clock
+func clockSum(clock VectorClock) uint64 {
+	var sum uint64
+	for _, count := range clock.NodeClocks {
+		sum += count
+	}
+	return sum
+}
+
+// Returns the key attributes of the item
+func (t *dynamoDBTableDescription) keyOf(item dynamoDBItem) dynamoDBItem {
+	key := make(dynamoDBItem)
+	for _, element := range t.KeySchema {
+		if value, ok := item[element.AttributeName]; ok {
+			key[element.AttributeName] = value
+		}
+	}
+	return key
+}
+
+// Returns the key storing the item with the key attributes, which must match the key schema of the table
+func (t *dynamoDBTableDescription) itemKey(key dynamoDBItem) (string, error) {
+	if len(key) != len(t.KeySchema) {
+		return "", newDynamoDBValidationError("The provided key element does not match the schema")
+	}
+
+	parts := make([]string, 0, 2*len(t.KeySchema))
+	for _, element := range t.KeySchema {
+		attributeType := t.attributeType(element.AttributeName)
+		value, ok := key[element.AttributeName][attributeType]
+		if !ok || len(key[element.AttributeName]) != 1 {
+			return "", newDynamoDBValidationError("The provided key element does not match the schema")
+		}
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil || s == "" {
+			return "", newDynamoDBValidationError("The key attribute %s must be a non-empty %s", element.AttributeName, attributeType)
+		}
+		parts = append(parts, attributeType, s)
+	}
+
+	encodedParts, err := json.Marshal(parts)
+	if err != nil {
+		return "", err
+	}
+	return DYNAMODB_ITEM_KEY_PREFIX + t.TableName + "/" + string(encodedParts), nil
+}
+
+// Returns the type of the attribute in the attribute definitions
+func (t *dynamoDBTableDescription) attributeType(name string) string {
+	for _, definition := range t.AttributeDefinitions {
+		if definition.AttributeName == name {
+			return definition.AttributeType
+		}
+	}
+	return ""
+}
+
+// Returns a ValidationException if the table of the request cannot be created
+func validateDynamoDBTable(request dynamoDBCreateTableRequest) error {
+	if !dynamoDBTableNamePattern.MatchString(request.TableName) {
+		return newDynamoDBValidationError("Invalid table name %q", request.TableName)
+	}
+	if len(request.KeySchema) < 1 || len(request.KeySchema) > 2 {
+		return newDynamoDBValidationError("KeySchema must have a HASH key and optionally a RANGE key")
+	}
+	if request.KeySchema[0].KeyType != "HASH" || (len(request.KeySchema) == 2 && request.KeySchema[1].KeyType != "RANGE") {
+		return newDynamoDBValidationError("KeySchema must have a HASH key and optionally a RANGE key")
+	}
+	if len(request.KeySchema) == 2 && request.KeySchema[0].AttributeName == request.KeySchema[1].AttributeName {
+		return newDynamoDBValidationError("The HASH and RANGE keys must be different attributes")
+	}
+	if len(request.AttributeDefinitions) != len(request.KeySchema) {
+		return newDynamoDBValidationError(
+			"Number of attributes in KeySchema does not exactly match number of attributes defined in AttributeDefinitions")
+	}
+
+	table := dynamoDBTableDescription{AttributeDefinitions: request.AttributeDefinitions}
+	for _, element := range request.KeySchema {
+		switch table.attributeType(element.AttributeName) {
+		case "S", "N", "B":
+		case "":
+			return newDynamoDBValidationError("The key attribute %s is not defined in AttributeDefinitions", element.AttributeName)
+		default:
+			return newDynamoDBValidationError("The key attribute %s must be of type S, N or B", element.AttributeName)
+		}
+	}
+	return nil
+}
+
+// Returns a ValidationException if the ReturnValues of a PutItem or DeleteItem are not supported
+func validateDynamoDBReturnValues(returnValues string) error {
+	switch returnValues {
+	case "", "NONE", "ALL_OLD":
+		return nil
+	default:
+		return newDynamoDBValidationError("ReturnValues %q is not supported", returnValues)
+	}
+}
+
+// Decodes the JSON body of a request, returns a SerializationException if it is malformed
+func decodeDynamoDBRequest(body []byte, request interface{}) error {
+	if err := json.Unmarshal(body, request); err != nil {
+		return &dynamoDBError{status: http.StatusBadRequest, errorType: "SerializationException", message: err.Error()}
+	}
+	return nil
+}
+
+// Writes the error with its status and type, errors which are not DynamoDB errors are internal errors
+func writeDynamoDBError(w http.ResponseWriter, err error) {
+	var dynamoDBErr *dynamoDBError
+	if !errors.As(err, &dynamoDBErr) {
+		dynamoDBErr = &dynamoDBError{status: http.StatusInternalServerError, errorType: "InternalServerError", message: err.Error()}
+	}
+
+	w.Header().Set("Content-Type", DYNAMODB_CONTENT_TYPE)
+	w.WriteHeader(dynamoDBErr.status)
+	response := dynamoDBErrorResponse{Type: DYNAMODB_ERROR_PREFIX + dynamoDBErr.errorType, Message: dynamoDBErr.message}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Println(DYNAMO_SERVER, "Failed to write DynamoDB response:", err)
+	}
+}
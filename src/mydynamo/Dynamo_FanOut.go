@@ -0,0 +1,114 @@
+package mydynamo
+
+import (
+	"context"
+	"errors"
+	"net/rpc"
+	"time"
+)
+
+// Failure of a request sent by a server to another node
+type NodeFailure struct {
+	Node        DynamoNode
+	Reason      string //Error of the request
+	Unreachable bool   //Whether the node could not be reached, as opposed to a node that responded with an error
+}
+
+// Response of a node to a request sent by `DynamoServer.fanOut`
+type fanOutResponse struct {
+	node     DynamoNode
+	err      error
+	timedOut bool
+}
+
+// Sends the request to the nodes concurrently until it succeeds on `count` nodes
+// The request is sent to the first `count` nodes, and to the next node whenever a request fails or does not respond
+// within the request timeout. Returns the nodes the request succeeded on and the failures of the other nodes it was
+// sent to, in the order they responded. Each request is given a context derived from ctx with the request timeout,
+// which is cancelled once fanOut returns, so requests sent through the connection pool with it are abandoned when they
+// time out, are no longer needed, or ctx is done.
+func (s *DynamoServer) fanOut(ctx context.Context, nodes []DynamoNode, count int, request func(ctx context.Context, node DynamoNode) error) ([]DynamoNode, []NodeFailure) {
+	succeededNodes := make([]DynamoNode, 0)
+	failures := make([]NodeFailure, 0)
+	if count <= 0 {
+		return succeededNodes, failures
+	}
+
+	// Buffered for a response and a timeout of each node, so the requests never block after returning
+	responses := make(chan fanOutResponse, 2*len(nodes))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pendingNodes := make(map[DynamoNode]bool)
+	timers := make([]*time.Timer, 0)
+	defer func() {
+		for _, timer := range timers {
+			timer.Stop()
+		}
+	}()
+	nextIndex := 0
+	for len(succeededNodes) < count {
+		for len(pendingNodes) < count-len(succeededNodes) && nextIndex < len(nodes) {
+			node := nodes[nextIndex]
+			nextIndex++
+			pendingNodes[node] = true
+
+			go func() {
+				requestCtx, requestCancel := contextWithTimeout(ctx, s.requestTimeout)
+				defer requestCancel()
+				responses <- fanOutResponse{node: node, err: request(requestCtx, node)}
+			}()
+			if s.requestTimeout > 0 {
+				timers = append(timers, time.AfterFunc(s.requestTimeout, func() {
+					responses <- fanOutResponse{node: node, err: errCallTimedOut, timedOut: true}
+				}))
+			}
+		}
+		if len(pendingNodes) == 0 {
+			break
+		}
+
+		response := <-responses
+		if !pendingNodes[response.node] {
+			continue
+		}
+		delete(pendingNodes, response.node)
+		if response.err == nil {
+			succeededNodes = append(succeededNodes, response.node)
+		} else {
+			failures = append(failures, NodeFailure{
+				Node:        response.node,
+				Reason:      response.err.Error(),
+				Unreachable: !response.timedOut && isUnreachableError(response.err),
+			})
+		}
+	}
+	return succeededNodes, failures
+}
+
+// Returns the nodes in the given list other than this node
+func (s *DynamoServer) otherNodes(nodes []DynamoNode) []DynamoNode {
+	otherNodes := make([]DynamoNode, 0, len(nodes))
+	for _, node := range nodes {
+		if node != s.selfNode {
+			otherNodes = append(otherNodes, node)
+		}
+	}
+	return otherNodes
+}
+
+// Returns the nodes of the given failures
+func nodesOfFailures(failures []NodeFailure) []DynamoNode {
+	nodes := make([]DynamoNode, 0, len(failures))
+	for _, failure := range failures {
+		nodes = append(nodes, failure.Node)
+	}
+	return nodes
+}
+
+// Returns true if the error of an RPC call means the node could not be reached
+// Errors returned by the methods of the node itself are `rpc.ServerError`s, any other error comes from the connection,
+// except for calls that timed out as the node is slow or were cancelled.
+func isUnreachableError(err error) bool {
+	var serverError rpc.ServerError
+	return err != nil && err != errCallTimedOut && !errors.Is(err, context.Canceled) && !errors.As(err, &serverError)
+}
@@ -0,0 +1,484 @@
+package mydynamo
+
+import (
+	"context"
+	"io"
+	"mydynamo/dynamopb"
+	"net/rpc"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// gRPC service of a server, mirroring its MyDynamo RPC methods
+// Each call stops waiting for the server once its context is cancelled or its deadline is exceeded, and errors of the
+// server are returned with code Unknown.
+type grpcService struct {
+	dynamopb.UnimplementedMyDynamoServer
+	server *DynamoServer
+}
+
+// Creates a new gRPC server serving the gRPC service of the server
+func newGRPCServer(server *DynamoServer) *grpc.Server {
+	grpcServer := grpc.NewServer()
+	dynamopb.RegisterMyDynamoServer(grpcServer, &grpcService{server: server})
+	return grpcServer
+}
+
+func (g *grpcService) Put(ctx context.Context, request *dynamopb.PutRequest) (*dynamopb.PutResponse, error) {
+	var result PutResult
+	if err := callWithContext(ctx, func() error {
+		return g.server.putWithResult(ctx, fromPBPutRequest(request), &result)
+	}); err != nil {
+		return nil, err
+	}
+	return &dynamopb.PutResponse{Success: result.Success, Acks: int32(result.Acks), Failures: toPBFailures(result.Failures)}, nil
+}
+
+func (g *grpcService) Delete(ctx context.Context, request *dynamopb.DeleteRequest) (*dynamopb.PutResponse, error) {
+	var result PutResult
+	if err := callWithContext(ctx, func() error {
+		return g.server.putWithResult(ctx, tombstonePutArgs(NewDeleteArgs(request.GetKey(), fromPBContext(request.GetContext()))), &result)
+	}); err != nil {
+		return nil, err
+	}
+	return &dynamopb.PutResponse{Success: result.Success, Acks: int32(result.Acks), Failures: toPBFailures(result.Failures)}, nil
+}
+
+func (g *grpcService) Get(ctx context.Context, request *dynamopb.GetRequest) (*dynamopb.GetResponse, error) {
+	var result DynamoResult
+	if err := callWithContext(ctx, func() error {
+		return g.server.get(ctx, request.Key, &result)
+	}); err != nil {
+		return nil, err
+	}
+	return toPBGetResponse(result), nil
+}
+
+func (g *grpcService) PutRaw(ctx context.Context, request *dynamopb.PutRequest) (*dynamopb.PutRawResponse, error) {
+	var result bool
+	if err := callWithContext(ctx, func() error {
+		return g.server.PutRaw(fromPBPutRequest(request), &result)
+	}); err != nil {
+		return nil, err
+	}
+	return &dynamopb.PutRawResponse{Success: result}, nil
+}
+
+func (g *grpcService) GetRaw(ctx context.Context, request *dynamopb.GetRequest) (*dynamopb.GetResponse, error) {
+	var result DynamoResult
+	if err := callWithContext(ctx, func() error {
+		return g.server.GetRaw(request.Key, &result)
+	}); err != nil {
+		return nil, err
+	}
+	return toPBGetResponse(result), nil
+}
+
+func (g *grpcService) PutRawStream(stream dynamopb.MyDynamo_PutRawStreamServer) error {
+	for {
+		request, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var result bool
+		if err := g.server.PutRaw(fromPBPutRequest(request), &result); err != nil {
+			return status.Error(codes.Unknown, err.Error())
+		}
+		if err := stream.Send(&dynamopb.PutRawResponse{Success: result}); err != nil {
+			return err
+		}
+	}
+}
+
+func (g *grpcService) Gossip(ctx context.Context, _ *dynamopb.Empty) (*dynamopb.Empty, error) {
+	return &dynamopb.Empty{}, callWithContext(ctx, func() error {
+		return g.server.Gossip(Empty{}, &Empty{})
+	})
+}
+
+func (g *grpcService) Crash(ctx context.Context, request *dynamopb.CrashRequest) (*dynamopb.CrashResponse, error) {
+	var result bool
+	if err := callWithContext(ctx, func() error {
+		return g.server.Crash(int(request.Seconds), &result)
+	}); err != nil {
+		return nil, err
+	}
+	return &dynamopb.CrashResponse{Success: result}, nil
+}
+
+func (g *grpcService) ForceCrash(ctx context.Context, _ *dynamopb.Empty) (*dynamopb.Empty, error) {
+	return &dynamopb.Empty{}, callWithContext(ctx, func() error {
+		return g.server.ForceCrash(Empty{}, &Empty{})
+	})
+}
+
+func (g *grpcService) ForceRestore(ctx context.Context, _ *dynamopb.Empty) (*dynamopb.Empty, error) {
+	return &dynamopb.Empty{}, callWithContext(ctx, func() error {
+		return g.server.ForceRestore(Empty{}, &Empty{})
+	})
+}
+
+func (g *grpcService) SendPreferenceList(ctx context.Context, request *dynamopb.NodeList) (*dynamopb.Empty, error) {
+	return &dynamopb.Empty{}, callWithContext(ctx, func() error {
+		return g.server.SendPreferenceList(fromPBNodes(request.Nodes), &Empty{})
+	})
+}
+
+// Runs the call until it returns or the context is done, and returns its error as a gRPC status error
+// A call whose context is done keeps running in the background, like a late replica of `fanOut`, so the calls that
+// send requests to other nodes are given the context too, to cancel these requests with it.
+func callWithContext(ctx context.Context, call func() error) error {
+	if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- call()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return status.Error(codes.Unknown, err.Error())
+		}
+		return nil
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// Returns the error of a gRPC call as returned by net/rpc
+// Errors returned by the other server become `rpc.ServerError`, so only transport errors are unreachable errors.
+func fromGRPCError(err error) error {
+	if s, ok := status.FromError(err); ok && err != nil && s.Code() == codes.Unknown {
+		return rpc.ServerError(s.Message())
+	}
+	return err
+}
+
+// Returns the error of a gRPC call of the connection pool as returned by `ConnectionPool.CallContext`, which is
+// `errCallTimedOut` or the error of the context for calls whose context is done
+func fromGRPCCallError(err error) error {
+	switch status.Code(err) {
+	case codes.DeadlineExceeded:
+		return errCallTimedOut
+	case codes.Canceled:
+		return context.Canceled
+	}
+	return fromGRPCError(err)
+}
+
+// Makes the pool call the replication methods through gRPC instead of net/rpc
+// The gRPC endpoints of the nodes are found from the transports they serve and the gRPC port offset.
+func (p *ConnectionPool) enableGRPC(transport string, portOffset int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.grpcTransport = transport
+	p.grpcPortOffset = portOffset
+}
+
+// Returns true if the pool calls the replication methods through gRPC
+func (p *ConnectionPool) usesGRPC() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.grpcTransport != ""
+}
+
+// Returns the gRPC client of the node, connecting to the node if there is no connection yet
+// A gRPC connection is shared by all calls to the node, and reconnects by itself.
+func (p *ConnectionPool) grpcClient(node DynamoNode) (dynamopb.MyDynamoClient, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return nil, rpc.ErrShutdown
+	}
+	if conn, ok := p.grpcConns[node]; ok {
+		return dynamopb.NewMyDynamoClient(conn), nil
+	}
+
+	atomic.AddInt64(&p.stats.Dials, 1)
+	address := node.Address + ":" + grpcPortOf(node, p.grpcTransport, p.grpcPortOffset)
+	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	p.grpcConns[node] = conn
+	return dynamopb.NewMyDynamoClient(conn), nil
+}
+
+// Calls the RPC method on the node through gRPC with the context, see `ConnectionPool.CallContext`
+// Returns false if gRPC is not enabled or the method is not a replication method, then it is called through net/rpc.
+func (p *ConnectionPool) callGRPC(ctx context.Context, node DynamoNode, method string, args interface{}, reply interface{}) (bool, error) {
+	switch method {
+	case "MyDynamo.PutRaw", "MyDynamo.GetRaw", "MyDynamo.PutWithResult", "MyDynamo.SendPreferenceList":
+	default:
+		return false, nil
+	}
+	if !p.usesGRPC() {
+		return false, nil
+	}
+
+	client, err := p.grpcClient(node)
+	if err != nil {
+		return true, err
+	}
+
+	switch method {
+	case "MyDynamo.PutRaw":
+		var response *dynamopb.PutRawResponse
+		if response, err = client.PutRaw(ctx, toPBPutRequest(args.(PutArgs))); err == nil {
+			*reply.(*bool) = response.Success
+		}
+	case "MyDynamo.GetRaw":
+		var response *dynamopb.GetResponse
+		if response, err = client.GetRaw(ctx, &dynamopb.GetRequest{Key: args.(string)}); err == nil {
+			*reply.(*DynamoResult) = fromPBGetResponse(response)
+		}
+	case "MyDynamo.PutWithResult":
+		var response *dynamopb.PutResponse
+		if response, err = client.Put(ctx, toPBPutRequest(args.(PutArgs))); err == nil {
+			*reply.(*PutResult) = fromPBPutResponse(response)
+		}
+	case "MyDynamo.SendPreferenceList":
+		_, err = client.SendPreferenceList(ctx, &dynamopb.NodeList{Nodes: toPBNodes(args.([]DynamoNode))})
+	}
+	return true, fromGRPCCallError(err)
+}
+
+// Closes the gRPC connections of the pool
+func (p *ConnectionPool) closeGRPC() {
+	p.mutex.Lock()
+	conns := p.grpcConns
+	p.grpcConns = make(map[DynamoNode]*grpc.ClientConn)
+	p.mutex.Unlock()
+
+	for _, conn := range conns {
+		_ = conn.Close()
+	}
+}
+
+// Puts entries to a node with its PutRaw method, one at a time
+type rawPutter interface {
+	putRaw(putArgs PutArgs) (bool, error)
+	close()
+}
+
+// Returns a rawPutter of the node, which streams the entries through gRPC if the pool replicates through gRPC
+// Each entry must be put within the timeout, no limit if 0.
+func (p *ConnectionPool) openRawPutter(node DynamoNode, timeout time.Duration) rawPutter {
+	if p.usesGRPC() {
+		return &grpcRawPutter{pool: p, node: node, timeout: timeout}
+	}
+	return &rpcRawPutter{pool: p, node: node, timeout: timeout}
+}
+
+// rawPutter calling PutRaw through net/rpc for each entry
+type rpcRawPutter struct {
+	pool    *ConnectionPool
+	node    DynamoNode
+	timeout time.Duration
+}
+
+func (r *rpcRawPutter) putRaw(putArgs PutArgs) (bool, error) {
+	ctx, cancel := contextWithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	var result bool
+	err := r.pool.CallContext(ctx, r.node, "MyDynamo.PutRaw", putArgs, &result)
+	return result, err
+}
+
+func (r *rpcRawPutter) close() {}
+
+// rawPutter sending the entries through a PutRawStream, which is opened on the first entry
+// The stream is cancelled if an entry is not put within the timeout, and reopened for the next entry after an error.
+type grpcRawPutter struct {
+	pool    *ConnectionPool
+	node    DynamoNode
+	timeout time.Duration
+	stream  dynamopb.MyDynamo_PutRawStreamClient
+	cancel  context.CancelFunc
+}
+
+func (g *grpcRawPutter) putRaw(putArgs PutArgs) (bool, error) {
+	response, err := g.send(putArgs)
+	if err != nil {
+		g.close()
+	}
+	err = fromGRPCCallError(err)
+	if isUnreachableError(err) {
+		atomic.AddInt64(&g.pool.stats.Unreachable, 1)
+	}
+	if err != nil {
+		return false, err
+	}
+	return response.Success, nil
+}
+
+// Sends the entry through the stream and receives its response, or returns `errCallTimedOut` after the timeout
+func (g *grpcRawPutter) send(putArgs PutArgs) (response *dynamopb.PutRawResponse, err error) {
+	if g.stream == nil {
+		client, err := g.pool.grpcClient(g.node)
+		if err != nil {
+			return nil, err
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		if g.stream, err = client.PutRawStream(ctx); err != nil {
+			cancel()
+			return nil, err
+		}
+		g.cancel = cancel
+	}
+
+	if g.timeout > 0 {
+		timer := time.AfterFunc(g.timeout, g.cancel)
+		defer func() {
+			if !timer.Stop() {
+				response, err = nil, errCallTimedOut
+			}
+		}()
+	}
+	if err = g.stream.Send(toPBPutRequest(putArgs)); err != nil {
+		// The error of the stream is returned by Recv
+		if err != io.EOF {
+			return nil, err
+		}
+	}
+	return g.stream.Recv()
+}
+
+func (g *grpcRawPutter) close() {
+	if g.stream != nil {
+		_ = g.stream.CloseSend()
+		g.cancel()
+		g.stream = nil
+	}
+}
+
+func toPBNodes(nodes []DynamoNode) []*dynamopb.Node {
+	pbNodes := make([]*dynamopb.Node, 0, len(nodes))
+	for _, node := range nodes {
+		pbNodes = append(pbNodes, &dynamopb.Node{Address: node.Address, Port: node.Port})
+	}
+	return pbNodes
+}
+
+func fromPBNodes(pbNodes []*dynamopb.Node) []DynamoNode {
+	nodes := make([]DynamoNode, 0, len(pbNodes))
+	for _, pbNode := range pbNodes {
+		nodes = append(nodes, NewDynamoNode(pbNode.GetAddress(), pbNode.GetPort()))
+	}
+	return nodes
+}
+
+func toPBContext(context Context) *dynamopb.Context {
+	pbContext := &dynamopb.Context{Clock: &dynamopb.VectorClock{
+		NodeClocks: context.Clock.NodeClocks,
+		Timestamps: context.Clock.Timestamps,
+	}}
+	if !context.Dot.IsZero() {
+		pbContext.Dot = &dynamopb.Dot{NodeId: context.Dot.NodeID, Counter: context.Dot.Counter}
+	}
+	return pbContext
+}
+
+func fromPBContext(pbContext *dynamopb.Context) Context {
+	clock := NewVectorClock()
+	for nodeID, count := range pbContext.GetClock().GetNodeClocks() {
+		clock.NodeClocks[nodeID] = count
+	}
+	for nodeID, timestamp := range pbContext.GetClock().GetTimestamps() {
+		clock.Timestamps[nodeID] = timestamp
+	}
+	context := NewContext(clock)
+	context.Dot = Dot{NodeID: pbContext.GetDot().GetNodeId(), Counter: pbContext.GetDot().GetCounter()}
+	return context
+}
+
+func toPBPutRequest(putArgs PutArgs) *dynamopb.PutRequest {
+	return &dynamopb.PutRequest{
+		Key:       putArgs.Key,
+		Context:   toPBContext(putArgs.Context),
+		Value:     putArgs.Value,
+		Deleted:   putArgs.Deleted,
+		DeletedAt: putArgs.DeletedAt,
+	}
+}
+
+func fromPBPutRequest(request *dynamopb.PutRequest) PutArgs {
+	putArgs := NewPutArgs(request.GetKey(), fromPBContext(request.GetContext()), request.GetValue())
+	putArgs.Deleted = request.GetDeleted()
+	putArgs.DeletedAt = request.GetDeletedAt()
+	return putArgs
+}
+
+func fromPBPutResponse(response *dynamopb.PutResponse) PutResult {
+	return PutResult{Success: response.Success, Acks: int(response.Acks), Failures: fromPBFailures(response.Failures)}
+}
+
+func toPBFailures(failures []NodeFailure) []*dynamopb.NodeFailure {
+	pbFailures := make([]*dynamopb.NodeFailure, 0, len(failures))
+	for _, failure := range failures {
+		pbFailures = append(pbFailures, &dynamopb.NodeFailure{
+			Node:        &dynamopb.Node{Address: failure.Node.Address, Port: failure.Node.Port},
+			Reason:      failure.Reason,
+			Unreachable: failure.Unreachable,
+		})
+	}
+	return pbFailures
+}
+
+func fromPBFailures(pbFailures []*dynamopb.NodeFailure) []NodeFailure {
+	var failures []NodeFailure
+	for _, pbFailure := range pbFailures {
+		failures = append(failures, NodeFailure{
+			Node:        NewDynamoNode(pbFailure.GetNode().GetAddress(), pbFailure.GetNode().GetPort()),
+			Reason:      pbFailure.Reason,
+			Unreachable: pbFailure.Unreachable,
+		})
+	}
+	return failures
+}
+
+func toPBGetResponse(result DynamoResult) *dynamopb.GetResponse {
+	response := &dynamopb.GetResponse{Failures: toPBFailures(result.Failures), Replies: int32(result.Replies)}
+	for _, entry := range result.EntryList {
+		response.Entries = append(response.Entries, &dynamopb.ObjectEntry{
+			Context:   toPBContext(entry.Context),
+			Value:     entry.Value,
+			Deleted:   entry.Deleted,
+			DeletedAt: entry.DeletedAt,
+		})
+	}
+	return response
+}
+
+func fromPBGetResponse(response *dynamopb.GetResponse) DynamoResult {
+	result := DynamoResult{
+		EntryList: make([]ObjectEntry, 0, len(response.Entries)),
+		Failures:  fromPBFailures(response.Failures),
+		Replies:   int(response.Replies),
+	}
+	for _, pbEntry := range response.Entries {
+		result.EntryList = append(result.EntryList, ObjectEntry{
+			Context:   fromPBContext(pbEntry.Context),
+			Value:     pbEntry.Value,
+			Deleted:   pbEntry.Deleted,
+			DeletedAt: pbEntry.DeletedAt,
+		})
+	}
+	return result
+}
@@ -0,0 +1,191 @@
+package mydynamo
+
+import (
+	"context"
+	"mydynamo/dynamopb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Client of the gRPC service of a server
+// Unlike the RPCClient, each method takes a context to set its deadline or cancel it, and returns the error of the call.
+// Errors returned by the server are `rpc.ServerError`s, as they are through net/rpc.
+type GRPCClient struct {
+	ServerAddr string
+	conn       *grpc.ClientConn
+	client     dynamopb.MyDynamoClient
+}
+
+// Creates a new gRPC client of the server at the address, which must serve the gRPC transport
+func NewDynamoGRPCClient(serverAddr string) *GRPCClient {
+	return &GRPCClient{ServerAddr: serverAddr}
+}
+
+// Connects the client to its server
+// The connection is established lazily, and reconnects by itself when it breaks.
+func (c *GRPCClient) Connect() error {
+	if c.conn != nil {
+		return nil
+	}
+
+	conn, err := grpc.Dial(c.ServerAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.client = dynamopb.NewMyDynamoClient(conn)
+	return nil
+}
+
+// Closes the connection of the client
+func (c *GRPCClient) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	c.client = nil
+	return err
+}
+
+// Puts the value to the key, see `DynamoServer.PutWithResult`
+func (c *GRPCClient) Put(ctx context.Context, value PutArgs) (*PutResult, error) {
+	if err := c.Connect(); err != nil {
+		return nil, err
+	}
+	response, err := c.client.Put(ctx, toPBPutRequest(value))
+	if err != nil {
+		return nil, fromGRPCError(err)
+	}
+	result := fromPBPutResponse(response)
+	return &result, nil
+}
+
+// Deletes the key, see `DynamoServer.Delete`
+func (c *GRPCClient) Delete(ctx context.Context, value DeleteArgs) (*PutResult, error) {
+	if err := c.Connect(); err != nil {
+		return nil, err
+	}
+	response, err := c.client.Delete(ctx, &dynamopb.DeleteRequest{Key: value.Key, Context: toPBContext(value.Context)})
+	if err != nil {
+		return nil, fromGRPCError(err)
+	}
+	result := fromPBPutResponse(response)
+	return &result, nil
+}
+
+// Gets the siblings of the key, see `DynamoServer.Get`
+func (c *GRPCClient) Get(ctx context.Context, key string) (*DynamoResult, error) {
+	if err := c.Connect(); err != nil {
+		return nil, err
+	}
+	response, err := c.client.Get(ctx, &dynamopb.GetRequest{Key: key})
+	if err != nil {
+		return nil, fromGRPCError(err)
+	}
+	result := fromPBGetResponse(response)
+	return &result, nil
+}
+
+// Puts the value to the server only, see `DynamoServer.PutRaw`
+func (c *GRPCClient) PutRaw(ctx context.Context, value PutArgs) (bool, error) {
+	if err := c.Connect(); err != nil {
+		return false, err
+	}
+	response, err := c.client.PutRaw(ctx, toPBPutRequest(value))
+	if err != nil {
+		return false, fromGRPCError(err)
+	}
+	return response.Success, nil
+}
+
+// Puts the values to the server only through a single stream, and returns the result of each value
+// Stops at the first error, returning the results of the values put before it.
+func (c *GRPCClient) PutRawStream(ctx context.Context, values []PutArgs) ([]bool, error) {
+	if err := c.Connect(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.client.PutRawStream(ctx)
+	if err != nil {
+		return nil, fromGRPCError(err)
+	}
+	results := make([]bool, 0, len(values))
+	for _, value := range values {
+		if err := stream.Send(toPBPutRequest(value)); err != nil {
+			// The error of the stream is returned by Recv
+			_, err = stream.Recv()
+			return results, fromGRPCError(err)
+		}
+		response, err := stream.Recv()
+		if err != nil {
+			return results, fromGRPCError(err)
+		}
+		results = append(results, response.Success)
+	}
+	return results, fromGRPCError(stream.CloseSend())
+}
+
+// Gets the siblings of the key from the server only, see `DynamoServer.GetRaw`
+func (c *GRPCClient) GetRaw(ctx context.Context, key string) (*DynamoResult, error) {
+	if err := c.Connect(); err != nil {
+		return nil, err
+	}
+	response, err := c.client.GetRaw(ctx, &dynamopb.GetRequest{Key: key})
+	if err != nil {
+		return nil, fromGRPCError(err)
+	}
+	result := fromPBGetResponse(response)
+	return &result, nil
+}
+
+// Makes the server replicate its entries to its preference list
+func (c *GRPCClient) Gossip(ctx context.Context) error {
+	if err := c.Connect(); err != nil {
+		return err
+	}
+	_, err := c.client.Gossip(ctx, &dynamopb.Empty{})
+	return fromGRPCError(err)
+}
+
+// Crashes the server for the seconds
+func (c *GRPCClient) Crash(ctx context.Context, seconds int) (bool, error) {
+	if err := c.Connect(); err != nil {
+		return false, err
+	}
+	response, err := c.client.Crash(ctx, &dynamopb.CrashRequest{Seconds: int32(seconds)})
+	if err != nil {
+		return false, fromGRPCError(err)
+	}
+	return response.Success, nil
+}
+
+// Crashes the server until it is restored
+func (c *GRPCClient) ForceCrash(ctx context.Context) error {
+	if err := c.Connect(); err != nil {
+		return err
+	}
+	_, err := c.client.ForceCrash(ctx, &dynamopb.Empty{})
+	return fromGRPCError(err)
+}
+
+// Restores the crashed server
+func (c *GRPCClient) ForceRestore(ctx context.Context) error {
+	if err := c.Connect(); err != nil {
+		return err
+	}
+	_, err := c.client.ForceRestore(ctx, &dynamopb.Empty{})
+	return fromGRPCError(err)
+}
+
+// Sends the preference list to the server
+func (c *GRPCClient) SendPreferenceList(ctx context.Context, nodes []DynamoNode) error {
+	if err := c.Connect(); err != nil {
+		return err
+	}
+	_, err := c.client.SendPreferenceList(ctx, &dynamopb.NodeList{Nodes: toPBNodes(nodes)})
+	return fromGRPCError(err)
+}
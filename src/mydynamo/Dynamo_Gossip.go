@@ -0,0 +1,52 @@
+package mydynamo
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Gossips with random peers periodically until the server is shut down
+// Each round waits for the gossip interval plus a random jitter, so the servers do not gossip in lockstep. Rounds are
+// skipped while the server is crashed.
+func (s *DynamoServer) runGossipLoop() {
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for {
+		interval := s.gossipInterval
+		if s.gossipJitter > 0 {
+			interval += time.Duration(random.Int63n(int64(s.gossipJitter) + 1))
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-s.lifecycle.done:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if s.checkCrashed() != nil {
+			continue
+		}
+		s.gossipTo(s.randomPeers(random, s.gossipFanout))
+	}
+}
+
+// Returns the given number of random peers from the preference list, or all peers if count is 0
+func (s *DynamoServer) randomPeers(random *rand.Rand, count int) []DynamoNode {
+	nodes, _ := s.topology()
+	peers := make([]DynamoNode, 0, len(nodes))
+	for _, node := range nodes {
+		if node != s.selfNode {
+			peers = append(peers, node)
+		}
+	}
+
+	if count <= 0 || count >= len(peers) {
+		return peers
+	}
+	random.Shuffle(len(peers), func(i, j int) {
+		peers[i], peers[j] = peers[j], peers[i]
+	})
+	return peers[:count]
+}
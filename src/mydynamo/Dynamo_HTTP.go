@@ -0,0 +1,189 @@
+package mydynamo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// HTTP/JSON gateway to the Get and Put of a server, for clients without Go's net/rpc
+// `GET /kv/{key}` returns the siblings of the key, and `PUT /kv/{key}` puts a value with the context token of a
+// previous GET. Values are base64 encoded. Quorum failures and crashed servers are reported with status 503.
+type httpGateway struct {
+	server *DynamoServer
+}
+
+// A value of a key with its context token
+type httpSibling struct {
+	Value   string `json:"value"`
+	Context string `json:"context"`
+}
+
+// A node that failed during a Get or a Put
+type httpFailure struct {
+	Node   string `json:"node"`
+	Reason string `json:"reason"`
+}
+
+// Response to `GET /kv/{key}`
+type httpGetResponse struct {
+	Key      string        `json:"key"`
+	Siblings []httpSibling `json:"siblings"`
+	Context  string        `json:"context"` //Token combining the contexts of all siblings, to put a value replacing them
+	Failures []httpFailure `json:"failures,omitempty"`
+}
+
+// Request of `PUT /kv/{key}`
+type httpPutRequest struct {
+	Value   string `json:"value"`
+	Context string `json:"context"` //Token of a previous GET, the value is put with a new context if empty
+}
+
+// Response to `PUT /kv/{key}`
+type httpPutResponse struct {
+	Key      string        `json:"key"`
+	Success  bool          `json:"success"`
+	Acks     int           `json:"acks"`
+	Failures []httpFailure `json:"failures,omitempty"`
+}
+
+// Response of a failed request
+type httpErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Returns the opaque token of the context, which is the base64 encoded binary encoding of its version
+// (see `Context.Version` and `VectorClock.MarshalBinary`)
+func EncodeContextToken(context Context) string {
+	data, _ := context.Version().MarshalBinary()
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Returns the context of the token returned by `EncodeContextToken`, an empty token is a new context
+func DecodeContextToken(token string) (Context, error) {
+	clock := NewVectorClock()
+	if token == "" {
+		return NewContext(clock), nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Context{}, err
+	}
+	if err := clock.UnmarshalBinary(data); err != nil {
+		return Context{}, err
+	}
+	return NewContext(clock), nil
+}
+
+func (g *httpGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, HTTP_KV_PATH)
+	if key == "" {
+		writeHTTPError(w, http.StatusNotFound, errors.New("missing key"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		g.get(w, key)
+	case http.MethodPut:
+		g.put(w, r, key)
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPut)
+		writeHTTPError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+	}
+}
+
+// Responds with the siblings of the key
+// The status is 404 if the key has no siblings, and 503 if fewer than R nodes were read from.
+func (g *httpGateway) get(w http.ResponseWriter, key string) {
+	var result DynamoResult
+	if err := g.server.Get(key, &result); err != nil {
+		writeHTTPError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	clock := NewVectorClock()
+	response := httpGetResponse{Key: key, Siblings: make([]httpSibling, 0), Failures: httpFailures(result.Failures)}
+	for _, entry := range result.EntryList {
+		response.Siblings = append(response.Siblings, httpSibling{
+			Value:   base64.StdEncoding.EncodeToString(entry.Value),
+			Context: EncodeContextToken(entry.Context),
+		})
+		clock.Combine([]VectorClock{entry.Context.Version()})
+	}
+	response.Context = EncodeContextToken(NewContext(clock))
+
+	status := http.StatusOK
+	if result.Replies < g.server.rValue {
+		status = http.StatusServiceUnavailable
+	} else if len(response.Siblings) == 0 {
+		status = http.StatusNotFound
+	}
+	writeHTTPResponse(w, status, response)
+}
+
+// Puts the value of the request to the key
+// The status is 400 if the request is malformed, and 503 if fewer than W nodes acked the value.
+func (g *httpGateway) put(w http.ResponseWriter, r *http.Request, key string) {
+	var request httpPutRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, HTTP_MAX_BODY_BYTES))
+	if err := decoder.Decode(&request); err != nil {
+		writeHTTPError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+		return
+	}
+	value, err := base64.StdEncoding.DecodeString(request.Value)
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, fmt.Errorf("invalid value: %v", err))
+		return
+	}
+	context, err := DecodeContextToken(request.Context)
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, fmt.Errorf("invalid context: %v", err))
+		return
+	}
+
+	var result PutResult
+	if err := g.server.PutWithResult(NewPutArgs(key, context, value), &result); err != nil {
+		writeHTTPError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	status := http.StatusOK
+	if !result.Success {
+		status = http.StatusServiceUnavailable
+	}
+	writeHTTPResponse(w, status, httpPutResponse{
+		Key:      key,
+		Success:  result.Success,
+		Acks:     result.Acks,
+		Failures: httpFailures(result.Failures),
+	})
+}
+
+// Returns the failures of the nodes in the responses of the gateway
+func httpFailures(failures []NodeFailure) []httpFailure {
+	converted := make([]httpFailure, 0, len(failures))
+	for _, failure := range failures {
+		converted = append(converted, httpFailure{Node: failure.Node.Address + ":" + failure.Node.Port, Reason: failure.Reason})
+	}
+	return converted
+}
+
+// Writes the response as JSON with the status
+func writeHTTPResponse(w http.ResponseWriter, status int, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Println(DYNAMO_SERVER, "Failed to write HTTP response:", err)
+	}
+}
+
+// Writes the error as JSON with the status
+func writeHTTPError(w http.ResponseWriter, status int, err error) {
+	writeHTTPResponse(w, status, httpErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,247 @@
+package mydynamo
+
+import (
+	"errors"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// A write kept for a node that was unavailable when it was put (hinted handoff)
+type Hint struct {
+	Owner   DynamoNode //The node the write was intended for
+	PutArgs PutArgs
+}
+
+// Storage of the hints kept by a node
+// Hints are kept apart from the local entries, so they are never returned by Get nor gossiped. The hints for the same
+// key and owner are kept as siblings, dropping the ones superseded by a newer hint.
+type HintStore struct {
+	storage StorageEngine
+}
+
+// Creates a new HintStore persisting the hints in the given directory, or keeping them in memory if dir is empty
+// The store must be opened with `HintStore.Open` before use.
+func NewHintStore(dir string) HintStore {
+	var storage StorageEngine
+	if dir == "" {
+		entriesMap := NewObjectEntriesMap()
+		storage = &entriesMap
+	} else {
+		storage = NewDiskStorageEngine(dir)
+	}
+
+	return HintStore{
+		storage: storage,
+	}
+}
+
+// Returns the key of the hints for the given owner and key in the storage
+func hintStorageKey(owner DynamoNode, key string) string {
+	return owner.Address + ":" + owner.Port + "\n" + key
+}
+
+// Returns the owner and key of the hints stored with the given key
+func parseHintStorageKey(storageKey string) (DynamoNode, string, error) {
+	ownerAndKey := strings.SplitN(storageKey, "\n", 2)
+	if len(ownerAndKey) != 2 {
+		return DynamoNode{}, "", errors.New("invalid hint key")
+	}
+
+	i := strings.LastIndex(ownerAndKey[0], ":")
+	if i < 0 {
+		return DynamoNode{}, "", errors.New("invalid hint owner")
+	}
+	return NewDynamoNode(ownerAndKey[0][:i], ownerAndKey[0][i+1:]), ownerAndKey[1], nil
+}
+
+// Prepares the store for use
+func (h *HintStore) Open() error {
+	return h.storage.Open()
+}
+
+// Releases the resources held by the store
+func (h *HintStore) Close() error {
+	return h.storage.Close()
+}
+
+// Adds the hint to the store
+// The hint is ignored if it is causally older than or equal to a hint kept for the same key and owner.
+func (h *HintStore) Add(hint Hint) error {
+	storageKey := hintStorageKey(hint.Owner, hint.PutArgs.Key)
+	context := hint.PutArgs.Context
+
+	h.storage.Lock(storageKey)
+	defer h.storage.Unlock(storageKey)
+
+	entries, err := h.storage.Get(storageKey)
+	if err != nil {
+		return err
+	}
+
+	newEntries := make([]ObjectEntry, 0, len(entries)+1)
+	for _, entry := range entries {
+		if context.LessThan(entry.Context) || context.Equals(entry.Context) {
+			return nil
+		}
+		if !entry.Context.LessThan(context) {
+			newEntries = append(newEntries, entry)
+		}
+	}
+	newEntries = append(newEntries, entryOfPutArgs(hint.PutArgs))
+
+	return h.storage.Put(storageKey, newEntries)
+}
+
+// Returns all hints in the store
+func (h *HintStore) List() ([]Hint, error) {
+	hints := make([]Hint, 0)
+	for _, storageKey := range h.storage.GetKeys() {
+		owner, key, err := parseHintStorageKey(storageKey)
+		if err != nil {
+			return nil, err
+		}
+
+		h.storage.RLock(storageKey)
+		entries, err := h.storage.Get(storageKey)
+		h.storage.RUnlock(storageKey)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			hints = append(hints, Hint{
+				Owner:   owner,
+				PutArgs: NewPutArgsFromEntry(key, entry),
+			})
+		}
+	}
+	return hints, nil
+}
+
+// Removes the hint from the store, together with the hints for the same key and owner that are causally older
+func (h *HintStore) Remove(hint Hint) error {
+	storageKey := hintStorageKey(hint.Owner, hint.PutArgs.Key)
+	context := hint.PutArgs.Context
+
+	h.storage.Lock(storageKey)
+	defer h.storage.Unlock(storageKey)
+
+	entries, err := h.storage.Get(storageKey)
+	if err != nil {
+		return err
+	}
+
+	newEntries := make([]ObjectEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Context.LessThan(context) && !entry.Context.Equals(context) {
+			newEntries = append(newEntries, entry)
+		}
+	}
+	return h.storage.Put(storageKey, newEntries)
+}
+
+// Stores a hint for a node that is unavailable
+// This is an internal method used by other servers to put a write intended for another node to this server
+// (through RPC). The hint is handed off to its owner once the owner is available again.
+func (s *DynamoServer) PutHint(hint Hint, result *bool) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+
+	if err := s.hints.Add(hint); err != nil {
+		*result = false
+		return err
+	}
+
+	*result = true
+	return nil
+}
+
+// Returns the hints kept by this server
+func (s *DynamoServer) GetHints(_ Empty, result *[]Hint) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+
+	hints, err := s.hints.List()
+	if err != nil {
+		return err
+	}
+
+	*result = hints
+	return nil
+}
+
+// Forces server to hand off the hints it keeps to their owners
+// The parameter `result *int` is set to the number of hints handed off.
+func (s *DynamoServer) DeliverHints(_ Empty, result *int) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+
+	count, err := s.deliverHints()
+	*result = count
+	return err
+}
+
+// Hands off the hints to their owners and removes the delivered hints
+// Hints for owners that are still unavailable are kept. Returns the number of hints handed off.
+func (s *DynamoServer) deliverHints() (int, error) {
+	hints, err := s.hints.List()
+	if err != nil {
+		return 0, err
+	}
+
+	deliveredCount := 0
+	unreachableOwners := make(map[DynamoNode]bool)
+	for _, hint := range hints {
+		if unreachableOwners[hint.Owner] {
+			continue
+		}
+
+		var result bool
+		if err := s.connections.Call(hint.Owner, "MyDynamo.PutRaw", hint.PutArgs, &result); err != nil || !result {
+			unreachableOwners[hint.Owner] = true
+			continue
+		}
+
+		if err := s.hints.Remove(hint); err != nil {
+			return deliveredCount, err
+		}
+		deliveredCount++
+	}
+	return deliveredCount, nil
+}
+
+// Hands off hints periodically until the server is shut down
+func (s *DynamoServer) runHintedHandoffLoop() {
+	ticker := time.NewTicker(s.handoffInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.lifecycle.done:
+			return
+		case <-ticker.C:
+		}
+
+		if s.checkCrashed() != nil {
+			continue
+		}
+		if count, err := s.deliverHints(); err != nil {
+			log.Println(DYNAMO_SERVER, "Failed to hand off hints:", err)
+		} else if count > 0 {
+			log.Println(DYNAMO_SERVER, "Handed off", count, "hints")
+		}
+	}
+}
+
+// Returns the directory of the hints in the given data directory, or an empty string if persistence is disabled
+func hintsDir(dataDir string) string {
+	if dataDir == "" {
+		return ""
+	}
+	return filepath.Join(dataDir, HINTS_DIR_NAME)
+}
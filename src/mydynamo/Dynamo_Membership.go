@@ -0,0 +1,287 @@
+package mydynamo
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// A node in the membership view of a server
+type Member struct {
+	Node       DynamoNode
+	Status     string //Liveness of the node, "alive", "suspect" or "dead"
+	Generation int64  //Start time of the node in nanoseconds, so the heartbeats of a restarted node are newer
+	Heartbeat  uint64 //Heartbeat counter of the node in its generation, only incremented by the node itself
+}
+
+// Returns true if the heartbeat of the member is newer than the heartbeat of the other member
+func (member Member) isNewerThan(other Member) bool {
+	if member.Generation != other.Generation {
+		return member.Generation > other.Generation
+	}
+	return member.Heartbeat > other.Heartbeat
+}
+
+// Member together with the local time its heartbeat last increased
+type memberState struct {
+	Member
+	updatedAt time.Time
+}
+
+// Membership view of a server, with a heartbeat failure detector
+// Each node increments its own heartbeat periodically, and the views are gossiped between the nodes, so heartbeats and
+// new nodes spread through the cluster. A node whose heartbeat has not increased for the suspect timeout is suspected,
+// and considered dead after the dead timeout. It is alive again as soon as its heartbeat increases.
+// Once the nodes of the cluster are set, dead nodes outside them, e.g. decommissioned nodes, are evicted from the view,
+// and only added back if their heartbeat increases.
+// The membership is safe for concurrent use.
+type Membership struct {
+	mutex          sync.Mutex
+	self           DynamoNode
+	members        map[DynamoNode]*memberState
+	nodes          map[DynamoNode]bool   //Nodes of the cluster set by SetNodes, nil until it is called
+	evicted        map[DynamoNode]Member //Last known heartbeats of the evicted nodes
+	suspectTimeout time.Duration
+	deadTimeout    time.Duration
+}
+
+// Creates a new Membership of the given node, which only knows the node itself
+func NewMembership(self DynamoNode, suspectTimeout time.Duration, deadTimeout time.Duration) *Membership {
+	m := &Membership{
+		self:           self,
+		members:        make(map[DynamoNode]*memberState),
+		evicted:        make(map[DynamoNode]Member),
+		suspectTimeout: suspectTimeout,
+		deadTimeout:    deadTimeout,
+	}
+	m.members[self] = &memberState{
+		Member:    Member{Node: self, Status: MEMBER_ALIVE, Generation: time.Now().UnixNano()},
+		updatedAt: time.Now(),
+	}
+	return m
+}
+
+// Adds the given nodes to the view as alive, if they are not known yet
+func (m *Membership) AddNodes(nodes []DynamoNode) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.addNodes(nodes)
+}
+
+// Sets the nodes of the cluster, adding the unknown nodes as alive and evicting the nodes not in the given list
+func (m *Membership) SetNodes(nodes []DynamoNode) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.nodes = make(map[DynamoNode]bool, len(nodes))
+	for _, node := range nodes {
+		m.nodes[node] = true
+	}
+	for node, state := range m.members {
+		if node != m.self && !m.nodes[node] {
+			m.evict(state)
+		}
+	}
+	m.addNodes(nodes)
+}
+
+// Adds the given nodes to the view as alive, if they are not known yet, the mutex must be held
+func (m *Membership) addNodes(nodes []DynamoNode) {
+	for _, node := range nodes {
+		if _, ok := m.members[node]; !ok {
+			m.members[node] = &memberState{
+				Member:    Member{Node: node, Status: MEMBER_ALIVE},
+				updatedAt: time.Now(),
+			}
+			delete(m.evicted, node)
+		}
+	}
+}
+
+// Removes the member from the view, remembering its heartbeat, the mutex must be held
+func (m *Membership) evict(state *memberState) {
+	m.evicted[state.Node] = state.Member
+	delete(m.members, state.Node)
+}
+
+// Increments the heartbeat of the node itself
+func (m *Membership) Beat() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	state := m.members[m.self]
+	state.Heartbeat++
+	state.updatedAt = time.Now()
+}
+
+// Merges the view of another node into this view
+// The nodes with a newer heartbeat than known are alive, and unknown nodes are added with their heartbeats, unless
+// they were evicted with a heartbeat at least as new.
+func (m *Membership) Merge(members []Member) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, member := range members {
+		if member.Node == m.self {
+			continue
+		}
+
+		state, ok := m.members[member.Node]
+		if !ok {
+			if evicted, ok := m.evicted[member.Node]; ok && !member.isNewerThan(evicted) {
+				continue
+			}
+			delete(m.evicted, member.Node)
+			state = &memberState{Member: Member{Node: member.Node}}
+			m.members[member.Node] = state
+		} else if !member.isNewerThan(state.Member) {
+			continue
+		}
+		state.Generation = member.Generation
+		state.Heartbeat = member.Heartbeat
+		state.Status = MEMBER_ALIVE
+		state.updatedAt = time.Now()
+	}
+}
+
+// Updates the liveness of the nodes from the time their heartbeats last increased, and evicts the dead nodes outside
+// the nodes of the cluster
+func (m *Membership) Detect() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	for node, state := range m.members {
+		if node == m.self {
+			continue
+		}
+
+		elapsed := now.Sub(state.updatedAt)
+		if elapsed >= m.deadTimeout && m.nodes != nil && !m.nodes[node] {
+			m.evict(state)
+		} else if elapsed >= m.deadTimeout {
+			state.Status = MEMBER_DEAD
+		} else if elapsed >= m.suspectTimeout {
+			state.Status = MEMBER_SUSPECT
+		} else {
+			state.Status = MEMBER_ALIVE
+		}
+	}
+}
+
+// Returns the members of the view, ordered by address and port
+func (m *Membership) Members() []Member {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	members := make([]Member, 0, len(m.members))
+	for _, state := range m.members {
+		members = append(members, state.Member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Node.Address != members[j].Node.Address {
+			return members[i].Node.Address < members[j].Node.Address
+		}
+		return members[i].Node.Port < members[j].Node.Port
+	})
+	return members
+}
+
+// Returns the liveness of the node, unknown nodes are considered alive
+func (m *Membership) Status(node DynamoNode) string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if state, ok := m.members[node]; ok {
+		return state.Status
+	}
+	return MEMBER_ALIVE
+}
+
+// Returns the given nodes with the alive nodes first, then the suspected nodes and the dead nodes
+// The order of nodes with the same liveness is kept.
+func (m *Membership) SortByLiveness(nodes []DynamoNode) []DynamoNode {
+	rank := map[string]int{MEMBER_ALIVE: 0, MEMBER_SUSPECT: 1, MEMBER_DEAD: 2}
+	sortedNodes := append([]DynamoNode(nil), nodes...)
+	sort.SliceStable(sortedNodes, func(i, j int) bool {
+		return rank[m.Status(sortedNodes[i])] < rank[m.Status(sortedNodes[j])]
+	})
+	return sortedNodes
+}
+
+// Returns the given number of random nodes of the view other than the node itself
+func (m *Membership) randomPeers(random *rand.Rand, count int) []DynamoNode {
+	peers := make([]DynamoNode, 0)
+	for _, member := range m.Members() {
+		if member.Node != m.self {
+			peers = append(peers, member.Node)
+		}
+	}
+
+	random.Shuffle(len(peers), func(i, j int) {
+		peers[i], peers[j] = peers[j], peers[i]
+	})
+	if count < len(peers) {
+		peers = peers[:count]
+	}
+	return peers
+}
+
+// Merges the membership view of another server and returns the view of this server
+// This is an internal method used by other servers to gossip their views to this server (through RPC).
+func (s *DynamoServer) ExchangeMembership(members []Member, result *[]Member) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+
+	s.membership.Merge(members)
+	*result = s.membership.Members()
+	return nil
+}
+
+// Returns the membership view of this server, with the liveness of each node
+func (s *DynamoServer) GetMembership(_ Empty, result *[]Member) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+
+	*result = s.membership.Members()
+	return nil
+}
+
+// Beats and exchanges the membership view with random peers periodically until the server is shut down
+// Rounds are skipped while the server is crashed, so the other servers detect it as failed.
+func (s *DynamoServer) runHeartbeatLoop() {
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	ticker := time.NewTicker(s.beatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.lifecycle.done:
+			return
+		case <-ticker.C:
+		}
+
+		s.membership.Detect()
+		if s.checkCrashed() != nil {
+			continue
+		}
+
+		s.membership.Beat()
+		members := s.membership.Members()
+		for _, peer := range s.membership.randomPeers(random, MEMBERSHIP_FANOUT) {
+			// A peer that does not respond within the request timeout must not delay the next heartbeat
+			var peerMembers []Member
+			ctx, cancel := contextWithTimeout(context.Background(), s.requestTimeout)
+			err := s.connections.CallContext(ctx, peer, "MyDynamo.ExchangeMembership", members, &peerMembers)
+			cancel()
+			if err == nil {
+				s.membership.Merge(peerMembers)
+			}
+		}
+	}
+}
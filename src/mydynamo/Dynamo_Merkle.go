@@ -0,0 +1,521 @@
+package mydynamo
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Merkle tree of the entries of a node
+// Keys are spread over 2^MERKLE_TREE_DEPTH leaves (buckets) by their hashes. The hash of a leaf covers the keys in
+// the bucket and the digests of their entries, and the hash of an inner node covers the hashes of its children. Empty
+// subtrees hash to 0. Nodes are indexed as a binary heap: the root is 1 and the children of node i are 2i and 2i+1.
+type MerkleTree struct {
+	hashes  []uint64            //Hashes of the tree nodes by index
+	digests map[string][]uint64 //Digests of the entries of each key
+	buckets [][]string          //Sorted keys in each bucket
+}
+
+// Merkle trees of the anti-entropy rounds of the peers
+// A peer sends the ID of its round with each request, and the tree built for the first request of the round serves the
+// other requests of the round, instead of being built again for each request. The tree is removed once the peer
+// requests the digests, or expires if the peer does not finish the round.
+type merkleTreeCache struct {
+	lastRound uint64 //ID of the last round started by this node, accessed atomically
+	mutex     sync.Mutex
+	trees     map[merkleRound]*merkleRoundTree //Tree of the entries shared with the peer in each round in progress
+}
+
+// Anti-entropy round of a peer
+type merkleRound struct {
+	peer DynamoNode
+	id   uint64
+}
+
+// Merkle tree of a round together with the time it was built
+type merkleRoundTree struct {
+	tree      *MerkleTree
+	createdAt time.Time
+}
+
+// Creates a new merkleTreeCache
+// The IDs of the rounds started by this node begin at its start time, so a restarted node does not reuse the IDs of
+// rounds still cached by its peers.
+func newMerkleTreeCache() *merkleTreeCache {
+	return &merkleTreeCache{
+		trees:     make(map[merkleRound]*merkleRoundTree),
+		lastRound: uint64(time.Now().UnixNano()),
+	}
+}
+
+// Arguments of the GetMerkleHashes RPC
+type MerkleHashesArgs struct {
+	Peer    DynamoNode //The node requesting the hashes
+	Round   uint64     //ID of the anti-entropy round of the requesting node
+	Indices []int      //Indices of the tree nodes
+}
+
+// Arguments of the GetMerkleDigests RPC
+type MerkleDigestsArgs struct {
+	Peer    DynamoNode //The node requesting the digests
+	Round   uint64     //ID of the anti-entropy round of the requesting node
+	Buckets []int      //Buckets (leaf numbers) to return the digests of
+}
+
+// Arguments of the GetMerkleEntries RPC
+type MerkleEntriesArgs struct {
+	Peer    DynamoNode          //The node requesting the entries
+	Digests map[string][]uint64 //Digests of the requested entries by key
+}
+
+// Counters of the replication of entries by a node to other nodes
+type ReplicationStats struct {
+	GossipBytes        int64 //Bytes of the entries pushed by Gossip
+	AntiEntropyBytes   int64 //Bytes of the hashes, digests and entries exchanged by AntiEntropy
+	ReadRepairs        int64 //Entries written back to stale replicas by read repair
+	ReadRepairFailures int64 //Entries read repair failed to write back to stale replicas
+}
+
+// Returns the bucket of the given key
+func merkleBucket(key string) int {
+	return int(ringHash(key) >> (64 - MERKLE_TREE_DEPTH))
+}
+
+// Returns the digest of an entry, which is equal for entries with equal contexts and values
+func entryDigest(entry ObjectEntry) uint64 {
+	hash := md5.New()
+	hash.Write([]byte(entry.Context.Identity()))
+	hash.Write([]byte{0})
+	hash.Write(entry.Value)
+	return binary.BigEndian.Uint64(hash.Sum(nil)[:8])
+}
+
+// Creates a new MerkleTree of the given entries
+func NewMerkleTree(entries map[string][]ObjectEntry) MerkleTree {
+	leafCount := 1 << MERKLE_TREE_DEPTH
+	tree := MerkleTree{
+		hashes:  make([]uint64, 2*leafCount),
+		digests: make(map[string][]uint64, len(entries)),
+		buckets: make([][]string, leafCount),
+	}
+
+	for key, keyEntries := range entries {
+		if len(keyEntries) == 0 {
+			continue
+		}
+
+		digests := make([]uint64, 0, len(keyEntries))
+		for _, entry := range keyEntries {
+			digests = append(digests, entryDigest(entry))
+		}
+		sort.Slice(digests, func(i, j int) bool { return digests[i] < digests[j] })
+		tree.digests[key] = digests
+
+		bucket := merkleBucket(key)
+		tree.buckets[bucket] = append(tree.buckets[bucket], key)
+	}
+
+	buf := make([]byte, 8)
+	for bucket, keys := range tree.buckets {
+		if len(keys) == 0 {
+			continue
+		}
+		sort.Strings(keys)
+
+		hash := md5.New()
+		for _, key := range keys {
+			hash.Write([]byte(key))
+			hash.Write([]byte{0})
+			for _, digest := range tree.digests[key] {
+				binary.BigEndian.PutUint64(buf, digest)
+				hash.Write(buf)
+			}
+		}
+		tree.hashes[leafCount+bucket] = binary.BigEndian.Uint64(hash.Sum(nil)[:8])
+	}
+
+	for i := leafCount - 1; i >= 1; i-- {
+		left, right := tree.hashes[2*i], tree.hashes[2*i+1]
+		if left == 0 && right == 0 {
+			continue
+		}
+
+		hash := md5.New()
+		binary.BigEndian.PutUint64(buf, left)
+		hash.Write(buf)
+		binary.BigEndian.PutUint64(buf, right)
+		hash.Write(buf)
+		tree.hashes[i] = binary.BigEndian.Uint64(hash.Sum(nil)[:8])
+	}
+
+	return tree
+}
+
+// Returns the hash of the tree node with the given index
+func (t *MerkleTree) Hash(index int) (uint64, error) {
+	if index < 1 || index >= len(t.hashes) {
+		return 0, fmt.Errorf("invalid Merkle tree index %d", index)
+	}
+	return t.hashes[index], nil
+}
+
+// Returns true if the tree node with the given index is a leaf
+func (t *MerkleTree) IsLeaf(index int) bool {
+	return index >= len(t.hashes)/2
+}
+
+// Returns the digests of the entries of the keys in the given buckets
+func (t *MerkleTree) Digests(buckets []int) (map[string][]uint64, error) {
+	digests := make(map[string][]uint64)
+	for _, bucket := range buckets {
+		if bucket < 0 || bucket >= len(t.buckets) {
+			return nil, fmt.Errorf("invalid Merkle tree bucket %d", bucket)
+		}
+		for _, key := range t.buckets[bucket] {
+			digests[key] = t.digests[key]
+		}
+	}
+	return digests, nil
+}
+
+// Returns the local entries of the keys replicated to both this node and the peer
+func (s *DynamoServer) entriesSharedWith(peer DynamoNode) (map[string][]ObjectEntry, error) {
+	entries := make(map[string][]ObjectEntry)
+	for _, key := range s.storage.GetKeys() {
+		if !s.isPreferredNodeOfKey(peer, key) || !s.isPreferredNodeOfKey(s.selfNode, key) {
+			continue
+		}
+
+		s.storage.RLock(key)
+		keyEntries, err := s.storage.Get(key)
+		s.storage.RUnlock(key)
+		if err != nil {
+			return nil, err
+		}
+		entries[key] = keyEntries
+	}
+	return entries, nil
+}
+
+// Returns the Merkle tree of the entries shared with the peer in the given anti-entropy round of the peer
+// The tree is built by the first request of the round.
+func (s *DynamoServer) merkleTreeOfRound(round merkleRound) (*MerkleTree, error) {
+	if tree := s.merkleTrees.get(round); tree != nil {
+		return tree, nil
+	}
+
+	entries, err := s.entriesSharedWith(round.peer)
+	if err != nil {
+		return nil, err
+	}
+	tree := NewMerkleTree(entries)
+	s.merkleTrees.put(round, &tree)
+	return &tree, nil
+}
+
+// Returns the ID of a new anti-entropy round of this node
+func (c *merkleTreeCache) newRound() uint64 {
+	return atomic.AddUint64(&c.lastRound, 1)
+}
+
+// Returns the tree of the round, nil if the round has no tree or its tree has expired
+func (c *merkleTreeCache) get(round merkleRound) *MerkleTree {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	roundTree, ok := c.trees[round]
+	if !ok || time.Since(roundTree.createdAt) >= time.Duration(MERKLE_ROUND_TIMEOUT_MS)*time.Millisecond {
+		return nil
+	}
+	return roundTree.tree
+}
+
+// Sets the tree of the round, and removes the expired trees of the rounds the peers did not finish
+func (c *merkleTreeCache) put(round merkleRound, tree *MerkleTree) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for otherRound, roundTree := range c.trees {
+		if time.Since(roundTree.createdAt) >= time.Duration(MERKLE_ROUND_TIMEOUT_MS)*time.Millisecond {
+			delete(c.trees, otherRound)
+		}
+	}
+	c.trees[round] = &merkleRoundTree{tree: tree, createdAt: time.Now()}
+}
+
+// Removes the tree of the finished round
+func (c *merkleTreeCache) remove(round merkleRound) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.trees, round)
+}
+
+// Returns the hashes of the tree nodes with the given indices in the Merkle tree of the entries shared with the peer
+// This is an internal method used by other servers during anti-entropy (through RPC).
+func (s *DynamoServer) GetMerkleHashes(args MerkleHashesArgs, result *[]uint64) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+
+	tree, err := s.merkleTreeOfRound(merkleRound{peer: args.Peer, id: args.Round})
+	if err != nil {
+		return err
+	}
+
+	hashes := make([]uint64, 0, len(args.Indices))
+	for _, index := range args.Indices {
+		hash, err := tree.Hash(index)
+		if err != nil {
+			return err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	*result = hashes
+	return nil
+}
+
+// Returns the digests of the entries shared with the peer in the given buckets
+// This is an internal method used by other servers during anti-entropy (through RPC).
+func (s *DynamoServer) GetMerkleDigests(args MerkleDigestsArgs, result *map[string][]uint64) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+
+	round := merkleRound{peer: args.Peer, id: args.Round}
+	tree, err := s.merkleTreeOfRound(round)
+	if err != nil {
+		return err
+	}
+	s.merkleTrees.remove(round)
+
+	digests, err := tree.Digests(args.Buckets)
+	if err != nil {
+		return err
+	}
+
+	*result = digests
+	return nil
+}
+
+// Returns the local entries with the given digests
+// This is an internal method used by other servers during anti-entropy (through RPC).
+func (s *DynamoServer) GetMerkleEntries(args MerkleEntriesArgs, result *[]PutArgs) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+
+	putArgsList := make([]PutArgs, 0)
+	for key, digests := range args.Digests {
+		s.storage.RLock(key)
+		localEntries, err := s.storage.Get(key)
+		s.storage.RUnlock(key)
+		if err != nil {
+			return err
+		}
+
+		for _, localEntry := range localEntries {
+			if containsDigest(digests, entryDigest(localEntry)) {
+				putArgsList = append(putArgsList, NewPutArgsFromEntry(key, localEntry))
+			}
+		}
+	}
+
+	*result = putArgsList
+	return nil
+}
+
+// Forces server to run anti-entropy with all other servers
+// As this method takes no arguments, we must use the Empty placeholder.
+// Unlike `DynamoServer.Gossip`, the entries are synchronized in both directions: the servers compare the Merkle trees
+// of the entries they share, descend only into the differing subtrees, and exchange the entries missing on either side.
+func (s *DynamoServer) AntiEntropy(_ Empty, _ *Empty) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+
+	nodes, _ := s.topology()
+	for _, preferredDynamoNode := range nodes {
+		if preferredDynamoNode == s.selfNode {
+			continue
+		}
+
+		if err := s.syncWithMerkle(preferredDynamoNode, true, &s.stats.AntiEntropyBytes); err != nil {
+			log.Println(DYNAMO_SERVER, "Failed anti-entropy with", preferredDynamoNode, ":", err)
+		}
+	}
+	return nil
+}
+
+// Pulls the entries shared with the peer that are missing locally, and pushes the local entries missing on the peer if
+// push is true. The bytes exchanged with the peer are added to the counter.
+func (s *DynamoServer) syncWithMerkle(peer DynamoNode, push bool, bytesCounter *int64) error {
+	// Count the bytes of each request and response
+	call := func(method string, args interface{}, reply interface{}) error {
+		atomic.AddInt64(bytesCounter, messageSize(args))
+		if err := s.connections.Call(peer, method, args, reply); err != nil {
+			return err
+		}
+		atomic.AddInt64(bytesCounter, messageSize(reply))
+		return nil
+	}
+
+	entries, err := s.entriesSharedWith(peer)
+	if err != nil {
+		return err
+	}
+	tree := NewMerkleTree(entries)
+	round := s.merkleTrees.newRound()
+
+	// Descend level by level into the subtrees with differing hashes
+	differingBuckets := make([]int, 0)
+	indices := []int{1}
+	for len(indices) > 0 {
+		var remoteHashes []uint64
+		if err := call("MyDynamo.GetMerkleHashes", MerkleHashesArgs{Peer: s.selfNode, Round: round, Indices: indices}, &remoteHashes); err != nil {
+			return err
+		}
+		if len(remoteHashes) != len(indices) {
+			return fmt.Errorf("got %d Merkle tree hashes, expected %d", len(remoteHashes), len(indices))
+		}
+
+		nextIndices := make([]int, 0)
+		for i, index := range indices {
+			if localHash, _ := tree.Hash(index); localHash == remoteHashes[i] {
+				continue
+			}
+			if tree.IsLeaf(index) {
+				differingBuckets = append(differingBuckets, index-len(tree.hashes)/2)
+			} else {
+				nextIndices = append(nextIndices, 2*index, 2*index+1)
+			}
+		}
+		indices = nextIndices
+	}
+	if len(differingBuckets) == 0 {
+		return nil
+	}
+
+	var remoteDigests map[string][]uint64
+	if err := call("MyDynamo.GetMerkleDigests", MerkleDigestsArgs{Peer: s.selfNode, Round: round, Buckets: differingBuckets}, &remoteDigests); err != nil {
+		return err
+	}
+	localDigests, _ := tree.Digests(differingBuckets)
+
+	// Pull the remote entries missing locally
+	missingDigests := make(map[string][]uint64)
+	for key, digests := range remoteDigests {
+		for _, digest := range digests {
+			if !containsDigest(localDigests[key], digest) {
+				missingDigests[key] = append(missingDigests[key], digest)
+			}
+		}
+	}
+	if len(missingDigests) > 0 {
+		var putArgsList []PutArgs
+		if err := call("MyDynamo.GetMerkleEntries", MerkleEntriesArgs{Peer: s.selfNode, Digests: missingDigests}, &putArgsList); err != nil {
+			return err
+		}
+		for _, putArgs := range putArgsList {
+			if err := s.putLocalEntry(putArgs, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !push {
+		return nil
+	}
+
+	// Push the local entries missing remotely
+	for key := range localDigests {
+		for _, entry := range entries[key] {
+			if containsDigest(remoteDigests[key], entryDigest(entry)) {
+				continue
+			}
+
+			var result bool
+			if err := call("MyDynamo.PutRaw", NewPutArgsFromEntry(key, entry), &result); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the bytes sent and received by this server to replicate entries
+func (s *DynamoServer) GetReplicationStats(_ Empty, result *ReplicationStats) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+
+	*result = ReplicationStats{
+		GossipBytes:        atomic.LoadInt64(&s.stats.GossipBytes),
+		AntiEntropyBytes:   atomic.LoadInt64(&s.stats.AntiEntropyBytes),
+		ReadRepairs:        atomic.LoadInt64(&s.stats.ReadRepairs),
+		ReadRepairFailures: atomic.LoadInt64(&s.stats.ReadRepairFailures),
+	}
+	return nil
+}
+
+// Returns true if the specified list of digests contains the specified digest
+func containsDigest(digests []uint64, digest uint64) bool {
+	for _, v := range digests {
+		if v == digest {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the approximate size of a message exchanged to replicate entries, the size of the keys, values, contexts,
+// hashes and digests in it
+// The size is computed from the lengths of the fields instead of encoding the message, so counting the bytes costs
+// little more than sending the message.
+func messageSize(message interface{}) int64 {
+	switch message := message.(type) {
+	case MerkleHashesArgs:
+		return int64(8 * len(message.Indices))
+	case *[]uint64:
+		return int64(8 * len(*message))
+	case MerkleDigestsArgs:
+		return int64(8 * len(message.Buckets))
+	case MerkleEntriesArgs:
+		return digestsSize(message.Digests)
+	case *map[string][]uint64:
+		return digestsSize(*message)
+	case PutArgs:
+		return putArgsSize(message)
+	case *[]PutArgs:
+		size := int64(0)
+		for _, putArgs := range *message {
+			size += putArgsSize(putArgs)
+		}
+		return size
+	case *bool:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Returns the size of the keys and the digests
+func digestsSize(digests map[string][]uint64) int64 {
+	size := int64(0)
+	for key, keyDigests := range digests {
+		size += int64(len(key) + 8*len(keyDigests))
+	}
+	return size
+}
+
+// Returns the size of the key, the context and the value of the PutArgs
+func putArgsSize(putArgs PutArgs) int64 {
+	size := int64(len(putArgs.Key) + len(putArgs.Value) + len(putArgs.Context.Dot.NodeID) + 8)
+	for nodeID := range putArgs.Context.Clock.NodeClocks {
+		size += int64(len(nodeID) + 8)
+	}
+	return size
+}
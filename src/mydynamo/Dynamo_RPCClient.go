@@ -1,10 +1,14 @@
 package mydynamo
 
 import (
+	"errors"
 	"log"
 	"net/rpc"
 )
 
+//Error of the calls of a client that is not connected
+var ErrRPCClientNotConnected = errors.New("rpc client is not connected")
+
 type RPCClient struct {
 	ServerAddr string
 	rpcConn    *rpc.Client
@@ -72,6 +76,48 @@ func (dynamoClient *RPCClient) Put(value PutArgs) bool {
 	return result
 }
 
+//Puts a value to the server, and returns the detailed result of the Put.
+func (dynamoClient *RPCClient) PutWithResult(value PutArgs) *PutResult {
+	result, err := dynamoClient.TryPutWithResult(value)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	return result
+}
+
+//Puts a value to the server, and returns the detailed result of the Put or the error of the call.
+func (dynamoClient *RPCClient) TryPutWithResult(value PutArgs) (*PutResult, error) {
+	if dynamoClient.rpcConn == nil {
+		return nil, ErrRPCClientNotConnected
+	}
+	var result PutResult
+	if err := dynamoClient.rpcConn.Call("MyDynamo.PutWithResult", value, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+//Deletes a key from the server, by putting a tombstone superseding the values older than the context
+func (dynamoClient *RPCClient) Delete(value DeleteArgs) bool {
+	result, err := dynamoClient.TryDelete(value)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	return result
+}
+
+//Deletes a key from the server like Delete, and returns the result of the Delete or the error of the call.
+func (dynamoClient *RPCClient) TryDelete(value DeleteArgs) (bool, error) {
+	if dynamoClient.rpcConn == nil {
+		return false, ErrRPCClientNotConnected
+	}
+	var result bool
+	err := dynamoClient.rpcConn.Call("MyDynamo.Delete", value, &result)
+	return result, err
+}
+
 //Puts a value to the server without incrementing clock and replicating to other servers.
 func (dynamoClient *RPCClient) PutRaw(value PutArgs) bool {
 	var result bool
@@ -86,18 +132,70 @@ func (dynamoClient *RPCClient) PutRaw(value PutArgs) bool {
 	return result
 }
 
-//Gets a value from a server.
-func (dynamoClient *RPCClient) Get(key string) *DynamoResult {
-	var result DynamoResult
+//Puts a hint for another node to the server.
+func (dynamoClient *RPCClient) PutHint(hint Hint) bool {
+	var result bool
+	if dynamoClient.rpcConn == nil {
+		return false
+	}
+	err := dynamoClient.rpcConn.Call("MyDynamo.PutHint", hint, &result)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	return result
+}
+
+//Gets the hints kept by a server.
+func (dynamoClient *RPCClient) GetHints() []Hint {
+	var result []Hint
 	if dynamoClient.rpcConn == nil {
 		return nil
 	}
-	err := dynamoClient.rpcConn.Call("MyDynamo.Get", key, &result)
+	var v Empty
+	err := dynamoClient.rpcConn.Call("MyDynamo.GetHints", v, &result)
 	if err != nil {
 		log.Println(err)
 		return nil
 	}
-	return &result
+	return result
+}
+
+//Instructs the server this client is connected to hand off its hints, returns the number of hints handed off
+func (dynamoClient *RPCClient) DeliverHints() int {
+	if dynamoClient.rpcConn == nil {
+		return 0
+	}
+	var v Empty
+	var result int
+	err := dynamoClient.rpcConn.Call("MyDynamo.DeliverHints", v, &result)
+	if err != nil {
+		log.Println(err)
+		return 0
+	}
+	return result
+}
+
+//Gets a value from a server.
+func (dynamoClient *RPCClient) Get(key string) *DynamoResult {
+	result, err := dynamoClient.TryGet(key)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	return result
+}
+
+//Gets a value from a server, and returns the result or the error of the call.
+func (dynamoClient *RPCClient) TryGet(key string) (*DynamoResult, error) {
+	if dynamoClient.rpcConn == nil {
+		return nil, ErrRPCClientNotConnected
+	}
+	var result DynamoResult
+	if err := dynamoClient.rpcConn.Call("MyDynamo.Get", key, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
 }
 
 //Gets a value from a server.
@@ -115,11 +213,7 @@ func (dynamoClient *RPCClient) GetRaw(key string, result *DynamoResult) bool {
 
 //Emulates a crash on the server this client is connected to
 func (dynamoClient *RPCClient) Crash(seconds int) bool {
-	if dynamoClient.rpcConn == nil {
-		return false
-	}
-	var success bool
-	err := dynamoClient.rpcConn.Call("MyDynamo.Crash", seconds, &success)
+	success, err := dynamoClient.TryCrash(seconds)
 	if err != nil {
 		log.Println(err)
 		return false
@@ -127,47 +221,192 @@ func (dynamoClient *RPCClient) Crash(seconds int) bool {
 	return success
 }
 
+//Emulates a crash on the server this client is connected to, and returns the result or the error of the call
+func (dynamoClient *RPCClient) TryCrash(seconds int) (bool, error) {
+	if dynamoClient.rpcConn == nil {
+		return false, ErrRPCClientNotConnected
+	}
+	var success bool
+	err := dynamoClient.rpcConn.Call("MyDynamo.Crash", seconds, &success)
+	return success, err
+}
+
 //Emulates a crash on the server this client is connected to
 func (dynamoClient *RPCClient) ForceCrash() {
+	if err := dynamoClient.TryForceCrash(); err != nil {
+		log.Println(err)
+	}
+}
+
+//Emulates a crash on the server this client is connected to, and returns the error of the call
+func (dynamoClient *RPCClient) TryForceCrash() error {
+	if dynamoClient.rpcConn == nil {
+		return ErrRPCClientNotConnected
+	}
+	var v Empty
+	return dynamoClient.rpcConn.Call("MyDynamo.ForceCrash", v, &v)
+}
+
+//Make the server restore from the emulated crash state
+func (dynamoClient *RPCClient) ForceRestore() {
+	if err := dynamoClient.TryForceRestore(); err != nil {
+		log.Println(err)
+	}
+}
+
+//Make the server restore from the emulated crash state, and returns the error of the call
+func (dynamoClient *RPCClient) TryForceRestore() error {
+	if dynamoClient.rpcConn == nil {
+		return ErrRPCClientNotConnected
+	}
+	var v Empty
+	return dynamoClient.rpcConn.Call("MyDynamo.ForceRestore", v, &v)
+}
+
+//Makes the server this client is connected to respond to each request after the given milliseconds
+func (dynamoClient *RPCClient) ForceDelay(milliseconds int) {
 	if dynamoClient.rpcConn == nil {
 		return
 	}
 
 	var v Empty
-	err := dynamoClient.rpcConn.Call("MyDynamo.ForceCrash", v, &v)
+	err := dynamoClient.rpcConn.Call("MyDynamo.ForceDelay", milliseconds, &v)
 	if err != nil {
 		log.Println(err)
 		return
 	}
 }
 
-//Make the server restore from the emulated crash state
-func (dynamoClient *RPCClient) ForceRestore() {
+//Returns the counters of the connections made by the server this client is connected to
+func (dynamoClient *RPCClient) GetConnectionPoolStats() *ConnectionPoolStats {
 	if dynamoClient.rpcConn == nil {
-		return
+		return nil
+	}
+	var v Empty
+	var result ConnectionPoolStats
+	err := dynamoClient.rpcConn.Call("MyDynamo.GetConnectionPoolStats", v, &result)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	return &result
+}
+
+//Returns the membership view of the server this client is connected to
+func (dynamoClient *RPCClient) GetMembership() []Member {
+	if dynamoClient.rpcConn == nil {
+		return nil
 	}
+	var v Empty
+	var result []Member
+	err := dynamoClient.rpcConn.Call("MyDynamo.GetMembership", v, &result)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	return result
+}
 
+//Instructs the server this client is connected to join the cluster of the seed server
+func (dynamoClient *RPCClient) Join(seed DynamoNode) bool {
+	if dynamoClient.rpcConn == nil {
+		return false
+	}
 	var v Empty
-	err := dynamoClient.rpcConn.Call("MyDynamo.ForceRestore", v, &v)
+	err := dynamoClient.rpcConn.Call("MyDynamo.Join", seed, &v)
 	if err != nil {
 		log.Println(err)
-		return
+		return false
 	}
+	return true
+}
+
+//Instructs the server this client is connected to join the cluster of the first seed server it can join
+func (dynamoClient *RPCClient) JoinSeeds(seeds []DynamoNode) bool {
+	if dynamoClient.rpcConn == nil {
+		return false
+	}
+	var v Empty
+	err := dynamoClient.rpcConn.Call("MyDynamo.JoinSeeds", seeds, &v)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	return true
+}
+
+//Instructs the server this client is connected to leave its cluster and shut down
+func (dynamoClient *RPCClient) Decommission() bool {
+	if dynamoClient.rpcConn == nil {
+		return false
+	}
+	var v Empty
+	err := dynamoClient.rpcConn.Call("MyDynamo.Decommission", v, &v)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	return true
 }
 
 //Instructs the server this client is connected to gossip
 func (dynamoClient *RPCClient) Gossip() {
+	if err := dynamoClient.TryGossip(); err != nil {
+		log.Println(err)
+	}
+}
+
+//Instructs the server this client is connected to gossip, and returns the error of the call
+func (dynamoClient *RPCClient) TryGossip() error {
+	if dynamoClient.rpcConn == nil {
+		return ErrRPCClientNotConnected
+	}
+	var v Empty
+	return dynamoClient.rpcConn.Call("MyDynamo.Gossip", v, &v)
+}
+
+//Instructs the server this client is connected to run anti-entropy with other servers
+func (dynamoClient *RPCClient) AntiEntropy() {
 	if dynamoClient.rpcConn == nil {
 		return
 	}
 	var v Empty
-	err := dynamoClient.rpcConn.Call("MyDynamo.Gossip", v, &v)
+	err := dynamoClient.rpcConn.Call("MyDynamo.AntiEntropy", v, &v)
 	if err != nil {
 		log.Println(err)
 		return
 	}
 }
 
+//Instructs the server this client is connected to garbage collect its tombstones, and returns the number collected
+func (dynamoClient *RPCClient) CollectTombstones() int {
+	var count int
+	if dynamoClient.rpcConn == nil {
+		return 0
+	}
+	err := dynamoClient.rpcConn.Call("MyDynamo.CollectTombstones", Empty{}, &count)
+	if err != nil {
+		log.Println(err)
+		return 0
+	}
+	return count
+}
+
+//Gets the bytes sent and received by a server to replicate entries
+func (dynamoClient *RPCClient) GetReplicationStats() *ReplicationStats {
+	if dynamoClient.rpcConn == nil {
+		return nil
+	}
+	var v Empty
+	var result ReplicationStats
+	err := dynamoClient.rpcConn.Call("MyDynamo.GetReplicationStats", v, &result)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	return &result
+}
+
 //Creates a new DynamoRPCClient
 func NewDynamoRPCClient(serverAddr string) *RPCClient {
 	return &RPCClient{
@@ -177,11 +416,12 @@ func NewDynamoRPCClient(serverAddr string) *RPCClient {
 }
 
 //Creates a new DynamoRPCClient from DynamoNode (address and port) and establishes the RPC connection
+//If the node is unreachable, the client is returned without a connection, so its calls fail.
 func NewDynamoRPCClientFromDynamoNodeAndConnect(node DynamoNode) *RPCClient {
 	client := NewDynamoRPCClient(node.Address + ":" + node.Port)
 
 	if err := client.CleanAndConn(); err != nil {
-		panic(err)
+		log.Println(err)
 	}
 	return client
 }
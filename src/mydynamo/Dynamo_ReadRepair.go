@@ -0,0 +1,75 @@
+package mydynamo
+
+import (
+	"log"
+	"sync/atomic"
+)
+
+// Writes the merged entries of the key back to the contacted replicas missing them, in the read repair mode of the
+// server. In "async" mode the replicas are repaired in the background, so Get does not wait for them.
+func (s *DynamoServer) readRepairReplicas(key string, entries []ObjectEntry, replicaEntries map[DynamoNode][]ObjectEntry) {
+	if s.readRepair == READ_REPAIR_OFF || s.readRepair == "" {
+		return
+	}
+
+	missingEntries := make(map[DynamoNode][]ObjectEntry)
+	for node, nodeEntries := range replicaEntries {
+		for _, entry := range entries {
+			if !containsEntryClock(nodeEntries, entry) {
+				missingEntries[node] = append(missingEntries[node], entry)
+			}
+		}
+	}
+	if len(missingEntries) == 0 {
+		return
+	}
+
+	if s.readRepair == READ_REPAIR_SYNC {
+		s.repairReplicas(key, missingEntries)
+		return
+	}
+
+	// Storage must stay open until the repair is done, so the repair is waited for on shutdown. Once the server is
+	// shutting down, the replicas are left to be repaired by gossip or anti-entropy.
+	if !s.startTask(func() { s.repairReplicas(key, missingEntries) }) {
+		log.Println(DYNAMO_SERVER, "Skipping read repair of", key, "during shutdown")
+	}
+}
+
+// Writes the given entries of the key to the replicas, and counts the entries written and failed
+func (s *DynamoServer) repairReplicas(key string, missingEntries map[DynamoNode][]ObjectEntry) {
+	for node, entries := range missingEntries {
+		if node == s.selfNode {
+			for _, entry := range entries {
+				if err := s.putLocalEntry(NewPutArgsFromEntry(key, entry), true); err != nil {
+					log.Println(DYNAMO_SERVER, "Failed to repair", key, "locally:", err)
+					atomic.AddInt64(&s.stats.ReadRepairFailures, 1)
+				} else {
+					atomic.AddInt64(&s.stats.ReadRepairs, 1)
+				}
+			}
+			continue
+		}
+
+		for _, entry := range entries {
+			var result bool
+			err := s.connections.Call(node, "MyDynamo.PutRaw", NewPutArgsFromEntry(key, entry), &result)
+			if err == nil && result {
+				atomic.AddInt64(&s.stats.ReadRepairs, 1)
+			} else {
+				log.Println(DYNAMO_SERVER, "Failed to repair", key, "on", node, ":", err)
+				atomic.AddInt64(&s.stats.ReadRepairFailures, 1)
+			}
+		}
+	}
+}
+
+// Returns true if the specified list of entries contains an entry with the same context as the specified entry
+func containsEntryClock(entries []ObjectEntry, entry ObjectEntry) bool {
+	for _, e := range entries {
+		if e.Context.Equals(entry.Context) {
+			return true
+		}
+	}
+	return false
+}
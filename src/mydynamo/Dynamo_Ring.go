@@ -0,0 +1,112 @@
+package mydynamo
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"sort"
+	"strconv"
+)
+
+// Position of a virtual node on the hash ring
+type ringToken struct {
+	hash uint64
+	node DynamoNode
+}
+
+// Consistent hashing ring placing keys on the Dynamo nodes
+// Each physical node owns `virtualNodes` tokens on the ring. The preference list of a key is built by walking the
+// ring clockwise from the position of the key and collecting the distinct physical nodes owning the tokens passed.
+// Nodes and keys are placed by the first 8 bytes of their MD5 hash, so every node builds the same ring from the same
+// set of nodes regardless of their order.
+type HashRing struct {
+	tokens    []ringToken //Tokens sorted by their hashes
+	nodeCount int         //Number of distinct physical nodes on the ring
+}
+
+// Creates a new HashRing with the given number of virtual nodes for each node
+func NewHashRing(nodes []DynamoNode, virtualNodes int) HashRing {
+	distinctNodes := make(map[DynamoNode]bool)
+	tokens := make([]ringToken, 0, len(nodes)*virtualNodes)
+	for _, node := range nodes {
+		if distinctNodes[node] {
+			continue
+		}
+		distinctNodes[node] = true
+
+		for i := 0; i < virtualNodes; i++ {
+			tokens = append(tokens, ringToken{
+				hash: ringHash(node.Address + ":" + node.Port + "#" + strconv.Itoa(i)),
+				node: node,
+			})
+		}
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].hash != tokens[j].hash {
+			return tokens[i].hash < tokens[j].hash
+		}
+		// Break ties by the node so that the order does not depend on the order of the given nodes
+		if tokens[i].node.Address != tokens[j].node.Address {
+			return tokens[i].node.Address < tokens[j].node.Address
+		}
+		return tokens[i].node.Port < tokens[j].node.Port
+	})
+
+	return HashRing{
+		tokens:    tokens,
+		nodeCount: len(distinctNodes),
+	}
+}
+
+// Returns the position of the given string on the ring
+func ringHash(s string) uint64 {
+	sum := md5.Sum([]byte(s))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// Returns the preference list of the given key: the first n distinct nodes met when walking the ring clockwise from
+// the position of the key. All nodes on the ring are returned if n is not positive or exceeds the number of nodes.
+func (r *HashRing) PreferenceList(key string, n int) []DynamoNode {
+	if n <= 0 || n > r.nodeCount {
+		n = r.nodeCount
+	}
+
+	preferenceList := make([]DynamoNode, 0, n)
+	if n == 0 {
+		return preferenceList
+	}
+
+	keyHash := ringHash(key)
+	start := sort.Search(len(r.tokens), func(i int) bool {
+		return r.tokens[i].hash >= keyHash
+	})
+
+	seenNodes := make(map[DynamoNode]bool, n)
+	for i := 0; i < len(r.tokens) && len(preferenceList) < n; i++ {
+		node := r.tokens[(start+i)%len(r.tokens)].node
+		if !seenNodes[node] {
+			seenNodes[node] = true
+			preferenceList = append(preferenceList, node)
+		}
+	}
+	return preferenceList
+}
+
+// Returns the nodes in the order a key not placed by a ring is replicated along
+// The nodes are sorted by address, and the list starts at the node chosen by the hash of the key, so every node
+// agrees on the top N nodes of the key whatever the order of its own preference list.
+func keyOrderedNodes(key string, nodes []DynamoNode) []DynamoNode {
+	sorted := make([]DynamoNode, len(nodes))
+	copy(sorted, nodes)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Address != sorted[j].Address {
+			return sorted[i].Address < sorted[j].Address
+		}
+		return sorted[i].Port < sorted[j].Port
+	})
+
+	start := int(ringHash(key) % uint64(len(sorted)))
+	ordered := make([]DynamoNode, 0, len(sorted))
+	ordered = append(ordered, sorted[start:]...)
+	return append(ordered, sorted[:start]...)
+}
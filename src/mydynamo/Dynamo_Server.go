@@ -1,101 +1,299 @@
 package mydynamo
 
 import (
+	"context"
 	"errors"
 	"log"
 	"net"
 	"net/http"
 	"net/rpc"
+	"os"
+	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"google.golang.org/grpc"
 )
 
 type DynamoServer struct {
 	/*------------Dynamo-specific-------------*/
 	wValue           int                  //Number of nodes to write to on each Put
 	rValue           int                  //Number of nodes to read from on each Get
+	nValue           int                  //Number of nodes storing each key, all nodes in preferenceList if 0
 	preferenceList   []DynamoNode         //Ordered list of other Dynamo nodes to perform operations o
 	selfNode         DynamoNode           //This node's address and port info
 	nodeID           string               //ID of this node
-	localEntriesMap  ObjectEntriesMap     //Map for string key to local object entries
+	storage          StorageEngine        //Storage for string key to local object entries
+	storageEngine    string               //Name of the storage engine
 	nodePutRecords   DynamoNodePutRecords //For querying if a node in preferenceList has a PutRecord
 	isCrashed        bool                 //Whether this server is crashed or not
-	isCrashedRWMutex *sync.RWMutex        //RWMutex for the variable `DynamoServer.isCrashed`
+	isCrashedRWMutex *sync.RWMutex        //RWMutex for the variables `DynamoServer.isCrashed` and `DynamoServer.delay`
+	delay            time.Duration        //Injected delay of each request to this server, for testing slow servers
+	dataDir          string               //Directory to persist this node's data, persistence is disabled if empty
+	wal              *WriteAheadLog       //Log of accepted PutArgs, nil if persistence is disabled
+	persistRWMutex   *sync.RWMutex        //Held for reading while persisting a PutArgs, and for writing to take snapshots
+	snapshotMutex    *sync.Mutex          //Serializes snapshots
+	snapshotInterval time.Duration        //Interval between two snapshots, snapshots are disabled if 0
+	snapshotsToKeep  int                  //Number of most recent snapshots kept on the disk
+	virtualNodes     int                  //Number of virtual nodes of each node on the hash ring
+	ring             *HashRing            //Hash ring placing keys on nodes, nil if keys are not placed by a ring
+	topologyRWMutex  *sync.RWMutex        //RWMutex for the variables `DynamoServer.preferenceList` and `DynamoServer.ring`
+	joinMutex        *sync.Mutex          //Serializes the nodes joining the cluster through this node
+	sloppyQuorum     bool                 //Whether to put writes for unavailable nodes to the next nodes as hints
+	hints            HintStore            //Hints kept for other nodes
+	handoffInterval  time.Duration        //Interval between two attempts to hand off hints, disabled if 0
+	stats            *ReplicationStats    //Bytes sent and received to replicate entries, updated atomically
+	merkleTrees      *merkleTreeCache     //Merkle trees of the anti-entropy rounds of the peers, shared by the copies
+	gossipInterval   time.Duration        //Interval between two rounds of background gossip, disabled if 0
+	gossipJitter     time.Duration        //Maximum random duration added to each gossip interval
+	gossipFanout     int                  //Number of random peers to gossip with in each round, all peers if 0
+	gossipMode       string               //Direction entries are replicated by gossip, "push", "pull" or "push-pull"
+	readRepair       string               //When Get writes the merged entries back to stale replicas
+	requestTimeout   time.Duration        //Time to wait for each replica before trying the next one, no limit if 0
+	connections      *ConnectionPool      //Pooled connections to other nodes, shared by the copies of the server
+	poolHealthCheck  time.Duration        //Interval between two health checks of pooled connections, disabled if 0
+	membership       *Membership          //Liveness of the nodes, shared by the copies of the server
+	beatInterval     time.Duration        //Interval between two heartbeats, membership gossip is disabled if 0
+	tombstoneGrace   time.Duration        //Time a tombstone is kept for at least before it can be garbage collected
+	tombstoneGC      time.Duration        //Interval between two tombstone garbage collections, disabled if 0
+	maxClockEntries  int                  //Maximum number of entries of the vector clock of a put value, unbounded if 0
+	causality        string               //Causality mechanism of the put values, vector clocks or dotted version vectors
+	transport        string               //Transports served by this node, "rpc", "grpc" or "both"
+	grpcPortOffset   int                  //Offset added to the port of this node to get its gRPC port, if both are served
+	lifecycle        *serverLifecycle     //State to shut down the server, shared by the copies of the server
+}
+
+// State to shut down a served DynamoServer
+type serverLifecycle struct {
+	mutex    sync.Mutex
+	done     chan struct{}  //Closed when the server is shut down
+	listener *connListener  //Listener of the server, nil if it is not listening yet
+	grpc     *grpc.Server   //gRPC server of the server, nil if it is not serving gRPC yet
+	loops    sync.WaitGroup //Background loops and tasks of the server
+}
+
+// Listener keeping track of the accepted connections, so they are closed together with the listener
+type connListener struct {
+	net.Listener
+	mutex sync.Mutex
+	conns map[net.Conn]bool
+}
+
+// Accepted connection that is forgotten by its listener once closed
+type listenerConn struct {
+	net.Conn
+	listener *connListener
+}
+
+// Accepts the next connection and keeps track of it
+func (l *connListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.conns[conn] = true
+	return listenerConn{Conn: conn, listener: l}, nil
+}
+
+// Closes the listener and the accepted connections
+func (l *connListener) Close() error {
+	err := l.Listener.Close()
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for conn := range l.conns {
+		_ = conn.Close()
+	}
+	l.conns = make(map[net.Conn]bool)
+	return err
+}
+
+// Closes the connection and removes it from its listener
+func (c listenerConn) Close() error {
+	c.listener.mutex.Lock()
+	delete(c.listener.conns, c.Conn)
+	c.listener.mutex.Unlock()
+	return c.Conn.Close()
 }
 
 // Returns error if the server is in crash state, otherwise nil
+// Waits for the injected delay first, if any.
 func (s *DynamoServer) checkCrashed() error {
 	s.isCrashedRWMutex.RLock()
-	defer s.isCrashedRWMutex.RUnlock()
+	isCrashed, delay := s.isCrashed, s.delay
+	s.isCrashedRWMutex.RUnlock()
 
-	if s.isCrashed {
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	if isCrashed {
 		return errors.New("Server is crashed")
 	}
 
 	return nil
 }
 
+// Sets the list of nodes in the cluster, starting with this node
+// If virtual nodes are configured, the hash ring is rebuilt from the nodes. The nodes not known by the membership yet
+// are added to it as alive, and the nodes no longer in the list are removed from it.
 func (s *DynamoServer) SendPreferenceList(incomingList []DynamoNode, _ *Empty) error {
 	if err := s.checkCrashed(); err != nil {
 		return err
 	}
 
-	s.preferenceList = incomingList
+	s.setTopology(incomingList)
+	s.membership.SetNodes(incomingList)
 	return nil
 }
 
+// Sets the list of nodes in the cluster, and rebuilds the hash ring from the nodes if virtual nodes are configured
+func (s *DynamoServer) setTopology(nodes []DynamoNode) {
+	var ring *HashRing
+	if s.virtualNodes > 0 {
+		newRing := NewHashRing(nodes, s.virtualNodes)
+		ring = &newRing
+	}
+
+	s.topologyRWMutex.Lock()
+	defer s.topologyRWMutex.Unlock()
+	s.preferenceList = nodes
+	s.ring = ring
+}
+
+// Returns the list of nodes in the cluster and the hash ring, nil if keys are not placed by a ring
+func (s *DynamoServer) topology() ([]DynamoNode, *HashRing) {
+	s.topologyRWMutex.RLock()
+	defer s.topologyRWMutex.RUnlock()
+	return s.preferenceList, s.ring
+}
+
+// Returns the ordered list of the top N nodes to perform operations on the given key
+// With a hash ring, each key has its own list of distinct nodes found by walking the ring from the key's position.
+// Otherwise, every key uses this node's preference list, or the top N nodes of the key found by `keyOrderedNodes` if
+// N is less than the number of nodes, as the preference lists of the nodes are in different orders. Before the
+// preference list is sent, this node is the only node it knows.
+func (s *DynamoServer) preferenceListOfKey(key string) []DynamoNode {
+	preferenceList, ring := s.topology()
+	if ring != nil {
+		return ring.PreferenceList(key, s.nValue)
+	}
+	if len(preferenceList) == 0 {
+		return []DynamoNode{s.selfNode}
+	}
+	if s.nValue > 0 && s.nValue < len(preferenceList) {
+		return keyOrderedNodes(key, preferenceList)[:s.nValue]
+	}
+	return preferenceList
+}
+
+// Returns the nodes after the top N nodes of the given key, in the order they take over writes for unavailable nodes
+func (s *DynamoServer) fallbackNodesOfKey(key string) []DynamoNode {
+	nodes, ring := s.topology()
+	if ring != nil {
+		nodes = ring.PreferenceList(key, 0)
+	} else if len(nodes) > 0 {
+		nodes = keyOrderedNodes(key, nodes)
+	}
+
+	topCount := len(s.preferenceListOfKey(key))
+	if topCount >= len(nodes) {
+		return nil
+	}
+	return nodes[topCount:]
+}
+
+// Returns true if the node is in the preference list of the given key
+func (s *DynamoServer) isPreferredNodeOfKey(node DynamoNode, key string) bool {
+	return containsDynamoNode(s.preferenceListOfKey(key), node)
+}
+
 // Forces server to gossip
 // As this method takes no arguments, we must use the Empty placeholder.
-// Replicates all keys and values from the current server to all other servers.
+// Replicates all keys and values from the current server to the other servers in the preference lists of the keys.
+// In "pull" mode, the entries missing on the current server are replicated from the other servers instead, and in
+// "push-pull" mode entries are replicated in both directions.
 func (s *DynamoServer) Gossip(_ Empty, _ *Empty) error {
 	if err := s.checkCrashed(); err != nil {
 		return err
 	}
 
-	entryKeys := s.localEntriesMap.GetKeys()
+	nodes, _ := s.topology()
+	s.gossipTo(nodes)
+	return nil
+}
+
+// Gossips with the given servers in the gossip mode of the current server
+func (s *DynamoServer) gossipTo(peers []DynamoNode) {
+	entryKeys := s.storage.GetKeys()
 
-	for _, preferredDynamoNode := range s.preferenceList {
-		rpcClient := NewDynamoRPCClientFromDynamoNodeAndConnect(preferredDynamoNode)
-		defer rpcClient.CleanConn()
+	for _, preferredDynamoNode := range peers {
+		if preferredDynamoNode == s.selfNode || s.membership.Status(preferredDynamoNode) == MEMBER_DEAD {
+			continue
+		}
 
-		for _, key := range entryKeys {
-			if preferredDynamoNode == s.selfNode {
-				continue
+		if s.gossipMode != GOSSIP_MODE_PULL {
+			s.pushTo(preferredDynamoNode, entryKeys)
+		}
+		if s.gossipMode != GOSSIP_MODE_PUSH {
+			if err := s.syncWithMerkle(preferredDynamoNode, false, &s.stats.GossipBytes); err != nil {
+				log.Println(DYNAMO_SERVER, "Failed to pull entries from", preferredDynamoNode, ":", err)
 			}
+		}
+	}
+}
+
+// Replicates the values of the given keys from the current server to the given server if it is in the preference
+// lists of the keys
+func (s *DynamoServer) pushTo(preferredDynamoNode DynamoNode, entryKeys []string) {
+	putter := s.connections.openRawPutter(preferredDynamoNode, s.requestTimeout)
+	defer putter.close()
 
-			s.localEntriesMap.RLock(key)
-			localEntries := s.localEntriesMap.Get(key)
-			s.localEntriesMap.RUnlock(key)
+	for _, key := range entryKeys {
+		if !s.isPreferredNodeOfKey(preferredDynamoNode, key) {
+			continue
+		}
+
+		s.storage.RLock(key)
+		localEntries, err := s.storage.Get(key)
+		s.storage.RUnlock(key)
+		if err != nil {
+			log.Println(DYNAMO_SERVER, "Failed to get local entries of key", key, ":", err)
+			continue
+		}
 
-			putRecords := make([]PutRecord, 0)
+		putRecords := make([]PutRecord, 0)
 
-			for _, localEntry := range localEntries {
-				putRecord := NewPutRecord(key, localEntry.Context)
+		for _, localEntry := range localEntries {
+			putRecord := NewPutRecord(key, localEntry.Context)
 
-				if !s.nodePutRecords.CheckPutRecordInNode(putRecord, preferredDynamoNode) {
-					putArgs := PutArgs{
-						Key:     key,
-						Context: localEntry.Context,
-						Value:   localEntry.Value,
-					}
-					if rpcClient.PutRaw(putArgs) {
-						putRecords = append(putRecords, putRecord)
-					}
+			if !s.nodePutRecords.CheckPutRecordInNode(putRecord, preferredDynamoNode) {
+				putArgs := NewPutArgsFromEntry(key, localEntry)
+				atomic.AddInt64(&s.stats.GossipBytes, messageSize(putArgs))
+				result, err := putter.putRaw(putArgs)
+				if isUnreachableError(err) {
+					log.Println(DYNAMO_SERVER, "Failed to gossip to", preferredDynamoNode, ":", err)
+					return
+				}
+				if err == nil && result {
+					putRecords = append(putRecords, putRecord)
 				}
 			}
+		}
 
-			s.nodePutRecords.ExecAtomic(func() {
-				for _, putRecord := range putRecords {
-					if s.nodePutRecords.CheckPutRecordInNode(putRecord, s.selfNode) {
-						// If this server still has this entry (PutRecord),
-						// then add new PutRecords associated with the server (DynamoNode) that successfully put previously
-						s.nodePutRecords.AddPutRecordToDynamoNode(putRecord, preferredDynamoNode)
-					}
+		s.nodePutRecords.ExecAtomic(func() {
+			for _, putRecord := range putRecords {
+				if s.nodePutRecords.CheckPutRecordInNode(putRecord, s.selfNode) {
+					// If this server still has this entry (PutRecord),
+					// then add new PutRecords associated with the server (DynamoNode) that successfully put previously
+					s.nodePutRecords.AddPutRecordToDynamoNode(putRecord, preferredDynamoNode)
 				}
-			})
-		}
+			}
+		})
 	}
-	return nil
 }
 
 // Makes server unavailable for some seconds
@@ -140,41 +338,86 @@ func (s *DynamoServer) ForceRestore(_ Empty, _ *Empty) error {
 	return nil
 }
 
+// Makes server respond to each request after the given milliseconds, or without delay if 0
+// This emulates a slow server in tests.
+func (s *DynamoServer) ForceDelay(milliseconds int, _ *Empty) error {
+	s.isCrashedRWMutex.Lock()
+	defer s.isCrashedRWMutex.Unlock()
+
+	s.delay = time.Duration(milliseconds) * time.Millisecond
+	return nil
+}
+
 // Put a file to this server and W other servers
 // Put will replicate the files to the first W nodes of its preference list. (spec)
+// With a hash ring, the preference list of the key is used. If this server is not in the top N nodes of the key,
+// the Put is forwarded to the first available node that is.
+// With sloppy quorum, the writes for unavailable nodes are put to the nodes after the top N nodes as hints, which
+// count towards W and are handed off to the intended nodes once they are available again.
 // If enough nodes are crashed that there are not W available nodes, Put will simply attempt to Put
 // to as many nodes as possible. (spec)
+// The other nodes are put to concurrently, see `DynamoServer.fanOut`.
 // Returns an error if there is an internal error.
 // The parameter `result *bool` is set to true when successfully Put to W other servers, otherwise set to false.
 // Reference:
 // - https://piazza.com/class/kfqynl4r6a0317?cid=906
 func (s *DynamoServer) Put(putArgs PutArgs, result *bool) error {
+	var putResult PutResult
+	err := s.PutWithResult(putArgs, &putResult)
+	*result = putResult.Success
+	return err
+}
+
+// Put a file to this server and W other servers like `DynamoServer.Put`
+// The parameter `result *PutResult` is set to whether the Put succeeded, the number of nodes the file was put to, and
+// the failures of the other nodes, including the nodes that could not be reached.
+func (s *DynamoServer) PutWithResult(putArgs PutArgs, result *PutResult) error {
+	return s.putWithResult(context.Background(), putArgs, result)
+}
+
+// Put a file like `DynamoServer.PutWithResult`, with the requests to the other nodes cancelled once ctx is done
+func (s *DynamoServer) putWithResult(ctx context.Context, putArgs PutArgs, result *PutResult) error {
 	if err := s.checkCrashed(); err != nil {
 		return err
 	}
 
-	putArgs.Context.Clock.Increment(s.nodeID)
-	if err := s.PutRaw(putArgs, result); err != nil {
-		*result = false
-		return err
+	preferenceList := s.preferenceListOfKey(putArgs.Key)
+	if !containsDynamoNode(preferenceList, s.selfNode) {
+		return s.forwardPut(ctx, preferenceList, putArgs, result)
 	}
 
-	wCount := 1
-	successfullyPutNodes := make([]DynamoNode, 0)
-	for _, preferredDynamoNode := range s.preferenceList {
-		if wCount >= s.wValue {
-			break
+	if s.causality == CAUSALITY_DOTTED_VERSION_VECTOR {
+		var err error
+		if putArgs, err = s.putRawDotted(putArgs); err != nil {
+			*result = PutResult{Success: false}
+			return err
 		}
-		if preferredDynamoNode == s.selfNode {
-			continue
+	} else {
+		putArgs.Context.Clock.Increment(s.nodeID)
+		if s.maxClockEntries > 0 {
+			putArgs.Context.Clock.Prune(s.maxClockEntries)
 		}
+		var success bool
+		if err := s.PutRaw(putArgs, &success); err != nil {
+			*result = PutResult{Success: false}
+			return err
+		}
+	}
 
-		rpcClient := NewDynamoRPCClientFromDynamoNodeAndConnect(preferredDynamoNode)
-		defer rpcClient.CleanConn()
-		if rpcClient.PutRaw(putArgs) {
-			successfullyPutNodes = append(successfullyPutNodes, preferredDynamoNode)
-			wCount++
+	successfullyPutNodes, failures := s.fanOut(ctx, s.membership.SortByLiveness(s.otherNodes(preferenceList)), s.wValue-1, func(ctx context.Context, node DynamoNode) error {
+		var success bool
+		if err := s.connections.CallContext(ctx, node, "MyDynamo.PutRaw", putArgs, &success); err != nil {
+			return err
+		}
+		if !success {
+			return errors.New("put rejected")
 		}
+		return nil
+	})
+	wCount := 1 + len(successfullyPutNodes)
+
+	if s.sloppyQuorum && wCount < s.wValue {
+		wCount += s.putHints(ctx, putArgs, nodesOfFailures(failures), s.wValue-wCount)
 	}
 
 	s.nodePutRecords.ExecAtomic(func() {
@@ -188,7 +431,69 @@ func (s *DynamoServer) Put(putArgs PutArgs, result *bool) error {
 		}
 	})
 
-	*result = wCount >= s.wValue
+	*result = PutResult{
+		Success:  wCount >= s.wValue,
+		Acks:     wCount,
+		Failures: failures,
+	}
+	return nil
+}
+
+// Puts the write for the unavailable nodes to the nodes after the top N nodes of the key as hints
+// At most `count` hints are put, each to a distinct node. Returns the number of hints put.
+func (s *DynamoServer) putHints(ctx context.Context, putArgs PutArgs, unavailableNodes []DynamoNode, count int) int {
+	if count > len(unavailableNodes) {
+		count = len(unavailableNodes)
+	}
+
+	hintCount := 0
+	for _, fallbackDynamoNode := range s.membership.SortByLiveness(s.fallbackNodesOfKey(putArgs.Key)) {
+		if hintCount >= count {
+			break
+		}
+		if fallbackDynamoNode == s.selfNode {
+			continue
+		}
+
+		hint := Hint{
+			Owner:   unavailableNodes[hintCount],
+			PutArgs: putArgs,
+		}
+		var result bool
+		hintCtx, cancel := contextWithTimeout(ctx, s.requestTimeout)
+		err := s.connections.CallContext(hintCtx, fallbackDynamoNode, "MyDynamo.PutHint", hint, &result)
+		cancel()
+		if err == nil && result {
+			hintCount++
+		}
+	}
+	return hintCount
+}
+
+// Forwards the Put to the first node in the preference list that accepts it
+// The result is set to the result of the node coordinating the Put, or to a failed result if no node accepts it. The
+// failures of the nodes that did not accept the Put are added to the result.
+func (s *DynamoServer) forwardPut(ctx context.Context, preferenceList []DynamoNode, putArgs PutArgs, result *PutResult) error {
+	failures := make([]NodeFailure, 0)
+	for _, preferredDynamoNode := range preferenceList {
+		// The node may fail to put to W nodes after storing the entry, so only try the next node on errors
+		var forwardedResult PutResult
+		forwardCtx, cancel := contextWithTimeout(ctx, s.requestTimeout)
+		err := s.connections.CallContext(forwardCtx, preferredDynamoNode, "MyDynamo.PutWithResult", putArgs, &forwardedResult)
+		cancel()
+		if err == nil {
+			forwardedResult.Failures = append(failures, forwardedResult.Failures...)
+			*result = forwardedResult
+			return nil
+		}
+		failures = append(failures, NodeFailure{
+			Node:        preferredDynamoNode,
+			Reason:      err.Error(),
+			Unreachable: isUnreachableError(err),
+		})
+	}
+
+	*result = PutResult{Success: false, Failures: failures}
 	return nil
 }
 
@@ -201,27 +506,59 @@ func (s *DynamoServer) PutRaw(putArgs PutArgs, result *bool) error {
 		return err
 	}
 
-	key := putArgs.Key
-	vClock := putArgs.Context.Clock
-	value := putArgs.Value
+	if err := s.putLocalEntry(putArgs, true); err != nil {
+		*result = false
+		return err
+	}
+
+	*result = true
+	return nil
+}
 
-	s.localEntriesMap.Lock(key)
-	defer s.localEntriesMap.Unlock(key)
+// Put the entry to the local storage, dropping the local entries superseded by it
+// If `persist` is true, the accepted entry is appended to the write-ahead log before the storage is updated.
+// Entries that are causally older than or equal to a local entry are ignored. Tombstones past the grace period
+// supersede the local entries but are not stored, so gossip cannot bring back the tombstones already collected.
+func (s *DynamoServer) putLocalEntry(putArgs PutArgs, persist bool) error {
+	s.storage.Lock(putArgs.Key)
+	defer s.storage.Unlock(putArgs.Key)
+
+	return s.putLockedEntry(putArgs, persist)
+}
 
-	localEntries := s.localEntriesMap.Get(key)
+// Put the entry to the local storage like `DynamoServer.putLocalEntry`, with its key already locked in the storage
+func (s *DynamoServer) putLockedEntry(putArgs PutArgs, persist bool) error {
+	key := putArgs.Key
+	context := putArgs.Context
+
+	localEntries, err := s.storage.Get(key)
+	if err != nil {
+		return err
+	}
 
 	indicesToRemove := make([]int, 0)
 	for i, localEntry := range localEntries {
-		if vClock.LessThan(localEntry.Context.Clock) || vClock.Equals(localEntry.Context.Clock) {
-			*result = true
+		switch context.Compare(localEntry.Context) {
+		case CLOCK_BEFORE, CLOCK_EQUAL:
 			return nil
+		case CLOCK_AFTER:
+			indicesToRemove = append(indicesToRemove, i)
 		}
+	}
 
-		if localEntry.Context.Clock.LessThan(vClock) {
-			indicesToRemove = append(indicesToRemove, i)
+	if persist && s.wal != nil {
+		// Appending to the log and updating the storage must not be split by a snapshot
+		s.persistRWMutex.RLock()
+		defer s.persistRWMutex.RUnlock()
+
+		if err := s.wal.Append(putArgs); err != nil {
+			log.Println(DYNAMO_SERVER, "Failed to append to write-ahead log:", err)
+			return err
 		}
 	}
 
+	store := !putArgs.Deleted || putArgs.DeletedAt >= s.tombstoneDeadline()
+
 	s.nodePutRecords.ExecAtomic(func() {
 		for i := len(indicesToRemove) - 1; i >= 0; i-- {
 			entryToRemove := &localEntries[indicesToRemove[i]]
@@ -229,76 +566,99 @@ func (s *DynamoServer) PutRaw(putArgs PutArgs, result *bool) error {
 			s.nodePutRecords.DeletePutRecord(putRecordToRemove)
 		}
 
-		putRecord := NewPutRecord(key, putArgs.Context)
-		s.nodePutRecords.AddPutRecordToDynamoNode(putRecord, s.selfNode)
+		if store {
+			putRecord := NewPutRecord(key, putArgs.Context)
+			s.nodePutRecords.AddPutRecordToDynamoNode(putRecord, s.selfNode)
+		}
 	})
 
 	for i := len(indicesToRemove) - 1; i >= 0; i-- {
 		localEntries = remove(localEntries, indicesToRemove[i])
 	}
 
-	localEntries = append(localEntries, ObjectEntry{
-		Context: NewContext(vClock),
-		Value:   value,
-	})
-
-	s.localEntriesMap.Put(key, localEntries)
+	if store {
+		localEntries = append(localEntries, entryOfPutArgs(putArgs))
+	}
 
-	*result = true
-	return nil
+	return s.storage.Put(key, localEntries)
 }
 
 // Get a file from this server, matched with R other servers
 // Get will get files from the top R nodes of its preference list. (spec)
+// With a hash ring, the preference list of the key is used. This server is only read from if it is in the top N nodes
+// of the key. Deleted values are not returned, even though their tombstones supersede the older values.
 func (s *DynamoServer) Get(key string, result *DynamoResult) error {
+	return s.get(context.Background(), key, result)
+}
+
+// Get a file like `DynamoServer.Get`, with the requests to the other nodes cancelled once ctx is done
+func (s *DynamoServer) get(ctx context.Context, key string, result *DynamoResult) error {
 	if err := s.checkCrashed(); err != nil {
 		return err
 	}
 
-	if err := s.GetRaw(key, result); err != nil {
-		return err
-	}
+	preferenceList := s.preferenceListOfKey(key)
 
-	rCount := 1
-	for _, preferredDynamoNode := range s.preferenceList {
-		if rCount >= s.rValue {
-			break
-		}
-		if preferredDynamoNode == s.selfNode {
-			continue
+	rCount := 0
+	result.EntryList = make([]ObjectEntry, 0)
+	replicaEntries := make(map[DynamoNode][]ObjectEntry)
+	if containsDynamoNode(preferenceList, s.selfNode) {
+		if err := s.GetRaw(key, result); err != nil {
+			return err
 		}
+		replicaEntries[s.selfNode] = append([]ObjectEntry(nil), result.EntryList...)
+		rCount++
+	}
 
-		// TODO: Reuse RPC client
-		rpcClient := NewDynamoRPCClientFromDynamoNodeAndConnect(preferredDynamoNode)
-		defer rpcClient.CleanConn()
-
+	// The results of the replicas answering after the timeout are dropped
+	remoteResults := make(map[DynamoNode][]ObjectEntry)
+	remoteResultsMutex := sync.Mutex{}
+	respondedNodes, failures := s.fanOut(ctx, s.membership.SortByLiveness(s.otherNodes(preferenceList)), s.rValue-rCount, func(ctx context.Context, node DynamoNode) error {
 		remoteResult := DynamoResult{EntryList: nil}
-		if rpcClient.GetRaw(key, &remoteResult) {
-			rCount++
-
-			// Iterate over remote entries and add concurrent entries to result
-			// TODO: Improve performance
-			for _, remoteEntry := range remoteResult.EntryList {
-				isRemoteEntryConcurrent := true
-				indicesToRemove := make([]int, 0)
-				for i, localEntry := range result.EntryList {
-					if localEntry.Context.Clock.LessThan(remoteEntry.Context.Clock) {
-						indicesToRemove = append(indicesToRemove, i)
-					} else if !remoteEntry.Context.Clock.Concurrent(localEntry.Context.Clock) {
-						isRemoteEntryConcurrent = false
-					}
-				}
+		if err := s.connections.CallContext(ctx, node, "MyDynamo.GetRaw", key, &remoteResult); err != nil {
+			return err
+		}
+		remoteResultsMutex.Lock()
+		remoteResults[node] = remoteResult.EntryList
+		remoteResultsMutex.Unlock()
+		return nil
+	})
+	result.Failures = failures
+	result.Replies = rCount + len(respondedNodes)
 
-				for i := len(indicesToRemove) - 1; i >= 0; i-- {
-					result.EntryList = remove(result.EntryList, indicesToRemove[i])
-				}
-				if isRemoteEntryConcurrent {
-					result.EntryList = append(result.EntryList, remoteEntry)
+	remoteResultsMutex.Lock()
+	for _, node := range respondedNodes {
+		replicaEntries[node] = remoteResults[node]
+	}
+	remoteResultsMutex.Unlock()
+
+	for _, node := range respondedNodes {
+		// Iterate over remote entries and add concurrent entries to result
+		// TODO: Improve performance
+		for _, remoteEntry := range replicaEntries[node] {
+			isRemoteEntryConcurrent := true
+			indicesToRemove := make([]int, 0)
+			for i, localEntry := range result.EntryList {
+				switch localEntry.Context.Compare(remoteEntry.Context) {
+				case CLOCK_BEFORE:
+					indicesToRemove = append(indicesToRemove, i)
+				case CLOCK_EQUAL, CLOCK_AFTER:
+					isRemoteEntryConcurrent = false
 				}
 			}
+
+			for i := len(indicesToRemove) - 1; i >= 0; i-- {
+				result.EntryList = remove(result.EntryList, indicesToRemove[i])
+			}
+			if isRemoteEntryConcurrent {
+				result.EntryList = append(result.EntryList, remoteEntry)
+			}
 		}
 	}
 
+	s.readRepairReplicas(key, result.EntryList, replicaEntries)
+	// Tombstones are only returned by GetRaw, to be replicated
+	result.EntryList = liveEntries(result.EntryList)
 	return nil
 }
 
@@ -310,53 +670,345 @@ func (s *DynamoServer) GetRaw(key string, result *DynamoResult) error {
 
 	result.EntryList = make([]ObjectEntry, 0)
 
-	s.localEntriesMap.RLock(key)
-	defer s.localEntriesMap.RUnlock(key)
+	s.storage.RLock(key)
+	defer s.storage.RUnlock(key)
 
-	result.EntryList = append(result.EntryList, s.localEntriesMap.Get(key)...)
+	localEntries, err := s.storage.Get(key)
+	if err != nil {
+		return err
+	}
+	result.EntryList = append(result.EntryList, localEntries...)
 
 	return nil
 }
 
+// Opens the local storage, restores the local entries from this node's data directory and opens the write-ahead log
+// for appending. The latest valid snapshot is loaded first, then the accepted PutArgs logged after it are replayed in
+// order, so the entries superseded before the restart are dropped again.
+// The write-ahead log is only used by the memory storage engine, other storage engines persist entries by themselves.
+func (s *DynamoServer) Recover() error {
+	if err := s.storage.Open(); err != nil {
+		return err
+	}
+	if err := s.hints.Open(); err != nil {
+		return err
+	}
+
+	if s.dataDir == "" || s.wal != nil || s.storageEngine != STORAGE_ENGINE_MEMORY {
+		return nil
+	}
+
+	if err := os.MkdirAll(s.dataDir, DATA_DIR_PERM); err != nil {
+		return err
+	}
+
+	wal, err := OpenWriteAheadLog(s.dataDir)
+	if err != nil {
+		return err
+	}
+
+	walSegment, err := s.loadLatestSnapshot()
+	if err != nil {
+		_ = wal.Close()
+		return err
+	}
+
+	replayedCount := 0
+	err = wal.Replay(walSegment, func(putArgs PutArgs) {
+		if err := s.putLocalEntry(putArgs, false); err != nil {
+			log.Println(DYNAMO_SERVER, "Failed to replay write-ahead log record:", err)
+		}
+		replayedCount++
+	})
+	if err != nil {
+		_ = wal.Close()
+		return err
+	}
+
+	log.Println(DYNAMO_SERVER, "Replayed", replayedCount, "records from", s.dataDir)
+	s.wal = wal
+	return nil
+}
+
 /* Belows are functions that implement server boot up and initialization */
 func NewDynamoServer(w int, r int, hostAddr string, hostPort string, id string) DynamoServer {
+	config := NewDynamoConfig()
+	config.WValue = w
+	config.RValue = r
+
+	return NewDynamoServerWithConfig(config, hostAddr, hostPort, id)
+}
+
+// Creates a new DynamoServer with the given cluster configurations
+// If a data directory is configured, this node's data is persisted in a subdirectory named by its ID.
+// Panics if the configurations are invalid, see `DynamoConfig.Validate`.
+func NewDynamoServerWithConfig(config DynamoConfig, hostAddr string, hostPort string, id string) DynamoServer {
 	preferenceList := make([]DynamoNode, 0)
 	selfNodeInfo := DynamoNode{
 		Address: hostAddr,
 		Port:    hostPort,
 	}
 
+	dataDir := ""
+	if config.DataDir != "" {
+		dataDir = filepath.Join(config.DataDir, id)
+	}
+
+	storageEngine := config.StorageEngineOfNode(id)
+	storage, err := NewStorageEngine(storageEngine, dataDir)
+	if err != nil {
+		panic(err)
+	}
+
+	suspectTimeout := time.Duration(config.SuspectTimeoutMs) * time.Millisecond
+	deadTimeout := time.Duration(config.DeadTimeoutMs) * time.Millisecond
+
+	connections := NewConnectionPool(config.ConnectionPoolMaxIdle, time.Duration(config.RequestTimeoutMs)*time.Millisecond)
+	if config.ReplicationTransport == TRANSPORT_GRPC {
+		connections.enableGRPC(config.Transport, config.GRPCPortOffset)
+	}
+
 	return DynamoServer{
-		wValue:           w,
-		rValue:           r,
+		wValue:           config.WValue,
+		rValue:           config.RValue,
+		nValue:           config.NValue,
 		preferenceList:   preferenceList,
 		selfNode:         selfNodeInfo,
 		nodeID:           id,
-		localEntriesMap:  NewObjectEntriesMap(),
+		storage:          storage,
+		storageEngine:    storageEngine,
 		nodePutRecords:   NewDynamoNodePutRecords(),
 		isCrashed:        false,
 		isCrashedRWMutex: &sync.RWMutex{},
+		dataDir:          dataDir,
+		wal:              nil,
+		persistRWMutex:   &sync.RWMutex{},
+		snapshotMutex:    &sync.Mutex{},
+		snapshotInterval: time.Duration(config.SnapshotIntervalSeconds) * time.Second,
+		snapshotsToKeep:  config.SnapshotRetention,
+		virtualNodes:     config.VirtualNodes,
+		ring:             nil,
+		topologyRWMutex:  &sync.RWMutex{},
+		joinMutex:        &sync.Mutex{},
+		sloppyQuorum:     config.SloppyQuorum,
+		hints:            NewHintStore(hintsDir(dataDir)),
+		handoffInterval:  time.Duration(config.HintedHandoffIntervalMs) * time.Millisecond,
+		stats:            &ReplicationStats{},
+		merkleTrees:      newMerkleTreeCache(),
+		gossipInterval:   time.Duration(config.GossipIntervalMs) * time.Millisecond,
+		gossipJitter:     time.Duration(config.GossipJitterMs) * time.Millisecond,
+		gossipFanout:     config.GossipFanout,
+		gossipMode:       config.GossipMode,
+		readRepair:       config.ReadRepair,
+		requestTimeout:   time.Duration(config.RequestTimeoutMs) * time.Millisecond,
+		connections:      connections,
+		poolHealthCheck:  time.Duration(config.ConnectionHealthCheckIntervalMs) * time.Millisecond,
+		membership:       NewMembership(selfNodeInfo, suspectTimeout, deadTimeout),
+		beatInterval:     time.Duration(config.HeartbeatIntervalMs) * time.Millisecond,
+		tombstoneGrace:   time.Duration(config.TombstoneGracePeriodMs) * time.Millisecond,
+		tombstoneGC:      time.Duration(config.TombstoneGCIntervalMs) * time.Millisecond,
+		maxClockEntries:  config.VectorClockMaxEntries,
+		causality:        config.Causality,
+		transport:        config.Transport,
+		grpcPortOffset:   config.GRPCPortOffset,
+		lifecycle:        &serverLifecycle{done: make(chan struct{})},
 	}
 }
 
+// Stops serving this server
+// The background loops are stopped and the listener and its connections are closed, then `ServeDynamoServer` closes the storage of the
+// server and returns nil. Calling Shutdown more than once has no effect.
+func (s *DynamoServer) Shutdown() {
+	s.lifecycle.mutex.Lock()
+	defer s.lifecycle.mutex.Unlock()
+
+	if s.isShutdown() {
+		return
+	}
+
+	close(s.lifecycle.done)
+	if s.lifecycle.listener != nil {
+		_ = s.lifecycle.listener.Close()
+	}
+	if s.lifecycle.grpc != nil {
+		s.lifecycle.grpc.Stop()
+	}
+}
+
+// Returns true if the server is shut down
+func (s *DynamoServer) isShutdown() bool {
+	select {
+	case <-s.lifecycle.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Runs the background loop in a goroutine, the loop must return once the server is shut down
+func (s *DynamoServer) startLoop(loop func()) {
+	s.lifecycle.loops.Add(1)
+	go func() {
+		defer s.lifecycle.loops.Done()
+		loop()
+	}()
+}
+
+// Runs the background task in a goroutine unless the server is shut down, and returns true if the task is started
+// The task is started under the lifecycle mutex, so it is either waited for on shutdown like the loops, or not started
+// once the shutdown has begun.
+func (s *DynamoServer) startTask(task func()) bool {
+	s.lifecycle.mutex.Lock()
+	defer s.lifecycle.mutex.Unlock()
+
+	if s.isShutdown() {
+		return false
+	}
+	s.lifecycle.loops.Add(1)
+	go func() {
+		defer s.lifecycle.loops.Done()
+		task()
+	}()
+	return true
+}
+
+// Closes the pooled connections, the write-ahead log, the hints and the storage of the server
+func (s *DynamoServer) closeStorage() error {
+	s.connections.Close()
+
+	var err error
+	if s.wal != nil {
+		err = s.wal.Close()
+	}
+	if closeErr := s.hints.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := s.storage.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Serves the DynamoServer through the configured transports until it is shut down
+// Returns nil if the server is shut down by `DynamoServer.Shutdown`, otherwise the error that stopped the server. If
+// either transport stops with an error, the server is shut down.
 func ServeDynamoServer(dynamoServer DynamoServer) error {
-	rpcServer := rpc.NewServer()
-	e := rpcServer.RegisterName("MyDynamo", &dynamoServer)
-	if e != nil {
-		log.Println(DYNAMO_SERVER, "Server Can't start During Name Registration")
+	if e := dynamoServer.Recover(); e != nil {
+		log.Println(DYNAMO_SERVER, "Server Can't start During Recovering From", dynamoServer.dataDir)
 		return e
 	}
 
-	log.Println(DYNAMO_SERVER, "Successfully Registered the RPC Interfaces")
+	address := dynamoServer.selfNode.Address + ":" + dynamoServer.selfNode.Port
+	var l *connListener
+	var handler http.Handler
+	if dynamoServer.transport != TRANSPORT_GRPC {
+		rpcServer := rpc.NewServer()
+		e := rpcServer.RegisterName("MyDynamo", &dynamoServer)
+		if e != nil {
+			log.Println(DYNAMO_SERVER, "Server Can't start During Name Registration")
+			return e
+		}
 
-	l, e := net.Listen("tcp", dynamoServer.selfNode.Address+":"+dynamoServer.selfNode.Port)
-	if e != nil {
-		log.Println(DYNAMO_SERVER, "Server Can't start During Port Listening")
-		return e
+		log.Println(DYNAMO_SERVER, "Successfully Registered the RPC Interfaces")
+
+		tcpListener, e := net.Listen("tcp", address)
+		if e != nil {
+			log.Println(DYNAMO_SERVER, "Server Can't start During Port Listening")
+			return e
+		}
+		l = &connListener{Listener: tcpListener, conns: make(map[net.Conn]bool)}
+
+		// The HTTP/JSON gateway and the DynamoDB API are served alongside the RPC endpoint
+		mux := http.NewServeMux()
+		mux.Handle(rpc.DefaultRPCPath, rpcServer)
+		mux.Handle(HTTP_KV_PATH, &httpGateway{server: &dynamoServer})
+		mux.Handle(DYNAMODB_API_PATH, &dynamoDBGateway{server: &dynamoServer})
+		handler = mux
+
+		log.Println(DYNAMO_SERVER, "Successfully Listening to Target Port ", address)
+	}
+
+	var grpcListener net.Listener
+	var grpcServer *grpc.Server
+	if dynamoServer.transport != TRANSPORT_RPC {
+		grpcAddress := dynamoServer.selfNode.Address + ":" +
+			grpcPortOf(dynamoServer.selfNode, dynamoServer.transport, dynamoServer.grpcPortOffset)
+		var e error
+		if grpcListener, e = net.Listen("tcp", grpcAddress); e != nil {
+			log.Println(DYNAMO_SERVER, "Server Can't start During gRPC Port Listening")
+			if l != nil {
+				_ = l.Close()
+			}
+			return e
+		}
+		grpcServer = newGRPCServer(&dynamoServer)
+
+		log.Println(DYNAMO_SERVER, "Successfully Listening to gRPC Port ", grpcAddress)
+	}
+
+	dynamoServer.lifecycle.mutex.Lock()
+	dynamoServer.lifecycle.listener = l
+	dynamoServer.lifecycle.grpc = grpcServer
+	dynamoServer.lifecycle.mutex.Unlock()
+	if dynamoServer.isShutdown() {
+		if l != nil {
+			_ = l.Close()
+		}
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+	}
+
+	if dynamoServer.wal != nil && dynamoServer.snapshotInterval > 0 {
+		dynamoServer.startLoop(dynamoServer.runSnapshotLoop)
+	}
+	if dynamoServer.handoffInterval > 0 {
+		dynamoServer.startLoop(dynamoServer.runHintedHandoffLoop)
+	}
+	if dynamoServer.gossipInterval > 0 {
+		dynamoServer.startLoop(dynamoServer.runGossipLoop)
+	}
+	if dynamoServer.poolHealthCheck > 0 {
+		dynamoServer.startLoop(dynamoServer.runConnectionHealthCheckLoop)
+	}
+	if dynamoServer.beatInterval > 0 {
+		dynamoServer.startLoop(dynamoServer.runHeartbeatLoop)
+	}
+	if dynamoServer.tombstoneGC > 0 {
+		dynamoServer.startLoop(dynamoServer.runTombstoneGCLoop)
 	}
 
-	log.Println(DYNAMO_SERVER, "Successfully Listening to Target Port ", dynamoServer.selfNode.Address+":"+dynamoServer.selfNode.Port)
 	log.Println(DYNAMO_SERVER, "Serving Server Now")
 
-	return http.Serve(l, rpcServer)
+	// Both transports stop serving once the server is shut down
+	serveErrs := make(chan error, 2)
+	serving := 0
+	if l != nil {
+		serving++
+		go func() {
+			serveErrs <- http.Serve(l, handler)
+		}()
+	}
+	if grpcServer != nil {
+		serving++
+		go func() {
+			serveErrs <- grpcServer.Serve(grpcListener)
+		}()
+	}
+
+	e := <-serveErrs
+	if !dynamoServer.isShutdown() {
+		// Stop serving the other transport as well
+		dynamoServer.Shutdown()
+		dynamoServer.lifecycle.loops.Wait()
+		_ = dynamoServer.closeStorage()
+		return e
+	}
+	for i := 1; i < serving; i++ {
+		<-serveErrs
+	}
+
+	dynamoServer.lifecycle.loops.Wait()
+	log.Println(DYNAMO_SERVER, "Server Shut Down")
+	return dynamoServer.closeStorage()
 }
@@ -0,0 +1,227 @@
+package mydynamo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Magic bytes at the beginning of a snapshot file
+const snapshotMagic string = "MDSN"
+
+// Errors of corrupted snapshot files, which are the only invalid snapshots recovery skips
+var errSnapshotNotSnapshotFile = errors.New("not a snapshot file")
+var errSnapshotChecksumMismatch = errors.New("snapshot checksum mismatch")
+
+// Point-in-time state of a node
+// A snapshot covers all records in the write-ahead log segments before WALSegment, so the state of the node is
+// restored by loading the snapshot and replaying the segments from WALSegment.
+type NodeSnapshot struct {
+	WALSegment uint64                     //First write-ahead log segment not covered by the snapshot
+	Entries    map[string][]ObjectEntry   //Local entries of the node
+	PutRecords map[PutRecord][]DynamoNode //Nodes known to have seen each PutRecord
+}
+
+// Returns the path of the snapshot file covering the write-ahead log segments before walSegment
+func snapshotPath(dir string, walSegment uint64) string {
+	return filepath.Join(dir, fmt.Sprintf(SNAPSHOT_FILE_PATTERN, walSegment))
+}
+
+// Writes the snapshot to the directory
+// The snapshot is written as | magic | checksum | payload | where the payload is the gob-encoded NodeSnapshot and the
+// checksum is the CRC-32 (Castagnoli) of the payload. The file is written to a temporary file first and renamed, so
+// a crash during the write never leaves a partial snapshot behind.
+func WriteSnapshot(dir string, snapshot NodeSnapshot) error {
+	var payload bytes.Buffer
+	if err := gob.NewEncoder(&payload).Encode(snapshot); err != nil {
+		return err
+	}
+
+	data := make([]byte, len(snapshotMagic)+4+payload.Len())
+	copy(data, snapshotMagic)
+	binary.BigEndian.PutUint32(data[len(snapshotMagic):], crc32.Checksum(payload.Bytes(), walCRCTable))
+	copy(data[len(snapshotMagic)+4:], payload.Bytes())
+
+	path := snapshotPath(dir, snapshot.WALSegment)
+	tmpPath := path + ".tmp"
+
+	file, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, SNAPSHOT_FILE_PERM)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// Reads and verifies the snapshot file at the given path
+func ReadSnapshot(path string) (NodeSnapshot, error) {
+	var snapshot NodeSnapshot
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return snapshot, err
+	}
+	if len(data) < len(snapshotMagic)+4 || string(data[:len(snapshotMagic)]) != snapshotMagic {
+		return snapshot, errSnapshotNotSnapshotFile
+	}
+
+	payload := data[len(snapshotMagic)+4:]
+	if crc32.Checksum(payload, walCRCTable) != binary.BigEndian.Uint32(data[len(snapshotMagic):]) {
+		return snapshot, errSnapshotChecksumMismatch
+	}
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&snapshot); err != nil {
+		return snapshot, err
+	}
+
+	return snapshot, nil
+}
+
+// Flushes the directory entries (e.g. a renamed file) to the disk
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	err = file.Sync()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Takes a snapshot of this node's state and compacts the write-ahead log
+// The write-ahead log is rotated at the point of the snapshot. Only the most recent snapshots are kept, and the
+// log segments older than the oldest kept snapshot are removed, so each kept snapshot can still be restored.
+func (s *DynamoServer) TakeSnapshot() error {
+	if s.wal == nil {
+		return errors.New("persistence is disabled")
+	}
+
+	s.snapshotMutex.Lock()
+	defer s.snapshotMutex.Unlock()
+
+	s.persistRWMutex.Lock()
+	snapshot := NodeSnapshot{
+		Entries:    make(map[string][]ObjectEntry),
+		PutRecords: s.nodePutRecords.Dump(),
+	}
+	err := func() error {
+		defer s.persistRWMutex.Unlock()
+
+		for _, key := range s.storage.GetKeys() {
+			entries, err := s.storage.Get(key)
+			if err != nil {
+				return err
+			}
+			if len(entries) > 0 {
+				snapshot.Entries[key] = append([]ObjectEntry{}, entries...)
+			}
+		}
+
+		var err error
+		snapshot.WALSegment, err = s.wal.Rotate()
+		return err
+	}()
+	if err != nil {
+		return err
+	}
+
+	if err := WriteSnapshot(s.dataDir, snapshot); err != nil {
+		return err
+	}
+
+	snapshotSegments, err := listSequencedFiles(s.dataDir, SNAPSHOT_FILE_PATTERN)
+	if err != nil {
+		return err
+	}
+	if len(snapshotSegments) > s.snapshotsToKeep {
+		for _, segment := range snapshotSegments[:len(snapshotSegments)-s.snapshotsToKeep] {
+			if err := os.Remove(snapshotPath(s.dataDir, segment)); err != nil {
+				return err
+			}
+		}
+		snapshotSegments = snapshotSegments[len(snapshotSegments)-s.snapshotsToKeep:]
+	}
+
+	return s.wal.RemoveSegmentsBefore(snapshotSegments[0])
+}
+
+// Loads the latest valid snapshot in the data directory into the local storage and PutRecords
+// Returns the first write-ahead log segment not covered by the loaded snapshot, or 0 if there are no snapshots.
+// Corrupted snapshots are skipped, but a snapshot that cannot be read or decoded is an error, so the server does not
+// start without its data. So is a data directory whose snapshots are all corrupted, as the write-ahead log segments
+// they cover may have been removed.
+func (s *DynamoServer) loadLatestSnapshot() (uint64, error) {
+	snapshotSegments, err := listSequencedFiles(s.dataDir, SNAPSHOT_FILE_PATTERN)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := len(snapshotSegments) - 1; i >= 0; i-- {
+		path := snapshotPath(s.dataDir, snapshotSegments[i])
+		snapshot, err := ReadSnapshot(path)
+		if err == errSnapshotNotSnapshotFile || err == errSnapshotChecksumMismatch {
+			log.Println(DYNAMO_SERVER, "Skipping invalid snapshot", path, ":", err)
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("snapshot %s: %v", path, err)
+		}
+
+		for key, entries := range snapshot.Entries {
+			if err := s.storage.Put(key, entries); err != nil {
+				return 0, err
+			}
+		}
+		s.nodePutRecords.Load(snapshot.PutRecords)
+
+		log.Println(DYNAMO_SERVER, "Loaded snapshot", path)
+		return snapshot.WALSegment, nil
+	}
+
+	if len(snapshotSegments) > 0 {
+		return 0, fmt.Errorf("none of the %d snapshots in %s is valid", len(snapshotSegments), s.dataDir)
+	}
+	return 0, nil
+}
+
+// Takes snapshots periodically until the server is shut down
+func (s *DynamoServer) runSnapshotLoop() {
+	ticker := time.NewTicker(s.snapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.lifecycle.done:
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.TakeSnapshot(); err != nil {
+			log.Println(DYNAMO_SERVER, "Failed to take snapshot:", err)
+		}
+	}
+}
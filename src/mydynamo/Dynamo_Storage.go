@@ -0,0 +1,86 @@
+package mydynamo
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+)
+
+// Storage of the local object entries of a server
+// Implementations must be safe for concurrent use by multiple goroutines. The per-key locks are not enforced by the
+// storage itself, callers hold them around read-modify-write sequences on a key.
+type StorageEngine interface {
+	// Prepares the storage for use, e.g. loads the index of a disk-backed storage
+	Open() error
+	// Releases the resources held by the storage
+	Close() error
+	// Returns the keys of entries in the storage
+	GetKeys() []string
+	// Returns the entries (siblings) associated with the given key, or an empty list if there are none
+	Get(key string) ([]ObjectEntry, error)
+	// Replaces the entries (siblings) associated with the given key
+	Put(key string, entries []ObjectEntry) error
+	// Removes the given key and its entries
+	Delete(key string) error
+	// Locks the given key for writing
+	Lock(key string)
+	// Unlocks the given key for writing
+	Unlock(key string)
+	// Locks the given key for reading
+	RLock(key string)
+	// Undoes a single RLock call on the given key
+	RUnlock(key string)
+}
+
+// Creates the StorageEngine with the given name
+// Disk-backed storages keep their files in the directory dir.
+func NewStorageEngine(name string, dir string) (StorageEngine, error) {
+	switch name {
+	case STORAGE_ENGINE_MEMORY:
+		entriesMap := NewObjectEntriesMap()
+		return &entriesMap, nil
+	case STORAGE_ENGINE_DISK:
+		if dir == "" {
+			return nil, fmt.Errorf("storage engine %q requires a data directory", name)
+		}
+		return NewDiskStorageEngine(filepath.Join(dir, DISK_STORAGE_DIR_NAME)), nil
+	default:
+		return nil, fmt.Errorf("unknown storage engine %q", name)
+	}
+}
+
+// Map of per-key RWMutexes, created on first use
+type KeyRWMutexMap struct {
+	entriesRWMutexMap *sync.Map
+}
+
+// Return a new KeyRWMutexMap
+func NewKeyRWMutexMap() KeyRWMutexMap {
+	return KeyRWMutexMap{
+		entriesRWMutexMap: &sync.Map{},
+	}
+}
+
+// Locks RWMutex associated with the given key for writing
+func (m *KeyRWMutexMap) Lock(key string) {
+	mu, _ := m.entriesRWMutexMap.LoadOrStore(key, &sync.RWMutex{})
+	mu.(*sync.RWMutex).Lock()
+}
+
+// Locks RWMutex associated with the given key for reading
+func (m *KeyRWMutexMap) RLock(key string) {
+	mu, _ := m.entriesRWMutexMap.LoadOrStore(key, &sync.RWMutex{})
+	mu.(*sync.RWMutex).RLock()
+}
+
+// Unlocks RWMutex associated with the given key for writing
+func (m *KeyRWMutexMap) Unlock(key string) {
+	mu, _ := m.entriesRWMutexMap.LoadOrStore(key, &sync.RWMutex{})
+	mu.(*sync.RWMutex).Unlock()
+}
+
+// Undoes a single RLock call on the RWMutex associated with the given key
+func (m *KeyRWMutexMap) RUnlock(key string) {
+	mu, _ := m.entriesRWMutexMap.LoadOrStore(key, &sync.RWMutex{})
+	mu.(*sync.RWMutex).RUnlock()
+}
@@ -0,0 +1,151 @@
+package mydynamo
+
+import (
+	"log"
+	"time"
+)
+
+// Deletes the key from this server and W other servers like `DynamoServer.Put`
+// A tombstone with the context is put in place of a value. It supersedes the entries causally older than the context,
+// keeps the concurrent ones, and is replicated like any entry until it is garbage collected.
+func (s *DynamoServer) Delete(deleteArgs DeleteArgs, result *bool) error {
+	var putResult PutResult
+	err := s.deleteWithResult(deleteArgs, &putResult)
+	*result = putResult.Success
+	return err
+}
+
+// Deletes the key like `DynamoServer.Delete`, and sets the result like `DynamoServer.PutWithResult`
+func (s *DynamoServer) deleteWithResult(deleteArgs DeleteArgs, result *PutResult) error {
+	return s.PutWithResult(tombstonePutArgs(deleteArgs), result)
+}
+
+// Returns the arguments to put the tombstone of the deletion
+func tombstonePutArgs(deleteArgs DeleteArgs) PutArgs {
+	return PutArgs{
+		Key:       deleteArgs.Key,
+		Context:   deleteArgs.Context,
+		Deleted:   true,
+		DeletedAt: time.Now().UnixNano(),
+	}
+}
+
+// Garbage collects the local tombstones, the number of removed tombstones is returned through `count`
+// See `DynamoServer.collectTombstones`.
+func (s *DynamoServer) CollectTombstones(_ Empty, count *int) error {
+	if err := s.checkCrashed(); err != nil {
+		return err
+	}
+
+	var err error
+	*count, err = s.collectTombstones()
+	return err
+}
+
+// Garbage collects the local tombstones periodically until the server is shut down
+// Collections are skipped while the server is crashed.
+func (s *DynamoServer) runTombstoneGCLoop() {
+	ticker := time.NewTicker(s.tombstoneGC)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.lifecycle.done:
+			return
+		case <-ticker.C:
+		}
+
+		if s.checkCrashed() != nil {
+			continue
+		}
+		if count, err := s.collectTombstones(); err != nil {
+			log.Println(DYNAMO_SERVER, "Failed to collect tombstones:", err)
+		} else if count > 0 {
+			log.Println(DYNAMO_SERVER, "Collected", count, "tombstones")
+		}
+	}
+}
+
+// Removes the tombstones deleted longer than the grace period ago that all other nodes in the preference lists of
+// their keys have, and returns the number of removed tombstones
+// This server only knows a node has a tombstone once it has put the tombstone to the node, by a Put, a Delete or
+// gossip. Tombstones restored from the write-ahead log after a restart are collected again.
+func (s *DynamoServer) collectTombstones() (int, error) {
+	deletedBefore := s.tombstoneDeadline()
+
+	count := 0
+	for _, key := range s.storage.GetKeys() {
+		removedCount, err := s.collectTombstonesOfKey(key, deletedBefore)
+		if err != nil {
+			return count, err
+		}
+		count += removedCount
+	}
+	return count, nil
+}
+
+// Removes the tombstones of the key deleted before the given time that all other nodes in its preference list have
+func (s *DynamoServer) collectTombstonesOfKey(key string, deletedBefore int64) (int, error) {
+	replicas := s.otherNodes(s.preferenceListOfKey(key))
+
+	s.storage.Lock(key)
+	defer s.storage.Unlock(key)
+
+	localEntries, err := s.storage.Get(key)
+	if err != nil {
+		return 0, err
+	}
+
+	keptEntries := make([]ObjectEntry, 0, len(localEntries))
+	removedRecords := make([]PutRecord, 0)
+	s.nodePutRecords.ExecAtomic(func() {
+		for _, entry := range localEntries {
+			putRecord := NewPutRecord(key, entry.Context)
+			if entry.Deleted && entry.DeletedAt < deletedBefore && s.isPutToAll(putRecord, replicas) {
+				removedRecords = append(removedRecords, putRecord)
+			} else {
+				keptEntries = append(keptEntries, entry)
+			}
+		}
+	})
+	if len(removedRecords) == 0 {
+		return 0, nil
+	}
+
+	if err := s.storage.Put(key, keptEntries); err != nil {
+		return 0, err
+	}
+	s.nodePutRecords.ExecAtomic(func() {
+		for _, putRecord := range removedRecords {
+			s.nodePutRecords.DeletePutRecord(putRecord)
+		}
+	})
+	return len(removedRecords), nil
+}
+
+// Returns the time in Unix nanoseconds before which tombstones are past the grace period
+func (s *DynamoServer) tombstoneDeadline() int64 {
+	return time.Now().Add(-s.tombstoneGrace).UnixNano()
+}
+
+// Returns true if the entry of the PutRecord is known to be put to all the nodes
+// Must be called in `DynamoNodePutRecords.ExecAtomic`.
+func (s *DynamoServer) isPutToAll(putRecord PutRecord, nodes []DynamoNode) bool {
+	for _, node := range nodes {
+		if !s.nodePutRecords.CheckPutRecordInNode(putRecord, node) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the entries which are not tombstones
+func liveEntries(entries []ObjectEntry) []ObjectEntry {
+	live := make([]ObjectEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Deleted {
+			live = append(live, entry)
+		}
+	}
+	return live
+}
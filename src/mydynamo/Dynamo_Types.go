@@ -1,6 +1,7 @@
 package mydynamo
 
 import (
+	"encoding/json"
 	"sync"
 )
 
@@ -8,14 +9,28 @@ import (
 type Empty struct{}
 
 //Context associated with some value
+//With dotted version vectors, the Clock of a value is the causal context it was put with, and the Dot is the update
+//that put it. The Dot is zero with vector clocks.
 type Context struct {
 	Clock VectorClock
+	Dot   Dot
 }
 
 // Returns the JSON string of the context
 //The key in the JSON string is sorted, which means the JSON strings are equal iff two contexts are equal
 func (c *Context) ToJSON() string {
-	return c.Clock.ToJSON()
+	if c.Dot.IsZero() {
+		return c.Clock.ToJSON()
+	}
+
+	data, err := json.Marshal(struct {
+		Clock map[string]uint64
+		Dot   Dot
+	}{c.Clock.NodeClocks, c.Dot})
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
 }
 
 // Information needed to connect to a DynamoNode
@@ -25,40 +40,74 @@ type DynamoNode struct {
 }
 
 // A single value, as well as the Context associated with it
+// A deleted value is kept as a tombstone, an entry without a value which supersedes the causally older entries.
 type ObjectEntry struct {
-	Context Context
-	Value   []byte
+	Context   Context
+	Value     []byte
+	Deleted   bool  //Whether the entry is a tombstone
+	DeletedAt int64 //Unix nanoseconds the value was deleted at, for tombstones
 }
 
 // Result of a Get operation, a list of ObjectEntry structs
+// Failures and Replies are only set by Get, for the nodes that were read from and failed, and the number of nodes that
+// were read from, including this node.
 type DynamoResult struct {
 	EntryList []ObjectEntry
+	Failures  []NodeFailure
+	Replies   int
+}
+
+// Result of a Put operation
+type PutResult struct {
+	Success  bool          //Whether the entry was put to W nodes
+	Acks     int           //Number of nodes the entry was put to, including the nodes keeping it as a hint
+	Failures []NodeFailure //Failures of the nodes the entry could not be put to
 }
 
 // Arguments required for a Put operation: the key, the context, and the value
+// Tombstones are put with `Deleted` set instead of a value, see `DynamoServer.Delete`.
 type PutArgs struct {
+	Key       string
+	Context   Context
+	Value     []byte
+	Deleted   bool
+	DeletedAt int64
+}
+
+// Arguments required for a Delete operation: the key, and the context of the entries to delete
+type DeleteArgs struct {
 	Key     string
 	Context Context
-	Value   []byte
 }
 
 // Map type to store string type key and object entry pairs
-// It provides methods to lock entries and be safe for concurrent use by multiple goroutines
+// It provides methods to lock entries and be safe for concurrent use by multiple goroutines.
+// It is the default (in-memory) StorageEngine.
 type ObjectEntriesMap struct {
-	entriesMap        *map[string][]ObjectEntry
-	entriesMapMutex   *sync.RWMutex
-	entriesRWMutexMap *sync.Map
+	entriesMap      *map[string][]ObjectEntry
+	entriesMapMutex *sync.RWMutex
+	KeyRWMutexMap
 }
 
 // Return a new ObjectEntriesMap
 func NewObjectEntriesMap() ObjectEntriesMap {
 	return ObjectEntriesMap{
-		entriesMap:        &map[string][]ObjectEntry{},
-		entriesMapMutex:   &sync.RWMutex{},
-		entriesRWMutexMap: &sync.Map{},
+		entriesMap:      &map[string][]ObjectEntry{},
+		entriesMapMutex: &sync.RWMutex{},
+		KeyRWMutexMap:   NewKeyRWMutexMap(),
 	}
 }
 
+// Does nothing, the map is ready to use once created
+func (m *ObjectEntriesMap) Open() error {
+	return nil
+}
+
+// Does nothing, the map is kept in memory only
+func (m *ObjectEntriesMap) Close() error {
+	return nil
+}
+
 // Get the keys of entries in the map
 func (m *ObjectEntriesMap) GetKeys() []string {
 	m.entriesMapMutex.Lock()
@@ -72,53 +121,41 @@ func (m *ObjectEntriesMap) GetKeys() []string {
 }
 
 // Get the entries associated with the given key
-func (m *ObjectEntriesMap) Get(key string) []ObjectEntry {
-	m.entriesMapMutex.Lock()
-	defer m.entriesMapMutex.Unlock()
+func (m *ObjectEntriesMap) Get(key string) ([]ObjectEntry, error) {
+	m.entriesMapMutex.RLock()
+	defer m.entriesMapMutex.RUnlock()
 
-	var entries []ObjectEntry
-	var ok bool
-
-	if entries, ok = (*m.entriesMap)[key]; !ok {
-		entries = make([]ObjectEntry, 0)
-		(*m.entriesMap)[key] = entries
+	if entries, ok := (*m.entriesMap)[key]; ok {
+		return entries, nil
 	}
-	return entries
+	return make([]ObjectEntry, 0), nil
 }
 
 // Put the entries associated with the given key to the map
-func (m *ObjectEntriesMap) Put(key string, entries []ObjectEntry) {
+// Putting an empty list of entries removes the key.
+func (m *ObjectEntriesMap) Put(key string, entries []ObjectEntry) error {
 	m.entriesMapMutex.Lock()
 	defer m.entriesMapMutex.Unlock()
 
-	(*m.entriesMap)[key] = entries
-}
-
-// Locks RWMutex associated with the given key for writing
-func (m *ObjectEntriesMap) Lock(key string) {
-	mu, _ := m.entriesRWMutexMap.LoadOrStore(key, &sync.RWMutex{})
-	mu.(*sync.RWMutex).Lock()
-}
-
-// Locks RWMutex associated with the given key for reading
-func (m *ObjectEntriesMap) RLock(key string) {
-	mu, _ := m.entriesRWMutexMap.LoadOrStore(key, &sync.RWMutex{})
-	mu.(*sync.RWMutex).RLock()
+	if len(entries) == 0 {
+		delete(*m.entriesMap, key)
+	} else {
+		(*m.entriesMap)[key] = entries
+	}
+	return nil
 }
 
-// Unlocks RWMutex associated with the given key for writing
-func (m *ObjectEntriesMap) Unlock(key string) {
-	mu, _ := m.entriesRWMutexMap.LoadOrStore(key, &sync.RWMutex{})
-	mu.(*sync.RWMutex).Unlock()
-}
+// Delete the entries associated with the given key from the map
+func (m *ObjectEntriesMap) Delete(key string) error {
+	m.entriesMapMutex.Lock()
+	defer m.entriesMapMutex.Unlock()
 
-// Undoes a single RLock call on the RWMutex associated with the given key
-func (m *ObjectEntriesMap) RUnlock(key string) {
-	mu, _ := m.entriesRWMutexMap.LoadOrStore(key, &sync.RWMutex{})
-	mu.(*sync.RWMutex).RUnlock()
+	delete(*m.entriesMap, key)
+	return nil
 }
 
 // PutArg identifier. It is used to record if the server saw a PutArg before
+// ContextString is the identity of the context, see `Context.Identity`.
 type PutRecord struct {
 	Key           string
 	ContextString string
@@ -128,7 +165,7 @@ type PutRecord struct {
 func NewPutRecord(key string, context Context) PutRecord {
 	return PutRecord{
 		Key:           key,
-		ContextString: context.ToJSON(),
+		ContextString: context.Identity(),
 	}
 }
 
@@ -185,6 +222,31 @@ func (r *DynamoNodePutRecords) CheckPutRecordInNode(putRecord PutRecord, node Dy
 	return false
 }
 
+// Returns a copy of all records, as a map from PutRecord to the list of DynamoNodes that saw it
+func (r *DynamoNodePutRecords) Dump() map[PutRecord][]DynamoNode {
+	r.putRecordSeenNodesMutex.RLock()
+	defer r.putRecordSeenNodesMutex.RUnlock()
+
+	records := make(map[PutRecord][]DynamoNode, len(*r.putRecordSeenNodes))
+	for putRecord, seenNodes := range *r.putRecordSeenNodes {
+		nodes := make([]DynamoNode, 0, len(*seenNodes))
+		for node := range *seenNodes {
+			nodes = append(nodes, node)
+		}
+		records[putRecord] = nodes
+	}
+	return records
+}
+
+// Adds all records returned by `DynamoNodePutRecords.Dump`
+func (r *DynamoNodePutRecords) Load(records map[PutRecord][]DynamoNode) {
+	for putRecord, nodes := range records {
+		for _, node := range nodes {
+			r.AddPutRecordToDynamoNode(putRecord, node)
+		}
+	}
+}
+
 // Lock the DynamoNodePutRecords for writing
 func (r *DynamoNodePutRecords) WLock() {
 	r.mu.Lock()
@@ -15,6 +15,27 @@ func remove(list []ObjectEntry, index int) []ObjectEntry {
 // 	return false
 // }
 
+//Returns true if the specified list of DynamoNodes contains the specified node
+func containsDynamoNode(list []DynamoNode, node DynamoNode) bool {
+	for _, v := range list {
+		if v == node {
+			return true
+		}
+	}
+	return false
+}
+
+//Returns a copy of the specified list of DynamoNodes without the specified node
+func removeDynamoNode(list []DynamoNode, node DynamoNode) []DynamoNode {
+	result := make([]DynamoNode, 0, len(list))
+	for _, v := range list {
+		if v != node {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
 //Rotates a preference list by one, so that we can give each node a unique preference list
 func RotateServerList(list []DynamoNode) []DynamoNode {
 	return append(list[1:], list[0])
@@ -36,6 +57,35 @@ func NewPutArgs(key string, context Context, value []byte) PutArgs {
 	}
 }
 
+//Creates a new PutArgs struct putting the entry to the key, which may be a tombstone
+func NewPutArgsFromEntry(key string, entry ObjectEntry) PutArgs {
+	return PutArgs{
+		Key:       key,
+		Context:   entry.Context,
+		Value:     entry.Value,
+		Deleted:   entry.Deleted,
+		DeletedAt: entry.DeletedAt,
+	}
+}
+
+//Creates a new DeleteArgs struct with the specified members
+func NewDeleteArgs(key string, context Context) DeleteArgs {
+	return DeleteArgs{
+		Key:     key,
+		Context: context,
+	}
+}
+
+//Returns the entry put by the PutArgs
+func entryOfPutArgs(putArgs PutArgs) ObjectEntry {
+	return ObjectEntry{
+		Context:   putArgs.Context,
+		Value:     putArgs.Value,
+		Deleted:   putArgs.Deleted,
+		DeletedAt: putArgs.DeletedAt,
+	}
+}
+
 //Creates a new DynamoNode struct with the specified members
 func NewDynamoNode(addr string, port string) DynamoNode {
 	return DynamoNode{
@@ -1,16 +1,27 @@
 package mydynamo
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
 
 //The vector clock data type for dynamo server events
+//Timestamps has the Unix nanoseconds each node last incremented its clock at, which are only used by Prune.
+//They are not part of the causality, so clocks differing only by their timestamps are equal.
 type VectorClock struct {
 	NodeClocks map[string]uint64
+	Timestamps map[string]int64
 }
 
+//The causal order of a VectorClock to another one, see VectorClock.Compare
+type ClockOrder int
+
 //Creates a new VectorClock
 func NewVectorClock() VectorClock {
 	return VectorClock{
 		NodeClocks: make(map[string]uint64),
+		Timestamps: make(map[string]int64),
 	}
 }
 
@@ -37,12 +48,56 @@ func (s VectorClock) Concurrent(otherVectorClock VectorClock) bool {
 	return !s.Equals(otherVectorClock) && !s.LessThan(otherVectorClock) && !otherVectorClock.LessThan(s)
 }
 
-//Increments this VectorClock at the element associated with nodeId
+//Returns the order of this VectorClock to the other one, walking the elements of this VectorClock once
+//The order is CLOCK_BEFORE if the other VectorClock is causally descended from this one, CLOCK_AFTER if this one is
+//causally descended from the other one, CLOCK_EQUAL if they are equal, and CLOCK_CONCURRENT otherwise, in agreement
+//with LessThan, Equals and Concurrent.
+func (s VectorClock) Compare(otherVectorClock VectorClock) ClockOrder {
+	before, after := false, false
+	sharedCount := 0
+	for nodeID, clock := range s.NodeClocks {
+		otherClock, ok := otherVectorClock.NodeClocks[nodeID]
+		if !ok || clock > otherClock {
+			after = true
+		} else if clock < otherClock {
+			before = true
+		}
+		if ok {
+			sharedCount++
+		}
+		if before && after {
+			return CLOCK_CONCURRENT
+		}
+	}
+
+	// The other VectorClock has elements this one does not have
+	if sharedCount < len(otherVectorClock.NodeClocks) {
+		before = true
+	}
+
+	switch {
+	case before && after:
+		return CLOCK_CONCURRENT
+	case before:
+		return CLOCK_BEFORE
+	case after:
+		return CLOCK_AFTER
+	default:
+		return CLOCK_EQUAL
+	}
+}
+
+//Increments this VectorClock at the element associated with nodeId, and updates the timestamp of the element
 func (s *VectorClock) Increment(nodeID string) {
 	if _, ok := s.NodeClocks[nodeID]; !ok {
 		s.NodeClocks[nodeID] = 0
 	}
 	s.NodeClocks[nodeID]++
+
+	if s.Timestamps == nil {
+		s.Timestamps = make(map[string]int64)
+	}
+	s.Timestamps[nodeID] = time.Now().UnixNano()
 }
 
 //Changes this VectorClock to be causally descended from all VectorClocks in clocks
@@ -57,6 +112,51 @@ func (s *VectorClock) Combine(vectorClocks []VectorClock) {
 				s.NodeClocks[nodeID] = clock
 			}
 		}
+		for nodeID, timestamp := range vectorClock.Timestamps {
+			if s.Timestamps == nil {
+				s.Timestamps = make(map[string]int64)
+			}
+			if s.Timestamps[nodeID] < timestamp {
+				s.Timestamps[nodeID] = timestamp
+			}
+		}
+	}
+}
+
+//Returns a new VectorClock causally descended from both this VectorClock and the other one, like Combine but
+//without changing this VectorClock
+func (s VectorClock) Merge(otherVectorClock VectorClock) VectorClock {
+	merged := VectorClock{
+		NodeClocks: make(map[string]uint64, len(s.NodeClocks)+len(otherVectorClock.NodeClocks)),
+		Timestamps: make(map[string]int64, len(s.Timestamps)+len(otherVectorClock.Timestamps)),
+	}
+	merged.Combine([]VectorClock{s, otherVectorClock})
+	return merged
+}
+
+//Removes the least recently updated elements until this VectorClock has at most maxEntries elements
+//Elements without a timestamp, such as the ones of clocks decoded from JSON, are removed first.
+//As in the Dynamo paper, a clock pruned this way no longer descends from the clocks of the removed elements, so
+//values it should replace can be kept as concurrent siblings.
+func (s *VectorClock) Prune(maxEntries int) {
+	if len(s.NodeClocks) <= maxEntries {
+		return
+	}
+
+	nodeIDs := make([]string, 0, len(s.NodeClocks))
+	for nodeID := range s.NodeClocks {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Slice(nodeIDs, func(i, j int) bool {
+		if s.Timestamps[nodeIDs[i]] != s.Timestamps[nodeIDs[j]] {
+			return s.Timestamps[nodeIDs[i]] < s.Timestamps[nodeIDs[j]]
+		}
+		return nodeIDs[i] < nodeIDs[j]
+	})
+
+	for _, nodeID := range nodeIDs[:len(nodeIDs)-maxEntries] {
+		delete(s.NodeClocks, nodeID)
+		delete(s.Timestamps, nodeID)
 	}
 }
 
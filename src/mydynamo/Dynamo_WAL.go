@@ -0,0 +1,276 @@
+package mydynamo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Size of the record header in the write-ahead log: CRC-32 checksum (4 bytes) followed by payload length (4 bytes)
+const walRecordHeaderSize = 8
+
+var walCRCTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Errors of torn or corrupted records, which are the only invalid records recovery skips
+var errWALIncompleteHeader = errors.New("incomplete record header")
+var errWALIncompletePayload = errors.New("incomplete record payload")
+var errWALChecksumMismatch = errors.New("record checksum mismatch")
+
+// Append-only log of the PutArgs accepted by a server
+// The log is split into segment files named by increasing sequence numbers, and records are only appended to
+// the last (active) segment. Each record is written as | checksum | length | payload | where the payload is the
+// gob-encoded PutArgs and the checksum is the CRC-32 (Castagnoli) of the payload.
+// Every append is fsync'd before returning.
+type WriteAheadLog struct {
+	dir        string
+	file       *os.File //The active segment
+	segmentSeq uint64   //Sequence number of the active segment
+	mu         *sync.Mutex
+}
+
+// Opens (or creates) the write-ahead log in the given directory
+func OpenWriteAheadLog(dir string) (*WriteAheadLog, error) {
+	segments, err := listSequencedFiles(dir, WAL_SEGMENT_PATTERN)
+	if err != nil {
+		return nil, err
+	}
+
+	segmentSeq := uint64(1)
+	if len(segments) > 0 {
+		segmentSeq = segments[len(segments)-1]
+	}
+
+	w := &WriteAheadLog{
+		dir:        dir,
+		file:       nil,
+		segmentSeq: segmentSeq,
+		mu:         &sync.Mutex{},
+	}
+
+	if w.file, err = os.OpenFile(w.segmentPath(segmentSeq), os.O_RDWR|os.O_CREATE, WAL_FILE_PERM); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Returns the path of the segment file with the given sequence number
+func (w *WriteAheadLog) segmentPath(seq uint64) string {
+	return filepath.Join(w.dir, fmt.Sprintf(WAL_SEGMENT_PATTERN, seq))
+}
+
+// Calls f with every valid record in the segments with sequence number >= fromSeq, in the order they were appended
+// A torn or corrupted record at the tail of the active segment (e.g. the process was killed in the middle of an
+// append) ends the replay, and the segment is truncated to the last valid record so that new records are appended
+// after it. A corrupted record in an older segment skips the rest of that segment.
+// A record that passes its checksum but cannot be decoded was written by an incompatible version, so the replay
+// returns the error instead of dropping the record and the records after it.
+func (w *WriteAheadLog) Replay(fromSeq uint64, f func(putArgs PutArgs)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	segments, err := listSequencedFiles(w.dir, WAL_SEGMENT_PATTERN)
+	if err != nil {
+		return err
+	}
+
+	for _, seq := range segments {
+		if seq < fromSeq || seq == w.segmentSeq {
+			continue
+		}
+
+		file, err := os.Open(w.segmentPath(seq))
+		if err != nil {
+			return err
+		}
+		validOffset, err := replayWALSegment(file, f)
+		_ = file.Close()
+		if err != nil && !isWALRecordCorrupted(err) {
+			return fmt.Errorf("write-ahead log segment %s at offset %d: %v", w.segmentPath(seq), validOffset, err)
+		}
+		if err != nil {
+			log.Println(DYNAMO_SERVER, "Skipping the rest of write-ahead log segment", w.segmentPath(seq),
+				"at offset", validOffset, ":", err)
+		}
+	}
+
+	if w.segmentSeq < fromSeq {
+		return nil
+	}
+
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	validOffset, err := replayWALSegment(w.file, f)
+	if err != nil && !isWALRecordCorrupted(err) {
+		return fmt.Errorf("write-ahead log segment %s at offset %d: %v", w.segmentPath(w.segmentSeq), validOffset, err)
+	}
+	if err != nil {
+		log.Println(DYNAMO_SERVER, "Truncating write-ahead log segment", w.segmentPath(w.segmentSeq),
+			"at offset", validOffset, ":", err)
+		if err := w.file.Truncate(validOffset); err != nil {
+			return err
+		}
+	}
+
+	_, err = w.file.Seek(validOffset, io.SeekStart)
+	return err
+}
+
+// Appends the PutArgs to the active segment and flushes it to the disk
+func (w *WriteAheadLog) Append(putArgs PutArgs) error {
+	var payload bytes.Buffer
+	if err := gob.NewEncoder(&payload).Encode(putArgs); err != nil {
+		return err
+	}
+
+	record := make([]byte, walRecordHeaderSize+payload.Len())
+	binary.BigEndian.PutUint32(record[0:4], crc32.Checksum(payload.Bytes(), walCRCTable))
+	binary.BigEndian.PutUint32(record[4:8], uint32(payload.Len()))
+	copy(record[walRecordHeaderSize:], payload.Bytes())
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := w.file.Write(record); err != nil {
+		return err
+	}
+	return w.file.Sync()
+}
+
+// Closes the active segment and starts a new one
+// Returns the sequence number of the new segment, all records appended before the call are in older segments.
+func (w *WriteAheadLog) Rotate() (uint64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	file, err := os.OpenFile(w.segmentPath(w.segmentSeq+1), os.O_RDWR|os.O_CREATE|os.O_TRUNC, WAL_FILE_PERM)
+	if err != nil {
+		return 0, err
+	}
+	if err := w.file.Close(); err != nil {
+		log.Println(DYNAMO_SERVER, "Failed to close write-ahead log segment:", err)
+	}
+
+	w.file = file
+	w.segmentSeq++
+	return w.segmentSeq, nil
+}
+
+// Removes the segments with sequence number < seq
+// The active segment is never removed.
+func (w *WriteAheadLog) RemoveSegmentsBefore(seq uint64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	segments, err := listSequencedFiles(w.dir, WAL_SEGMENT_PATTERN)
+	if err != nil {
+		return err
+	}
+
+	for _, segmentSeq := range segments {
+		if segmentSeq >= seq || segmentSeq == w.segmentSeq {
+			continue
+		}
+		if err := os.Remove(w.segmentPath(segmentSeq)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Closes the active segment
+func (w *WriteAheadLog) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.file.Close()
+}
+
+// Calls f with every valid record read from the reader
+// Returns the size of the valid records in bytes, and a non-nil error if the replay stops at an invalid record.
+func replayWALSegment(reader io.Reader, f func(putArgs PutArgs)) (int64, error) {
+	bufferedReader := bufio.NewReader(reader)
+	validOffset := int64(0)
+	for {
+		putArgs, size, err := readWALRecord(bufferedReader)
+		if err == io.EOF {
+			return validOffset, nil
+		}
+		if err != nil {
+			return validOffset, err
+		}
+
+		f(putArgs)
+		validOffset += size
+	}
+}
+
+// Reads a single record from the reader
+// Returns the decoded PutArgs and the size of the record in bytes. Returns io.EOF if there are no more records.
+func readWALRecord(reader io.Reader) (PutArgs, int64, error) {
+	var putArgs PutArgs
+
+	header := make([]byte, walRecordHeaderSize)
+	if n, err := io.ReadFull(reader, header); err != nil {
+		if err == io.EOF && n == 0 {
+			return putArgs, 0, io.EOF
+		}
+		return putArgs, 0, errWALIncompleteHeader
+	}
+
+	checksum := binary.BigEndian.Uint32(header[0:4])
+	length := binary.BigEndian.Uint32(header[4:8])
+
+	// Copy instead of allocating the whole payload up front, the length itself may be corrupted
+	var payload bytes.Buffer
+	if _, err := io.CopyN(&payload, reader, int64(length)); err != nil {
+		return putArgs, 0, errWALIncompletePayload
+	}
+	if crc32.Checksum(payload.Bytes(), walCRCTable) != checksum {
+		return putArgs, 0, errWALChecksumMismatch
+	}
+	if err := gob.NewDecoder(&payload).Decode(&putArgs); err != nil {
+		return putArgs, 0, err
+	}
+
+	return putArgs, int64(walRecordHeaderSize) + int64(length), nil
+}
+
+// Returns true if the error is of a torn or corrupted record
+func isWALRecordCorrupted(err error) bool {
+	return err == errWALIncompleteHeader || err == errWALIncompletePayload || err == errWALChecksumMismatch
+}
+
+// Returns the sorted sequence numbers of the files in the directory whose names match the pattern
+// The pattern is a fmt format with a single %d verb, e.g. "wal-%016d.log"
+func listSequencedFiles(dir string, pattern string) ([]uint64, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	seqs := make([]uint64, 0)
+	for _, file := range files {
+		var seq uint64
+		if _, err := fmt.Sscanf(file.Name(), pattern, &seq); err != nil {
+			continue
+		}
+		if file.Name() == fmt.Sprintf(pattern, seq) {
+			seqs = append(seqs, seq)
+		}
+	}
+
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
@@ -0,0 +1,534 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: dynamo.proto
+
+package dynamopb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	MyDynamo_Put_FullMethodName                = "/mydynamo.MyDynamo/Put"
+	MyDynamo_Delete_FullMethodName             = "/mydynamo.MyDynamo/Delete"
+	MyDynamo_Get_FullMethodName                = "/mydynamo.MyDynamo/Get"
+	MyDynamo_PutRaw_FullMethodName             = "/mydynamo.MyDynamo/PutRaw"
+	MyDynamo_GetRaw_FullMethodName             = "/mydynamo.MyDynamo/GetRaw"
+	MyDynamo_PutRawStream_FullMethodName       = "/mydynamo.MyDynamo/PutRawStream"
+	MyDynamo_Gossip_FullMethodName             = "/mydynamo.MyDynamo/Gossip"
+	MyDynamo_Crash_FullMethodName              = "/mydynamo.MyDynamo/Crash"
+	MyDynamo_ForceCrash_FullMethodName         = "/mydynamo.MyDynamo/ForceCrash"
+	MyDynamo_ForceRestore_FullMethodName       = "/mydynamo.MyDynamo/ForceRestore"
+	MyDynamo_SendPreferenceList_FullMethodName = "/mydynamo.MyDynamo/SendPreferenceList"
+)
+
+// MyDynamoClient is the client API for MyDynamo service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MyDynamoClient interface {
+	// Puts the entry to this server and replicates it to W-1 other servers
+	Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutResponse, error)
+	// Puts a tombstone deleting the entries older than the context to this server and replicates it to W-1 other servers
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*PutResponse, error)
+	// Gets the entries of the key from R servers, merged by their vector clocks
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
+	// Puts the entry to this server only
+	PutRaw(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutRawResponse, error)
+	// Gets the entries of the key from this server only
+	GetRaw(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
+	// Puts a stream of entries to this server only, responding to each entry in order
+	PutRawStream(ctx context.Context, opts ...grpc.CallOption) (MyDynamo_PutRawStreamClient, error)
+	// Makes this server gossip its entries to the other servers
+	Gossip(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	// Emulates a crash of this server for the given seconds
+	Crash(ctx context.Context, in *CrashRequest, opts ...grpc.CallOption) (*CrashResponse, error)
+	// Emulates a crash of this server until it is restored
+	ForceCrash(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	// Restores this server from an emulated crash
+	ForceRestore(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	// Sets the nodes in the cluster, starting with this server
+	SendPreferenceList(ctx context.Context, in *NodeList, opts ...grpc.CallOption) (*Empty, error)
+}
+
+type myDynamoClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMyDynamoClient(cc grpc.ClientConnInterface) MyDynamoClient {
+	return &myDynamoClient{cc}
+}
+
+func (c *myDynamoClient) Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutResponse, error) {
+	out := new(PutResponse)
+	err := c.cc.Invoke(ctx, MyDynamo_Put_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *myDynamoClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*PutResponse, error) {
+	out := new(PutResponse)
+	err := c.cc.Invoke(ctx, MyDynamo_Delete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *myDynamoClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	out := new(GetResponse)
+	err := c.cc.Invoke(ctx, MyDynamo_Get_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *myDynamoClient) PutRaw(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutRawResponse, error) {
+	out := new(PutRawResponse)
+	err := c.cc.Invoke(ctx, MyDynamo_PutRaw_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *myDynamoClient) GetRaw(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	out := new(GetResponse)
+	err := c.cc.Invoke(ctx, MyDynamo_GetRaw_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *myDynamoClient) PutRawStream(ctx context.Context, opts ...grpc.CallOption) (MyDynamo_PutRawStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &MyDynamo_ServiceDesc.Streams[0], MyDynamo_PutRawStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &myDynamoPutRawStreamClient{stream}
+	return x, nil
+}
+
+type MyDynamo_PutRawStreamClient interface {
+	Send(*PutRequest) error
+	Recv() (*PutRawResponse, error)
+	grpc.ClientStream
+}
+
+type myDynamoPutRawStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *myDynamoPutRawStreamClient) Send(m *PutRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *myDynamoPutRawStreamClient) Recv() (*PutRawResponse, error) {
+	m := new(PutRawResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *myDynamoClient) Gossip(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, MyDynamo_Gossip_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *myDynamoClient) Crash(ctx context.Context, in *CrashRequest, opts ...grpc.CallOption) (*CrashResponse, error) {
+	out := new(CrashResponse)
+	err := c.cc.Invoke(ctx, MyDynamo_Crash_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *myDynamoClient) ForceCrash(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, MyDynamo_ForceCrash_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *myDynamoClient) ForceRestore(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, MyDynamo_ForceRestore_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *myDynamoClient) SendPreferenceList(ctx context.Context, in *NodeList, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, MyDynamo_SendPreferenceList_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MyDynamoServer is the server API for MyDynamo service.
+// All implementations must embed UnimplementedMyDynamoServer
+// for forward compatibility
+type MyDynamoServer interface {
+	// Puts the entry to this server and replicates it to W-1 other servers
+	Put(context.Context, *PutRequest) (*PutResponse, error)
+	// Puts a tombstone deleting the entries older than the context to this server and replicates it to W-1 other servers
+	Delete(context.Context, *DeleteRequest) (*PutResponse, error)
+	// Gets the entries of the key from R servers, merged by their vector clocks
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	// Puts the entry to this server only
+	PutRaw(context.Context, *PutRequest) (*PutRawResponse, error)
+	// Gets the entries of the key from this server only
+	GetRaw(context.Context, *GetRequest) (*GetResponse, error)
+	// Puts a stream of entries to this server only, responding to each entry in order
+	PutRawStream(MyDynamo_PutRawStreamServer) error
+	// Makes this server gossip its entries to the other servers
+	Gossip(context.Context, *Empty) (*Empty, error)
+	// Emulates a crash of this server for the given seconds
+	Crash(context.Context, *CrashRequest) (*CrashResponse, error)
+	// Emulates a crash of this server until it is restored
+	ForceCrash(context.Context, *Empty) (*Empty, error)
+	// Restores this server from an emulated crash
+	ForceRestore(context.Context, *Empty) (*Empty, error)
+	// Sets the nodes in the cluster, starting with this server
+	SendPreferenceList(context.Context, *NodeList) (*Empty, error)
+	mustEmbedUnimplementedMyDynamoServer()
+}
+
+// UnimplementedMyDynamoServer must be embedded to have forward compatible implementations.
+type UnimplementedMyDynamoServer struct {
+}
+
+func (UnimplementedMyDynamoServer) Put(context.Context, *PutRequest) (*PutResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Put not implemented")
+}
+func (UnimplementedMyDynamoServer) Delete(context.Context, *DeleteRequest) (*PutResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedMyDynamoServer) Get(context.Context, *GetRequest) (*GetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedMyDynamoServer) PutRaw(context.Context, *PutRequest) (*PutRawResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutRaw not implemented")
+}
+func (UnimplementedMyDynamoServer) GetRaw(context.Context, *GetRequest) (*GetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRaw not implemented")
+}
+func (UnimplementedMyDynamoServer) PutRawStream(MyDynamo_PutRawStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method PutRawStream not implemented")
+}
+func (UnimplementedMyDynamoServer) Gossip(context.Context, *Empty) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Gossip not implemented")
+}
+func (UnimplementedMyDynamoServer) Crash(context.Context, *CrashRequest) (*CrashResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Crash not implemented")
+}
+func (UnimplementedMyDynamoServer) ForceCrash(context.Context, *Empty) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ForceCrash not implemented")
+}
+func (UnimplementedMyDynamoServer) ForceRestore(context.Context, *Empty) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ForceRestore not implemented")
+}
+func (UnimplementedMyDynamoServer) SendPreferenceList(context.Context, *NodeList) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendPreferenceList not implemented")
+}
+func (UnimplementedMyDynamoServer) mustEmbedUnimplementedMyDynamoServer() {}
+
+// UnsafeMyDynamoServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MyDynamoServer will
+// result in compilation errors.
+type UnsafeMyDynamoServer interface {
+	mustEmbedUnimplementedMyDynamoServer()
+}
+
+func RegisterMyDynamoServer(s grpc.ServiceRegistrar, srv MyDynamoServer) {
+	s.RegisterService(&MyDynamo_ServiceDesc, srv)
+}
+
+func _MyDynamo_Put_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyDynamoServer).Put(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MyDynamo_Put_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyDynamoServer).Put(ctx, req.(*PutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MyDynamo_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyDynamoServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MyDynamo_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyDynamoServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MyDynamo_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyDynamoServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MyDynamo_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyDynamoServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MyDynamo_PutRaw_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyDynamoServer).PutRaw(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MyDynamo_PutRaw_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyDynamoServer).PutRaw(ctx, req.(*PutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MyDynamo_GetRaw_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyDynamoServer).GetRaw(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MyDynamo_GetRaw_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyDynamoServer).GetRaw(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MyDynamo_PutRawStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MyDynamoServer).PutRawStream(&myDynamoPutRawStreamServer{stream})
+}
+
+type MyDynamo_PutRawStreamServer interface {
+	Send(*PutRawResponse) error
+	Recv() (*PutRequest, error)
+	grpc.ServerStream
+}
+
+type myDynamoPutRawStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *myDynamoPutRawStreamServer) Send(m *PutRawResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *myDynamoPutRawStreamServer) Recv() (*PutRequest, error) {
+	m := new(PutRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _MyDynamo_Gossip_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyDynamoServer).Gossip(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MyDynamo_Gossip_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyDynamoServer).Gossip(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MyDynamo_Crash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CrashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyDynamoServer).Crash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MyDynamo_Crash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyDynamoServer).Crash(ctx, req.(*CrashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MyDynamo_ForceCrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyDynamoServer).ForceCrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MyDynamo_ForceCrash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyDynamoServer).ForceCrash(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MyDynamo_ForceRestore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyDynamoServer).ForceRestore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MyDynamo_ForceRestore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyDynamoServer).ForceRestore(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MyDynamo_SendPreferenceList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NodeList)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MyDynamoServer).SendPreferenceList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MyDynamo_SendPreferenceList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MyDynamoServer).SendPreferenceList(ctx, req.(*NodeList))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// MyDynamo_ServiceDesc is the grpc.ServiceDesc for MyDynamo service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MyDynamo_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mydynamo.MyDynamo",
+	HandlerType: (*MyDynamoServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Put",
+			Handler:    _MyDynamo_Put_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _MyDynamo_Delete_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _MyDynamo_Get_Handler,
+		},
+		{
+			MethodName: "PutRaw",
+			Handler:    _MyDynamo_PutRaw_Handler,
+		},
+		{
+			MethodName: "GetRaw",
+			Handler:    _MyDynamo_GetRaw_Handler,
+		},
+		{
+			MethodName: "Gossip",
+			Handler:    _MyDynamo_Gossip_Handler,
+		},
+		{
+			MethodName: "Crash",
+			Handler:    _MyDynamo_Crash_Handler,
+		},
+		{
+			MethodName: "ForceCrash",
+			Handler:    _MyDynamo_ForceCrash_Handler,
+		},
+		{
+			MethodName: "ForceRestore",
+			Handler:    _MyDynamo_ForceRestore_Handler,
+		},
+		{
+			MethodName: "SendPreferenceList",
+			Handler:    _MyDynamo_SendPreferenceList_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PutRawStream",
+			Handler:       _MyDynamo_PutRawStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "dynamo.proto",
+}
@@ -0,0 +1,4 @@
+// Package dynamopb has the protobuf messages and the gRPC service of a Dynamo server, generated from dynamo.proto.
+package dynamopb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative dynamo.proto
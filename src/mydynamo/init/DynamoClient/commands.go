@@ -0,0 +1,323 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mydynamo"
+	"strconv"
+	"strings"
+)
+
+// Runs the commands of the command-line tool on the server the client is connected to
+type commandLine struct {
+	client     *mydynamo.RPCClient
+	jsonOutput bool
+	out        io.Writer
+}
+
+// A value of a key with its context
+type sibling struct {
+	Value   string            `json:"value"`
+	Context map[string]uint64 `json:"context"`
+}
+
+// Result of the get command
+type getOutput struct {
+	Key      string                 `json:"key"`
+	Values   []string               `json:"values"`
+	Context  map[string]uint64      `json:"context"`
+	Failures []mydynamo.NodeFailure `json:"failures,omitempty"`
+}
+
+// Result of the siblings command
+type siblingsOutput struct {
+	Key      string                 `json:"key"`
+	Siblings []sibling              `json:"siblings"`
+	Failures []mydynamo.NodeFailure `json:"failures,omitempty"`
+}
+
+// Result of the put and resolve commands
+type putOutput struct {
+	Key      string                 `json:"key"`
+	Success  bool                   `json:"success"`
+	Acks     int                    `json:"acks"`
+	Failures []mydynamo.NodeFailure `json:"failures,omitempty"`
+}
+
+// Result of the commands without a result
+type okOutput struct {
+	OK bool `json:"ok"`
+}
+
+// Result of a failed command
+type errorOutput struct {
+	Error string `json:"error"`
+}
+
+// Runs the command given as its name followed by its arguments, and returns true if it succeeded
+func (c *commandLine) run(args []string) bool {
+	name, args := args[0], args[1:]
+	switch name {
+	case "get":
+		if len(args) != 1 {
+			return c.usage("get <key>")
+		}
+		return c.get(args[0])
+	case "put":
+		args, clock, hasContext, err := parseContextFlag(args)
+		if err != nil {
+			return c.fail(err.Error())
+		}
+		if len(args) < 2 {
+			return c.usage("put <key> <value> [--context <context>]")
+		}
+		if !hasContext {
+			clock = mydynamo.NewVectorClock()
+		}
+		return c.putWithClock(args[0], strings.Join(args[1:], " "), clock)
+	case "siblings":
+		if len(args) != 1 {
+			return c.usage("siblings <key>")
+		}
+		return c.siblings(args[0])
+	case "resolve":
+		if len(args) < 2 {
+			return c.usage("resolve <key> <value>")
+		}
+		return c.resolve(args[0], strings.Join(args[1:], " "))
+	case "delete":
+		args, clock, hasContext, err := parseContextFlag(args)
+		if err != nil {
+			return c.fail(err.Error())
+		}
+		if len(args) != 1 {
+			return c.usage("delete <key> [--context <context>]")
+		}
+		if !hasContext {
+			return c.delete(args[0], nil)
+		}
+		return c.delete(args[0], &clock)
+	case "gossip":
+		return c.ok("gossip", c.client.TryGossip())
+	case "crash":
+		return c.crash(args)
+	case "restore":
+		return c.ok("restore", c.client.TryForceRestore())
+	case "help":
+		fmt.Fprint(c.out, USAGE_STRING)
+		return true
+	default:
+		return c.fail(fmt.Sprintf("unknown command %q, run \"help\" for the list of commands", name))
+	}
+}
+
+// Prints the values of the key and the context combining their clocks
+func (c *commandLine) get(key string) bool {
+	result, err := c.client.TryGet(key)
+	if err != nil {
+		return c.fail(fmt.Sprintf("get failed: %v", err))
+	}
+
+	output := getOutput{Key: key, Values: make([]string, 0), Context: combineClocks(result.EntryList).NodeClocks}
+	for _, entry := range result.EntryList {
+		output.Values = append(output.Values, string(entry.Value))
+	}
+	output.Failures = result.Failures
+
+	if c.jsonOutput {
+		return c.printJSON(output)
+	}
+	if len(output.Values) == 0 {
+		fmt.Fprintln(c.out, "(not found)")
+	}
+	for _, value := range output.Values {
+		fmt.Fprintln(c.out, value)
+	}
+	fmt.Fprintln(c.out, "context:", clockJSON(output.Context))
+	c.printFailures(output.Failures)
+	return true
+}
+
+// Prints the values of the key, each with its own context
+func (c *commandLine) siblings(key string) bool {
+	result, err := c.client.TryGet(key)
+	if err != nil {
+		return c.fail(fmt.Sprintf("siblings failed: %v", err))
+	}
+
+	output := siblingsOutput{Key: key, Siblings: make([]sibling, 0), Failures: result.Failures}
+	for _, entry := range result.EntryList {
+		output.Siblings = append(output.Siblings, sibling{Value: string(entry.Value), Context: entry.Context.Version().NodeClocks})
+	}
+
+	if c.jsonOutput {
+		return c.printJSON(output)
+	}
+	fmt.Fprintln(c.out, len(output.Siblings), "siblings")
+	for _, s := range output.Siblings {
+		fmt.Fprintln(c.out, s.Value, clockJSON(s.Context))
+	}
+	c.printFailures(output.Failures)
+	return true
+}
+
+// Puts the value with the context combining the clocks of all siblings of the key
+func (c *commandLine) resolve(key string, value string) bool {
+	result, err := c.client.TryGet(key)
+	if err != nil {
+		return c.fail(fmt.Sprintf("resolve failed: %v", err))
+	}
+	return c.putWithClock(key, value, combineClocks(result.EntryList))
+}
+
+// Deletes the values of the key older than the clock, or all values of the key if the clock is nil
+func (c *commandLine) delete(key string, clock *mydynamo.VectorClock) bool {
+	if clock == nil {
+		result, err := c.client.TryGet(key)
+		if err != nil {
+			return c.fail(fmt.Sprintf("delete failed: %v", err))
+		}
+		combinedClock := combineClocks(result.EntryList)
+		clock = &combinedClock
+	}
+
+	success, err := c.client.TryDelete(mydynamo.NewDeleteArgs(key, mydynamo.NewContext(*clock)))
+	if err == nil && !success {
+		return c.fail("delete failed")
+	}
+	return c.ok("delete", err)
+}
+
+// Emulates a crash of the server, for the seconds if given
+func (c *commandLine) crash(args []string) bool {
+	if len(args) > 1 {
+		return c.usage("crash [seconds]")
+	}
+	if len(args) == 0 {
+		return c.ok("crash", c.client.TryForceCrash())
+	}
+
+	seconds, err := strconv.Atoi(args[0])
+	if err != nil {
+		return c.fail(fmt.Sprintf("invalid seconds %q: %v", args[0], err))
+	}
+	success, err := c.client.TryCrash(seconds)
+	if err == nil && !success {
+		return c.fail("crash failed")
+	}
+	return c.ok("crash", err)
+}
+
+// Puts the value with the clock as its context and prints the result
+func (c *commandLine) putWithClock(key string, value string, clock mydynamo.VectorClock) bool {
+	result, err := c.client.TryPutWithResult(mydynamo.NewPutArgs(key, mydynamo.NewContext(clock), []byte(value)))
+	if err != nil {
+		return c.fail(fmt.Sprintf("put failed: %v", err))
+	}
+
+	output := putOutput{Key: key, Success: result.Success, Acks: result.Acks, Failures: result.Failures}
+	if c.jsonOutput {
+		c.printJSON(output)
+	} else {
+		fmt.Fprintln(c.out, "success:", output.Success, "acks:", output.Acks)
+		c.printFailures(output.Failures)
+	}
+	return result.Success
+}
+
+// Prints that the command succeeded, or that it failed with the error of its call if there is one
+func (c *commandLine) ok(name string, err error) bool {
+	if err != nil {
+		return c.fail(fmt.Sprintf("%s failed: %v", name, err))
+	}
+
+	if c.jsonOutput {
+		return c.printJSON(okOutput{OK: true})
+	}
+	fmt.Fprintln(c.out, "ok")
+	return true
+}
+
+// Prints the usage of a command and returns false
+func (c *commandLine) usage(usage string) bool {
+	return c.fail("usage: " + usage)
+}
+
+// Prints the error and returns false
+func (c *commandLine) fail(message string) bool {
+	if c.jsonOutput {
+		c.printJSON(errorOutput{Error: message})
+	} else {
+		fmt.Fprintln(c.out, "error:", message)
+	}
+	return false
+}
+
+// Prints the nodes that failed during a get or put
+func (c *commandLine) printFailures(failures []mydynamo.NodeFailure) {
+	for _, failure := range failures {
+		fmt.Fprintln(c.out, "failed:", failure.Node.Address+":"+failure.Node.Port, failure.Reason)
+	}
+}
+
+// Prints the output as a line of JSON and returns true
+func (c *commandLine) printJSON(output interface{}) bool {
+	data, err := json.Marshal(output)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprintln(c.out, string(data))
+	return true
+}
+
+// Removes the context flag from the arguments of a command, and returns the other arguments and the clock of the context
+// The flag can be given anywhere in the arguments, as `--context <context>` or `--context=<context>`. The returned
+// bool is whether the flag was given.
+func parseContextFlag(args []string) ([]string, mydynamo.VectorClock, bool, error) {
+	clock := mydynamo.NewVectorClock()
+	otherArgs := make([]string, 0, len(args))
+	var context string
+	hasContext := false
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == CONTEXT_FLAG:
+			if i+1 == len(args) {
+				return nil, clock, false, fmt.Errorf("missing context after %s", CONTEXT_FLAG)
+			}
+			i++
+			context = args[i]
+		case strings.HasPrefix(args[i], CONTEXT_FLAG+"="):
+			context = strings.TrimPrefix(args[i], CONTEXT_FLAG+"=")
+		default:
+			otherArgs = append(otherArgs, args[i])
+			continue
+		}
+		if hasContext {
+			return nil, clock, false, fmt.Errorf("%s given more than once", CONTEXT_FLAG)
+		}
+		hasContext = true
+	}
+
+	if hasContext {
+		if err := json.Unmarshal([]byte(context), &clock.NodeClocks); err != nil {
+			return nil, clock, false, fmt.Errorf("invalid context %q: %v", context, err)
+		}
+	}
+	return otherArgs, clock, hasContext, nil
+}
+
+// Returns the vector clock combining the clocks of the entries
+func combineClocks(entries []mydynamo.ObjectEntry) mydynamo.VectorClock {
+	clock := mydynamo.NewVectorClock()
+	for _, entry := range entries {
+		clock.Combine([]mydynamo.VectorClock{entry.Context.Version()})
+	}
+	return clock
+}
+
+// Returns the JSON of the node clocks of a vector clock, which is the context given to the put command
+func clockJSON(nodeClocks map[string]uint64) string {
+	clock := mydynamo.VectorClock{NodeClocks: nodeClocks}
+	return clock.ToJSON()
+}
@@ -1,21 +1,151 @@
 package main
 
 import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
 	"mydynamo"
-	"strconv"
+	"os"
+	"strings"
+	"unicode"
 )
 
+const (
+	DEFAULT_SERVER_ADDRESS = "localhost:8080"
+	EX_FAILURE             = 1
+	PROMPT                 = "dynamo> "
+	CONTEXT_FLAG           = "--context"
+)
+
+const USAGE_STRING = `usage: DynamoClient [-server address:port] [-json] [command [args...]]
+
+Runs the command on the server, or reads commands line by line from the standard input if no command is given.
+
+Commands:
+  get <key>                        Get the values of the key and the context combining their clocks
+  put <key> <value> [--context <context>]
+                                   Put the value, with the context of a previous get if given
+  siblings <key>                   Get the values of the key, each with its own context
+  resolve <key> <value>            Put the value with the context combining the clocks of all siblings
+  delete <key> [--context <context>]
+                                   Delete the values older than the context, or all values if no context is given
+  gossip                           Make the server gossip with the other servers
+  crash [seconds]                  Emulate a crash of the server, for the seconds if given
+  restore                          Restore the server from an emulated crash
+  help                             Print this message
+  exit                             Exit the interactive mode
+
+Contexts are printed and given as the JSON of their vector clocks, e.g. {"s0":2,"s1":1}.
+Words of the commands read from the standard input can be quoted like in a shell, e.g.
+  put k1 'hello world' --context '{"s0": 1}'
+`
+
 func main() {
-	//Spin up a client for some testing
-	//Expects server to be started, starting at port 8080
-	serverPort := 8080
+	serverAddress := flag.String("server", DEFAULT_SERVER_ADDRESS, "address of the server to connect to")
+	jsonOutput := flag.Bool("json", false, "print the results as JSON")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), USAGE_STRING)
+	}
+	flag.Parse()
+
+	clientInstance := mydynamo.NewDynamoRPCClient(*serverAddress)
+	if err := clientInstance.RpcConnect(); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to connect to", *serverAddress, ":", err)
+		os.Exit(EX_FAILURE)
+	}
+	defer clientInstance.CleanConn()
 
-	//this client connects to the server on port 8080
-	clientInstance := mydynamo.NewDynamoRPCClient("localhost:" + strconv.Itoa(serverPort+0))
+	cli := &commandLine{client: clientInstance, jsonOutput: *jsonOutput, out: os.Stdout}
 
-	if err := clientInstance.CleanAndConn(); err != nil {
-		panic(err)
+	// Run a single command
+	if flag.NArg() > 0 {
+		if !cli.run(flag.Args()) {
+			clientInstance.CleanConn()
+			os.Exit(EX_FAILURE)
+		}
+		return
 	}
 
-	//You can use the space below to write some operations that you want your client to do
+	// Read the commands line by line, only prompting for them on a terminal
+	stat, _ := os.Stdin.Stat()
+	interactive := stat != nil && stat.Mode()&os.ModeCharDevice != 0
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		if interactive {
+			fmt.Print(PROMPT)
+		}
+		if !scanner.Scan() {
+			break
+		}
+
+		args, err := splitWords(scanner.Text())
+		if err != nil {
+			cli.fail(err.Error())
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if args[0] == "exit" || args[0] == "quit" {
+			break
+		}
+		cli.run(args)
+	}
+}
+
+// Splits the line into words like a shell
+// Words are separated by spaces, which are kept in words quoted with single or double quotes. A backslash outside
+// single quotes escapes the next character.
+func splitWords(line string) ([]string, error) {
+	words := make([]string, 0)
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case quote == '"':
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, errors.New("unterminated backslash escape")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
 }
@@ -1,13 +1,16 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"mydynamo"
 	"net/rpc"
 	"os"
+	"os/signal"
 	"strconv"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/go-ini/ini"
@@ -35,20 +38,27 @@ func main() {
 	// Load the detailed configuration from section "mydynamo"
 	dynamoConfigs := configContent.Section(mydynamo.MYDYNAMO)
 
-	serverPort, _ := dynamoConfigs.Key(mydynamo.SERVER_PORT).Int()
-	r_value, _ := dynamoConfigs.Key(mydynamo.R_VALUE).Int()
-	w_value, _ := dynamoConfigs.Key(mydynamo.W_VALUE).Int()
-	cluster_size, err := dynamoConfigs.Key(mydynamo.CLUSTER_SIZE).Int()
-	if err != nil {
+	config := mydynamo.NewDynamoConfig()
+	for _, key := range dynamoConfigs.Keys() {
+		if err = config.SetOption(key.Name(), key.Value()); err != nil {
+			log.Println(err)
+			log.Println("Failed to load config file, field is wrong type:", configFilePath)
+			log.Println(mydynamo.USAGE_STRING)
+			os.Exit(mydynamo.EX_CONFIG)
+		}
+	}
+	if err = config.Validate(); err != nil {
 		log.Println(err)
-		log.Println("Failed to load config file, field is wrong type:", configFilePath)
+		log.Println("Invalid configurations in config file:", configFilePath)
 		log.Println(mydynamo.USAGE_STRING)
 		os.Exit(mydynamo.EX_CONFIG)
 	}
+	serverPort := config.StartingPort
+	cluster_size := config.ClusterSize
 	fmt.Println("Done loading configurations")
 
 	//keep a list of servers so we can communicate with them
-	// serverList := make([]mydynamo.DynamoServer, 0)
+	serverList := make([]mydynamo.DynamoServer, 0)
 
 	//spin up a dynamo cluster
 	dynamoNodeList := make([]mydynamo.DynamoNode, 0)
@@ -59,12 +69,14 @@ func main() {
 	for idx := 0; idx < cluster_size; idx++ {
 
 		//Create a server instance
-		serverInstance := mydynamo.NewDynamoServer(w_value, r_value, "localhost", strconv.Itoa(serverPort+idx), strconv.Itoa(idx))
-		// serverList = append(serverList, serverInstance)
+		serverInstance := mydynamo.NewDynamoServerWithConfig(config, "localhost", strconv.Itoa(serverPort+idx), strconv.Itoa(idx))
+		serverList = append(serverList, serverInstance)
 
 		//Create an anonymous function in a goroutine that starts the server
 		go func() {
-			log.Fatal(mydynamo.ServeDynamoServer(serverInstance))
+			if err := mydynamo.ServeDynamoServer(serverInstance); err != nil {
+				log.Fatal(err)
+			}
 			wg.Done()
 		}()
 		nodeInfo := mydynamo.DynamoNode{
@@ -82,20 +94,40 @@ func main() {
 
 	//Send the preference list to all servers
 	for _, info := range dynamoNodeList {
-		var empty mydynamo.Empty
-		c, _ := rpc.DialHTTP("tcp", info.Address+":"+info.Port)
-		if err != nil {
-			log.Println("Failed to send preference list")
+		if config.Transport == mydynamo.TRANSPORT_GRPC {
+			//Servers serving gRPC only are sent their preference list through gRPC
+			c := mydynamo.NewDynamoGRPCClient(config.GRPCAddressOf(info))
+			if err := c.SendPreferenceList(context.Background(), nodePreferenceList); err != nil {
+				log.Println("Failed to send preference list")
+			}
+			_ = c.Close()
 		} else {
-			err2 := c.Call("MyDynamo.SendPreferenceList", nodePreferenceList, &empty)
-			if err2 != nil {
+			var empty mydynamo.Empty
+			c, _ := rpc.DialHTTP("tcp", info.Address+":"+info.Port)
+			if err != nil {
 				log.Println("Failed to send preference list")
+			} else {
+				err2 := c.Call("MyDynamo.SendPreferenceList", nodePreferenceList, &empty)
+				if err2 != nil {
+					log.Println("Failed to send preference list")
+				}
 			}
 		}
 		nodePreferenceList = mydynamo.RotateServerList(nodePreferenceList)
 	}
 	/*---------------------------------------------*/
 
+	//Shut down all servers on interrupt, so they stop their background loops and close their storage
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		log.Println("Shutting down servers")
+		for i := range serverList {
+			serverList[i].Shutdown()
+		}
+	}()
+
 	//wait for all servers to finish
 	wg.Wait()
 }
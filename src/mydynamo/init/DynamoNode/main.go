@@ -0,0 +1,137 @@
+package main
+
+import (
+	"log"
+	"mydynamo"
+	"net"
+	"net/rpc"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-ini/ini"
+)
+
+// Starts a single Dynamo server, which joins the cluster of its seeds
+// The first seed starts a new cluster if none of the other seeds is in a cluster yet. The other servers retry joining
+// the seeds until one of them is in a cluster.
+func main() {
+	var err error
+	/*-----------------------------*/
+	if len(os.Args) < mydynamo.NODE_ARG_COUNT {
+		log.Println(mydynamo.NODE_USAGE_STRING)
+		os.Exit(mydynamo.EX_USAGE)
+	}
+
+	// Load the configuration file
+	configFilePath := os.Args[mydynamo.CONFIG_FILE_INDEX]
+	configContent, err := ini.Load(configFilePath)
+	if err != nil {
+		log.Println(err)
+		log.Println("Failed to load config file:", configFilePath)
+		log.Println(mydynamo.NODE_USAGE_STRING)
+		os.Exit(mydynamo.EX_CONFIG)
+	}
+
+	// Load the detailed configuration from section "mydynamo"
+	dynamoConfigs := configContent.Section(mydynamo.MYDYNAMO)
+
+	config := mydynamo.NewDynamoConfig()
+	for _, key := range dynamoConfigs.Keys() {
+		if err = config.SetOption(key.Name(), key.Value()); err != nil {
+			log.Println(err)
+			log.Println("Failed to load config file, field is wrong type:", configFilePath)
+			log.Println(mydynamo.NODE_USAGE_STRING)
+			os.Exit(mydynamo.EX_CONFIG)
+		}
+	}
+	if err = config.Validate(); err != nil {
+		log.Println(err)
+		log.Println("Invalid configurations in config file:", configFilePath)
+		log.Println(mydynamo.NODE_USAGE_STRING)
+		os.Exit(mydynamo.EX_CONFIG)
+	}
+
+	// Load the address of this server and its seeds
+	id := os.Args[mydynamo.NODE_ID_INDEX]
+	self, err := parseNode(os.Args[mydynamo.NODE_ADDRESS_INDEX])
+	if err != nil {
+		log.Println(err)
+		log.Println(mydynamo.NODE_USAGE_STRING)
+		os.Exit(mydynamo.EX_USAGE)
+	}
+	seeds := make([]mydynamo.DynamoNode, 0)
+	for _, arg := range os.Args[mydynamo.NODE_SEEDS_INDEX:] {
+		seed, err := parseNode(arg)
+		if err != nil {
+			log.Println(err)
+			log.Println(mydynamo.NODE_USAGE_STRING)
+			os.Exit(mydynamo.EX_USAGE)
+		}
+		seeds = append(seeds, seed)
+		if seed != self && config.VirtualNodes <= 0 {
+			log.Println("Joining a cluster requires config label", mydynamo.VIRTUAL_NODES)
+			os.Exit(mydynamo.EX_CONFIG)
+		}
+	}
+
+	server := mydynamo.NewDynamoServerWithConfig(config, self.Address, self.Port, id)
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- mydynamo.ServeDynamoServer(server)
+	}()
+
+	//Shut down the server on interrupt, so it stops its background loops and closes its storage
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		log.Println("Shutting down server")
+		server.Shutdown()
+	}()
+
+	//Join the cluster of the seeds, or start a new cluster if this server is the first seed
+	go func() {
+		client := dialServer(self)
+		defer client.Close()
+		for {
+			var empty mydynamo.Empty
+			if err := client.Call("MyDynamo.JoinSeeds", seeds, &empty); err == nil {
+				return
+			}
+			if len(seeds) == 0 || seeds[0] == self {
+				if err := client.Call("MyDynamo.SendPreferenceList", []mydynamo.DynamoNode{self}, &empty); err != nil {
+					log.Println("Failed to start a new cluster:", err)
+					return
+				}
+				log.Println("Started a new cluster")
+				return
+			}
+			time.Sleep(time.Duration(mydynamo.JOIN_RETRY_INTERVAL_MS) * time.Millisecond)
+		}
+	}()
+
+	if err = <-serveErr; err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Parses a node from its "address:port"
+func parseNode(addressAndPort string) (mydynamo.DynamoNode, error) {
+	address, port, err := net.SplitHostPort(addressAndPort)
+	if err != nil {
+		return mydynamo.DynamoNode{}, err
+	}
+	return mydynamo.NewDynamoNode(address, port), nil
+}
+
+// Waits until the server accepts connections, and returns a connection to the server
+func dialServer(node mydynamo.DynamoNode) *rpc.Client {
+	for {
+		if client, err := rpc.DialHTTP("tcp", node.Address+":"+node.Port); err == nil {
+			return client
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"mydynamo"
 	"net/rpc"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -17,6 +19,7 @@ const (
 	CONFIG_R_VALUE_ARG_INDEX       = 2
 	CONFIG_W_VALUE_ARG_INDEX       = 3
 	CONFIG_CLUSTER_SIZE_ARG_INDEX  = 4
+	CONFIG_OPTIONS_ARG_INDEX       = 5
 	SERVER_STARTUP_WAIT_SECONDS    = 2
 )
 
@@ -24,7 +27,7 @@ func main() {
 	var err error
 	/*-----------------------------*/
 	// When the input argument is less than 1
-	if len(os.Args) != ARG_COUNT {
+	if len(os.Args) < ARG_COUNT {
 		log.Println(mydynamo.USAGE_STRING)
 		os.Exit(mydynamo.EX_USAGE)
 	}
@@ -36,7 +39,28 @@ func main() {
 	config["w_value"], _ = strconv.Atoi(os.Args[CONFIG_W_VALUE_ARG_INDEX])
 	config["cluster_size"], _ = strconv.Atoi(os.Args[CONFIG_CLUSTER_SIZE_ARG_INDEX])
 
-	fmt.Println("Done loading configurations: ", config)
+	// Load the optional configurations given as "label=value"
+	dynamoConfig := mydynamo.NewDynamoConfig()
+	dynamoConfig.RValue = config["r_value"]
+	dynamoConfig.WValue = config["w_value"]
+	dynamoConfig.ClusterSize = config["cluster_size"]
+	for _, option := range os.Args[CONFIG_OPTIONS_ARG_INDEX:] {
+		labelAndValue := strings.SplitN(option, "=", 2)
+		if len(labelAndValue) != 2 {
+			log.Println("Invalid option:", option)
+			os.Exit(mydynamo.EX_USAGE)
+		}
+		if err = dynamoConfig.SetOption(labelAndValue[0], labelAndValue[1]); err != nil {
+			log.Println(err)
+			os.Exit(mydynamo.EX_CONFIG)
+		}
+	}
+	if err = dynamoConfig.Validate(); err != nil {
+		log.Println(err)
+		os.Exit(mydynamo.EX_CONFIG)
+	}
+
+	fmt.Println("Done loading configurations: ", config, os.Args[CONFIG_OPTIONS_ARG_INDEX:])
 
 	//keep a list of servers so we can communicate with them
 	// serverList := make([]mydynamo.DynamoServer, 0)
@@ -50,14 +74,15 @@ func main() {
 	for idx := 0; idx < config["cluster_size"]; idx++ {
 
 		//Create a server instance
-		serverInstance := mydynamo.NewDynamoServer(
-			config["w_value"], config["r_value"], "localhost", strconv.Itoa(config["starting_port"]+idx),
-			"s"+strconv.Itoa(idx))
+		serverInstance := mydynamo.NewDynamoServerWithConfig(
+			dynamoConfig, "localhost", strconv.Itoa(config["starting_port"]+idx), "s"+strconv.Itoa(idx))
 		// serverList = append(serverList, serverInstance)
 
 		//Create an anonymous function in a goroutine that starts the server
 		go func() {
-			log.Fatal(mydynamo.ServeDynamoServer(serverInstance))
+			if err := mydynamo.ServeDynamoServer(serverInstance); err != nil {
+				log.Fatal(err)
+			}
 			wg.Done()
 		}()
 		nodeInfo := mydynamo.DynamoNode{
@@ -76,14 +101,23 @@ func main() {
 
 	//Send the preference list to all servers
 	for _, info := range dynamoNodeList {
-		var empty mydynamo.Empty
-		c, _ := rpc.DialHTTP("tcp", info.Address+":"+info.Port)
-		if err != nil {
-			log.Println("Failed to send preference list")
+		if dynamoConfig.Transport == mydynamo.TRANSPORT_GRPC {
+			//Servers serving gRPC only are sent their preference list through gRPC
+			c := mydynamo.NewDynamoGRPCClient(dynamoConfig.GRPCAddressOf(info))
+			if err := c.SendPreferenceList(context.Background(), nodePreferenceList); err != nil {
+				log.Println("Failed to send preference list")
+			}
+			_ = c.Close()
 		} else {
-			err2 := c.Call("MyDynamo.SendPreferenceList", nodePreferenceList, &empty)
-			if err2 != nil {
+			var empty mydynamo.Empty
+			c, _ := rpc.DialHTTP("tcp", info.Address+":"+info.Port)
+			if err != nil {
 				log.Println("Failed to send preference list")
+			} else {
+				err2 := c.Call("MyDynamo.SendPreferenceList", nodePreferenceList, &empty)
+				if err2 != nil {
+					log.Println("Failed to send preference list")
+				}
 			}
 		}
 		nodePreferenceList = mydynamo.RotateServerList(nodePreferenceList)
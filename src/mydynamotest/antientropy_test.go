@@ -0,0 +1,191 @@
+package mydynamotest
+
+import (
+	dy "mydynamo"
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Anti-Entropy", func() {
+
+	Describe("Merkle Tree", func() {
+		makeEntries := func(vectorClockMap map[string]uint64, value []byte) []dy.ObjectEntry {
+			return []dy.ObjectEntry{{
+				Context: dy.NewContext(NewVectorClockFromMap(vectorClockMap)),
+				Value:   value,
+			}}
+		}
+
+		It("should have equal root hashes for equal entries.", func() {
+			tree0 := dy.NewMerkleTree(map[string][]dy.ObjectEntry{
+				"k0": makeEntries(map[string]uint64{"s0": 1}, []byte("v0")),
+				"k1": makeEntries(map[string]uint64{"s1": 1}, []byte("v1")),
+			})
+			tree1 := dy.NewMerkleTree(map[string][]dy.ObjectEntry{
+				"k1": makeEntries(map[string]uint64{"s1": 1}, []byte("v1")),
+				"k0": makeEntries(map[string]uint64{"s0": 1}, []byte("v0")),
+				"k2": {},
+			})
+
+			root0, err := tree0.Hash(1)
+			Expect(err).NotTo(HaveOccurred())
+			root1, err := tree1.Hash(1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(root0).To(Equal(root1))
+			Expect(root0).NotTo(BeZero())
+		})
+
+		It("should have different root hashes for different entries.", func() {
+			tree0 := dy.NewMerkleTree(map[string][]dy.ObjectEntry{
+				"k0": makeEntries(map[string]uint64{"s0": 1}, []byte("v0")),
+			})
+			tree1 := dy.NewMerkleTree(map[string][]dy.ObjectEntry{
+				"k0": makeEntries(map[string]uint64{"s0": 2}, []byte("v0")),
+			})
+			tree2 := dy.NewMerkleTree(map[string][]dy.ObjectEntry{})
+
+			root0, _ := tree0.Hash(1)
+			root1, _ := tree1.Hash(1)
+			root2, _ := tree2.Hash(1)
+			Expect(root0).NotTo(Equal(root1))
+			Expect(root2).To(BeZero())
+		})
+
+		It("should reject invalid indices.", func() {
+			tree := dy.NewMerkleTree(map[string][]dy.ObjectEntry{})
+			_, err := tree.Hash(0)
+			Expect(err).To(HaveOccurred())
+			_, err = tree.Digests([]int{-1})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Merkle Tree Rounds", func() {
+		It("should build the tree once per round.", func() {
+			server := dy.NewDynamoServer(1, 1, "localhost", "0", "s0")
+			Expect(server.Recover()).To(Succeed())
+			peer := dy.NewDynamoNode("localhost", "0")
+
+			var result bool
+			Expect(server.PutRaw(MakePutFromVectorClockMapAndValue("k0", map[string]uint64{"s0": 1}, []byte("v0")), &result)).To(Succeed())
+			var roundHashes []uint64
+			Expect(server.GetMerkleHashes(dy.MerkleHashesArgs{Peer: peer, Round: 1, Indices: []int{1}}, &roundHashes)).To(Succeed())
+
+			// Later requests of the round are served by the tree built for its first request, even for the root
+			Expect(server.PutRaw(MakePutFromVectorClockMapAndValue("k1", map[string]uint64{"s0": 1}, []byte("v1")), &result)).To(Succeed())
+			var hashes []uint64
+			Expect(server.GetMerkleHashes(dy.MerkleHashesArgs{Peer: peer, Round: 1, Indices: []int{1, 2, 3}}, &hashes)).To(Succeed())
+			Expect(hashes[0]).To(Equal(roundHashes[0]))
+			Expect(server.GetMerkleHashes(dy.MerkleHashesArgs{Peer: peer, Round: 1, Indices: []int{1}}, &hashes)).To(Succeed())
+			Expect(hashes[0]).To(Equal(roundHashes[0]))
+
+			// A concurrent round of the same peer sees the new entry, and does not replace the tree of the first round
+			var newRoundHashes []uint64
+			Expect(server.GetMerkleHashes(dy.MerkleHashesArgs{Peer: peer, Round: 2, Indices: []int{1}}, &newRoundHashes)).To(Succeed())
+			Expect(newRoundHashes[0]).NotTo(Equal(roundHashes[0]))
+			Expect(server.GetMerkleHashes(dy.MerkleHashesArgs{Peer: peer, Round: 1, Indices: []int{1}}, &hashes)).To(Succeed())
+			Expect(hashes[0]).To(Equal(roundHashes[0]))
+
+			// The tree of a round is dropped once its digests are requested
+			var digests map[string][]uint64
+			Expect(server.GetMerkleDigests(dy.MerkleDigestsArgs{Peer: peer, Round: 1, Buckets: []int{}}, &digests)).To(Succeed())
+			Expect(server.GetMerkleHashes(dy.MerkleHashesArgs{Peer: peer, Round: 1, Indices: []int{1}}, &hashes)).To(Succeed())
+			Expect(hashes[0]).To(Equal(newRoundHashes[0]))
+		})
+	})
+
+	Describe("R=1, W=1, ClusterSize=2", func() {
+		var sc ServerCoordinator
+
+		BeforeEach(func() {
+			// StartingPort: 8000, R-Value: 1, W-Value: 1, ClusterSize: 2
+			sc = NewServerCoordinator(8000+config.GinkgoConfig.ParallelNode*100, 1, 1, 2)
+		})
+
+		AfterEach(func() {
+			sc.Kill()
+		})
+
+		It("should synchronize entries in both directions.", func() {
+			sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))
+			sc.GetClient(1).Put(MakePutFreshEntry("k1", []byte("v1")))
+			sc.GetClient(0).AntiEntropy()
+
+			for i := 0; i < 2; i++ {
+				res := sc.GetClient(i).Get("k0")
+				Expect(res).NotTo(BeNil())
+				Expect(GetEntryValues(res)).To(ConsistOf([][]byte{
+					[]byte("v0"),
+				}))
+
+				res = sc.GetClient(i).Get("k1")
+				Expect(res).NotTo(BeNil())
+				Expect(GetEntryValues(res)).To(ConsistOf([][]byte{
+					[]byte("v1"),
+				}))
+			}
+		})
+
+		It("should synchronize concurrent entries.", func() {
+			sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0-0")))
+			sc.GetClient(1).Put(MakePutFreshEntry("k0", []byte("v0-1")))
+			sc.GetClient(1).AntiEntropy()
+
+			for i := 0; i < 2; i++ {
+				res := sc.GetClient(i).Get("k0")
+				Expect(res).NotTo(BeNil())
+				Expect(GetEntryValues(res)).To(ConsistOf([][]byte{
+					[]byte("v0-0"),
+					[]byte("v0-1"),
+				}))
+			}
+		})
+
+		It("should overwrite ancestor entries.", func() {
+			sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0-0")))
+			sc.GetClient(1).Put(MakePutFromVectorClockMapAndValue(
+				"k0",
+				map[string]uint64{sc.GetID(0): 1},
+				[]byte("v0-1"),
+			))
+			sc.GetClient(0).AntiEntropy()
+
+			for i := 0; i < 2; i++ {
+				res := sc.GetClient(i).Get("k0")
+				Expect(res).NotTo(BeNil())
+				Expect(GetEntryValues(res)).To(ConsistOf([][]byte{
+					[]byte("v0-1"),
+				}))
+			}
+		})
+
+		It("should transfer fewer bytes than gossip.", func() {
+			value := MakeRandomBytes(1024)
+			for k := 0; k < 100; k++ {
+				putArgs := MakePutFromVectorClockMapAndValue("k"+strconv.Itoa(k), map[string]uint64{"s0": 1}, value)
+				Expect(sc.GetClient(0).PutRaw(putArgs)).To(BeTrue())
+				Expect(sc.GetClient(1).PutRaw(putArgs)).To(BeTrue())
+			}
+
+			sc.GetClient(0).Put(MakePutFreshEntry("d0", value))
+			sc.GetClient(0).AntiEntropy()
+			res := sc.GetClient(1).Get("d0")
+			Expect(res).NotTo(BeNil())
+			Expect(GetEntryValues(res)).To(ConsistOf([][]byte{value}))
+
+			sc.GetClient(0).Put(MakePutFreshEntry("d1", value))
+			sc.GetClient(0).Gossip()
+			res = sc.GetClient(1).Get("d1")
+			Expect(res).NotTo(BeNil())
+			Expect(GetEntryValues(res)).To(ConsistOf([][]byte{value}))
+
+			stats := sc.GetClient(0).GetReplicationStats()
+			Expect(stats).NotTo(BeNil())
+			Expect(stats.AntiEntropyBytes).To(BeNumerically(">", 1024))
+			Expect(stats.AntiEntropyBytes * 10).To(BeNumerically("<", stats.GossipBytes))
+		})
+	})
+})
@@ -0,0 +1,164 @@
+package mydynamotest
+
+import (
+	dy "mydynamo"
+	"net/rpc"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Background Gossip", func() {
+
+	Describe("R=1, W=1, ClusterSize=3, GossipInterval=100ms, GossipFanout=1", func() {
+		var sc ServerCoordinator
+
+		BeforeEach(func() {
+			// StartingPort: 8000, R-Value: 1, W-Value: 1, ClusterSize: 3
+			sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 1, 1, 3, map[string]string{
+				dy.GOSSIP_INTERVAL: "100",
+				dy.GOSSIP_JITTER:   "50",
+				dy.GOSSIP_FANOUT:   "1",
+			})
+		})
+
+		AfterEach(func() {
+			sc.Kill()
+		})
+
+		It("should replicate entry to all servers eventually.", func() {
+			sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))
+
+			for i := 1; i < 3; i++ {
+				client := sc.GetClient(i)
+				Eventually(func() [][]byte {
+					res := client.Get("k0")
+					Expect(res).NotTo(BeNil())
+					return GetEntryValues(res)
+				}, 5*time.Second, 100*time.Millisecond).Should(ConsistOf([][]byte{
+					[]byte("v0"),
+				}))
+			}
+		})
+	})
+
+	Describe("In-process servers", func() {
+		var startingPort int
+		var servers []*dy.DynamoServer
+		var serveErrs []chan error
+
+		// Creates and serves servers with the given config, then sends them their preference lists
+		startServers := func(dynamoConfig dy.DynamoConfig, count int) {
+			nodes := make([]dy.DynamoNode, 0)
+			for i := 0; i < count; i++ {
+				port := strconv.Itoa(startingPort + i)
+				server := dy.NewDynamoServerWithConfig(dynamoConfig, "localhost", port, "s"+strconv.Itoa(i))
+				serveErr := make(chan error, 1)
+				go func() {
+					serveErr <- dy.ServeDynamoServer(server)
+				}()
+
+				servers = append(servers, &server)
+				serveErrs = append(serveErrs, serveErr)
+				nodes = append(nodes, dy.NewDynamoNode("localhost", port))
+			}
+
+			for _, node := range nodes {
+				var client *rpc.Client
+				Eventually(func() error {
+					var err error
+					client, err = rpc.DialHTTP("tcp", node.Address+":"+node.Port)
+					return err
+				}, 5*time.Second, 50*time.Millisecond).Should(Succeed())
+
+				var empty dy.Empty
+				Expect(client.Call("MyDynamo.SendPreferenceList", nodes, &empty)).To(Succeed())
+				Expect(client.Close()).To(Succeed())
+				nodes = dy.RotateServerList(nodes)
+			}
+		}
+
+		getClient := func(i int) *dy.RPCClient {
+			client := dy.NewDynamoRPCClient("localhost:" + strconv.Itoa(startingPort+i))
+			Expect(client.RpcConnect()).To(Succeed())
+			return client
+		}
+
+		BeforeEach(func() {
+			startingPort = 8050 + config.GinkgoConfig.ParallelNode*100
+			servers = make([]*dy.DynamoServer, 0)
+			serveErrs = make([]chan error, 0)
+		})
+
+		AfterEach(func() {
+			for i, server := range servers {
+				server.Shutdown()
+				Eventually(serveErrs[i], 5*time.Second).Should(Receive(BeNil()))
+			}
+		})
+
+		It("should not gossip while crashed.", func() {
+			dynamoConfig := dy.NewDynamoConfig()
+			dynamoConfig.GossipIntervalMs = 1000
+			startServers(dynamoConfig, 2)
+
+			client0 := getClient(0)
+			defer client0.CleanConn()
+			client1 := getClient(1)
+			defer client1.CleanConn()
+
+			Expect(client0.Put(MakePutFreshEntry("k0", []byte("v0")))).To(BeTrue())
+			client0.ForceCrash()
+
+			Consistently(func() [][]byte {
+				res := client1.Get("k0")
+				Expect(res).NotTo(BeNil())
+				return GetEntryValues(res)
+			}, 2500*time.Millisecond, 100*time.Millisecond).Should(BeEmpty())
+
+			client0.ForceRestore()
+			Eventually(func() [][]byte {
+				res := client1.Get("k0")
+				Expect(res).NotTo(BeNil())
+				return GetEntryValues(res)
+			}, 5*time.Second, 100*time.Millisecond).Should(ConsistOf([][]byte{
+				[]byte("v0"),
+			}))
+		})
+
+		It("should stop serving on shutdown.", func() {
+			dynamoConfig := dy.NewDynamoConfig()
+			dynamoConfig.GossipIntervalMs = 10
+			startServers(dynamoConfig, 1)
+
+			servers[0].Shutdown()
+			servers[0].Shutdown()
+			Eventually(serveErrs[0], 5*time.Second).Should(Receive(BeNil()))
+
+			_, err := rpc.DialHTTP("tcp", "localhost:"+strconv.Itoa(startingPort))
+			Expect(err).To(HaveOccurred())
+
+			// The server is already shut down, and AfterEach must not wait for it again
+			servers = servers[:0]
+		})
+	})
+
+	Describe("Config", func() {
+		It("should load gossip interval, jitter and fan-out.", func() {
+			config := dy.NewDynamoConfig()
+			Expect(config.SetOption(dy.GOSSIP_INTERVAL, "1000")).To(Succeed())
+			Expect(config.SetOption(dy.GOSSIP_JITTER, "200")).To(Succeed())
+			Expect(config.SetOption(dy.GOSSIP_FANOUT, "2")).To(Succeed())
+			Expect(config.GossipIntervalMs).To(Equal(1000))
+			Expect(config.GossipJitterMs).To(Equal(200))
+			Expect(config.GossipFanout).To(Equal(2))
+
+			Expect(config.SetOption(dy.GOSSIP_INTERVAL, "-1")).NotTo(Succeed())
+			Expect(config.SetOption(dy.GOSSIP_JITTER, "-1")).NotTo(Succeed())
+			Expect(config.SetOption(dy.GOSSIP_FANOUT, "-1")).NotTo(Succeed())
+		})
+	})
+})
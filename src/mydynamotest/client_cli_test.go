@@ -0,0 +1,141 @@
+package mydynamotest
+
+import (
+	"encoding/json"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Client CLI", func() {
+	var sc ServerCoordinator
+
+	// Runs the client CLI with the arguments and the standard input, and returns its output once it exits
+	runClient := func(exitCode int, stdin string, args ...string) string {
+		cmd := exec.Command("DynamoClient", args...)
+		cmd.Stdin = strings.NewReader(stdin)
+		session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session, 5*time.Second).Should(gexec.Exit(exitCode))
+		return string(session.Out.Contents())
+	}
+
+	server := func(i int) string {
+		return "-server=localhost:" + strconv.Itoa(sc.StartingPort+i)
+	}
+
+	// Runs a command of the client CLI with JSON output, and decodes its output
+	runJSON := func(exitCode int, output interface{}, args ...string) {
+		Expect(json.Unmarshal([]byte(runClient(exitCode, "", append([]string{"-json"}, args...)...)), output)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		sc = NewServerCoordinator(8000+config.GinkgoConfig.ParallelNode*100, 3, 3, 3)
+	})
+
+	AfterEach(func() {
+		sc.Kill()
+	})
+
+	It("should put and get with contexts.", func() {
+		var put struct {
+			Success bool
+			Acks    int
+		}
+		runJSON(0, &put, server(0), "put", "k1", "v1")
+		Expect(put.Success).To(BeTrue())
+		Expect(put.Acks).To(Equal(3))
+
+		var get struct {
+			Values  []string
+			Context map[string]uint64
+		}
+		runJSON(0, &get, server(1), "get", "k1")
+		Expect(get.Values).To(ConsistOf("v1"))
+		Expect(get.Context).To(Equal(map[string]uint64{"s0": 1}))
+
+		runJSON(0, &put, server(1), "put", "k1", "v2", "--context", `{"s0":1}`)
+		runJSON(0, &get, server(2), "get", "k1")
+		Expect(get.Values).To(ConsistOf("v2"))
+		Expect(get.Context).To(Equal(map[string]uint64{"s0": 1, "s1": 1}))
+	})
+
+	It("should list and resolve siblings.", func() {
+		runClient(0, "", server(0), "put", "k1", "v1")
+		runClient(0, "", server(1), "put", "k1", "v2")
+
+		var siblings struct {
+			Siblings []struct {
+				Value   string
+				Context map[string]uint64
+			}
+		}
+		runJSON(0, &siblings, server(2), "siblings", "k1")
+		Expect(siblings.Siblings).To(HaveLen(2))
+		Expect(siblings.Siblings[0].Context).To(Or(
+			Equal(map[string]uint64{"s0": 1}),
+			Equal(map[string]uint64{"s1": 1}),
+		))
+
+		var put struct {
+			Success bool
+		}
+		runJSON(0, &put, server(2), "resolve", "k1", "v3")
+		Expect(put.Success).To(BeTrue())
+
+		runJSON(0, &siblings, server(0), "siblings", "k1")
+		Expect(siblings.Siblings).To(HaveLen(1))
+		Expect(siblings.Siblings[0].Value).To(Equal("v3"))
+		Expect(siblings.Siblings[0].Context).To(Equal(map[string]uint64{"s0": 1, "s1": 1, "s2": 1}))
+	})
+
+	It("should crash and restore the server.", func() {
+		runClient(0, "", server(0), "crash")
+
+		var failed struct {
+			Error string
+		}
+		runJSON(1, &failed, server(0), "get", "k1")
+		Expect(failed.Error).To(HavePrefix("get failed"))
+
+		runClient(0, "", server(0), "restore")
+		runClient(0, "", server(0), "get", "k1")
+	})
+
+	It("should run the commands read from the standard input.", func() {
+		output := runClient(0, "put k1 hello world\n\nget k1\nunknown\nexit\nget k1\n", server(0))
+		Expect(strings.Split(strings.TrimSpace(output), "\n")).To(Equal([]string{
+			"success: true acks: 3",
+			"hello world",
+			`context: {"s0":1}`,
+			`error: unknown command "unknown", run "help" for the list of commands`,
+		}))
+	})
+
+	It("should read quoted words and contexts from the standard input.", func() {
+		output := runClient(0, strings.Join([]string{
+			"put k1 a {b}",
+			`put k1 'hello  world' --context '{"s0": 1}'`,
+			`get "k1"`,
+			"put k1 'unterminated",
+			`delete k1 --context='{"s0":2}'`,
+			"get k1",
+		}, "\n"), server(0))
+		Expect(strings.Split(strings.TrimSpace(output), "\n")).To(Equal([]string{
+			"success: true acks: 3",
+			"success: true acks: 3",
+			"hello  world",
+			`context: {"s0":2}`,
+			"error: unterminated ' quote",
+			"ok",
+			"(not found)",
+			"context: {}",
+		}))
+	})
+})
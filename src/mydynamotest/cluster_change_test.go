@@ -0,0 +1,333 @@
+package mydynamotest
+
+import (
+	dy "mydynamo"
+	"net/rpc"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cluster Changes", func() {
+	var startingPort int
+	var dynamoConfig dy.DynamoConfig
+	var servers []*dy.DynamoServer
+	var serveErrs []chan error
+
+	node := func(i int) dy.DynamoNode {
+		return dy.NewDynamoNode("localhost", strconv.Itoa(startingPort+i))
+	}
+
+	// Creates and serves a server without sending it a preference list
+	startServer := func(i int) {
+		server := dy.NewDynamoServerWithConfig(dynamoConfig, "localhost", strconv.Itoa(startingPort+i), "s"+strconv.Itoa(i))
+		serveErr := make(chan error, 1)
+		go func() {
+			serveErr <- dy.ServeDynamoServer(server)
+		}()
+		servers = append(servers, &server)
+		serveErrs = append(serveErrs, serveErr)
+
+		Eventually(func() error {
+			client, err := rpc.DialHTTP("tcp", "localhost:"+strconv.Itoa(startingPort+i))
+			if err == nil {
+				_ = client.Close()
+			}
+			return err
+		}, 5*time.Second, 50*time.Millisecond).Should(Succeed())
+	}
+
+	// Serves `count` servers and sends them their preference lists
+	startCluster := func(count int) {
+		nodes := make([]dy.DynamoNode, 0)
+		for i := 0; i < count; i++ {
+			startServer(i)
+			nodes = append(nodes, node(i))
+		}
+
+		for i := 0; i < count; i++ {
+			client := getClusterClient(startingPort, i)
+			var empty dy.Empty
+			nodeList := append(append([]dy.DynamoNode{}, nodes[i:]...), nodes[:i]...)
+			Expect(client.Call("MyDynamo.SendPreferenceList", nodeList, &empty)).To(Succeed())
+			Expect(client.Close()).To(Succeed())
+		}
+	}
+
+	getClient := func(i int) *dy.RPCClient {
+		client := dy.NewDynamoRPCClient("localhost:" + strconv.Itoa(startingPort+i))
+		Expect(client.RpcConnect()).To(Succeed())
+		return client
+	}
+
+	// Puts keys through the given servers until stopped, and returns the keys that were put successfully
+	startWriteLoad := func(serverIndices []int) (chan struct{}, chan []string) {
+		stop := make(chan struct{})
+		done := make(chan []string, 1)
+		go func() {
+			defer GinkgoRecover()
+
+			clients := make([]*dy.RPCClient, 0)
+			for _, i := range serverIndices {
+				clients = append(clients, getClient(i))
+			}
+			keys := make([]string, 0)
+			for k := 0; ; k++ {
+				select {
+				case <-stop:
+					for _, client := range clients {
+						client.CleanConn()
+					}
+					done <- keys
+					return
+				default:
+				}
+
+				key := "k" + strconv.Itoa(k)
+				Expect(clients[k%len(clients)].Put(MakePutFreshEntry(key, []byte(key)))).To(BeTrue())
+				keys = append(keys, key)
+			}
+		}()
+		return stop, done
+	}
+
+	expectKeys := func(i int, keys []string) {
+		client := getClient(i)
+		defer client.CleanConn()
+		for _, key := range keys {
+			res := client.Get(key)
+			Expect(res).NotTo(BeNil())
+			Expect(GetEntryValues(res)).To(ConsistOf([][]byte{[]byte(key)}), key)
+		}
+	}
+
+	BeforeEach(func() {
+		startingPort = 8050 + config.GinkgoConfig.ParallelNode*100
+		dynamoConfig = dy.NewDynamoConfig()
+		dynamoConfig.VirtualNodes = 16
+		dynamoConfig.NValue = 3
+		dynamoConfig.RValue = 2
+		dynamoConfig.WValue = 2
+		servers = make([]*dy.DynamoServer, 0)
+		serveErrs = make([]chan error, 0)
+	})
+
+	AfterEach(func() {
+		for i, server := range servers {
+			if server != nil {
+				server.Shutdown()
+				Eventually(serveErrs[i], 5*time.Second).Should(Receive(BeNil()))
+			}
+		}
+	})
+
+	It("should grow the cluster from 3 to 5 servers under write load.", func() {
+		startCluster(3)
+		stop, done := startWriteLoad([]int{0, 1, 2})
+		time.Sleep(200 * time.Millisecond)
+
+		for i := 3; i < 5; i++ {
+			startServer(i)
+			client := getClient(i)
+			Expect(client.Join(node(i % 3))).To(BeTrue())
+			client.CleanConn()
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		close(stop)
+		var keys []string
+		Eventually(done, 10*time.Second).Should(Receive(&keys))
+		Expect(len(keys)).To(BeNumerically(">", 0))
+
+		for i := 0; i < 5; i++ {
+			client := getClient(i)
+			Expect(client.GetMembership()).To(HaveLen(5))
+			client.CleanConn()
+		}
+		for i := 0; i < 5; i++ {
+			expectKeys(i, keys)
+		}
+
+		// The new servers own some of the keys
+		for i := 3; i < 5; i++ {
+			client := getClient(i)
+			ownedCount := 0
+			for _, key := range keys {
+				var res dy.DynamoResult
+				Expect(client.GetRaw(key, &res)).To(BeTrue())
+				if len(res.EntryList) > 0 {
+					ownedCount++
+				}
+			}
+			client.CleanConn()
+			Expect(ownedCount).To(BeNumerically(">", 0))
+		}
+	})
+
+	It("should drop the entries handed off to a joining server.", func() {
+		startCluster(3)
+		keys := make([]string, 0)
+		client0 := getClient(0)
+		for k := 0; k < 20; k++ {
+			key := "k" + strconv.Itoa(k)
+			Expect(client0.Put(MakePutFreshEntry(key, []byte(key)))).To(BeTrue())
+			keys = append(keys, key)
+		}
+		client0.CleanConn()
+		for i := 0; i < 3; i++ {
+			client := getClient(i)
+			client.Gossip()
+			client.CleanConn()
+		}
+
+		startServer(3)
+		client3 := getClient(3)
+		Expect(client3.Join(node(0))).To(BeTrue())
+		client3.CleanConn()
+
+		// Each key is only kept by its N owners
+		storedCounts := make(map[string]int)
+		for i := 0; i < 4; i++ {
+			client := getClient(i)
+			for _, key := range keys {
+				var res dy.DynamoResult
+				Expect(client.GetRaw(key, &res)).To(BeTrue())
+				if len(res.EntryList) > 0 {
+					storedCounts[key]++
+				}
+			}
+			client.CleanConn()
+		}
+		for _, key := range keys {
+			Expect(storedCounts[key]).To(Equal(3), key)
+		}
+		for i := 0; i < 4; i++ {
+			expectKeys(i, keys)
+		}
+	})
+
+	It("should stream the keys of a joining server even if a server misses the new ring.", func() {
+		startCluster(3)
+		keys := make([]string, 0)
+		client0 := getClient(0)
+		for k := 0; k < 20; k++ {
+			key := "k" + strconv.Itoa(k)
+			Expect(client0.Put(MakePutFreshEntry(key, []byte(key)))).To(BeTrue())
+			keys = append(keys, key)
+		}
+		client0.Gossip()
+		client0.CleanConn()
+
+		client2 := getClient(2)
+		client2.ForceCrash()
+		client2.CleanConn()
+
+		startServer(3)
+		client3 := getClient(3)
+		defer client3.CleanConn()
+		Expect(client3.Join(node(0))).To(BeFalse())
+
+		ownedCount := 0
+		for _, key := range keys {
+			var res dy.DynamoResult
+			Expect(client3.GetRaw(key, &res)).To(BeTrue())
+			if len(res.EntryList) > 0 {
+				ownedCount++
+			}
+		}
+		Expect(ownedCount).To(BeNumerically(">", 0))
+	})
+
+	It("should decommission a server under write load.", func() {
+		startCluster(4)
+		stop, done := startWriteLoad([]int{0, 1, 2})
+		time.Sleep(200 * time.Millisecond)
+
+		client3 := getClient(3)
+		Expect(client3.Decommission()).To(BeTrue())
+		client3.CleanConn()
+		Eventually(serveErrs[3], 5*time.Second).Should(Receive(BeNil()))
+		servers[3] = nil
+		time.Sleep(200 * time.Millisecond)
+
+		close(stop)
+		var keys []string
+		Eventually(done, 10*time.Second).Should(Receive(&keys))
+		Expect(len(keys)).To(BeNumerically(">", 0))
+
+		for i := 0; i < 3; i++ {
+			expectKeys(i, keys)
+		}
+	})
+
+	It("should discover the cluster from seeds.", func() {
+		startServer(0)
+		client0 := getClusterClient(startingPort, 0)
+		var empty dy.Empty
+		Expect(client0.Call("MyDynamo.SendPreferenceList", []dy.DynamoNode{node(0)}, &empty)).To(Succeed())
+		Expect(client0.Close()).To(Succeed())
+		for i := 1; i < 4; i++ {
+			startServer(i)
+		}
+
+		// Servers joining through the same seed at the same time are added one at a time
+		joined := make(chan bool, 3)
+		for i := 1; i < 4; i++ {
+			go func(i int) {
+				client := getClient(i)
+				defer client.CleanConn()
+				joined <- client.JoinSeeds([]dy.DynamoNode{node(0), node(1)})
+			}(i)
+		}
+		for i := 1; i < 4; i++ {
+			Eventually(joined, 5*time.Second).Should(Receive(BeTrue()))
+		}
+
+		for i := 0; i < 4; i++ {
+			client := getClient(i)
+			Expect(client.GetMembership()).To(HaveLen(4))
+			client.CleanConn()
+		}
+
+		client := getClient(3)
+		defer client.CleanConn()
+		Expect(client.Put(MakePutFreshEntry("s1", []byte("abcde")))).To(BeTrue())
+		expectValue := func(i int) {
+			c := getClient(i)
+			defer c.CleanConn()
+			Expect(GetEntryValues(c.Get("s1"))).To(ConsistOf([][]byte{[]byte("abcde")}))
+		}
+		expectValue(0)
+		expectValue(1)
+	})
+
+	It("should not join seeds which are not in a cluster.", func() {
+		startServer(0)
+		startServer(1)
+
+		client := getClient(1)
+		defer client.CleanConn()
+		Expect(client.JoinSeeds([]dy.DynamoNode{node(0)})).To(BeFalse())
+		Expect(client.JoinSeeds([]dy.DynamoNode{node(1)})).To(BeFalse())
+	})
+
+	It("should require virtual nodes.", func() {
+		dynamoConfig.VirtualNodes = 0
+		startCluster(1)
+		startServer(1)
+
+		client := getClient(1)
+		defer client.CleanConn()
+		Expect(client.Join(node(0))).To(BeFalse())
+	})
+})
+
+// Returns an RPC client connected to the i-th server
+func getClusterClient(startingPort int, i int) *rpc.Client {
+	client, err := rpc.DialHTTP("tcp", "localhost:"+strconv.Itoa(startingPort+i))
+	Expect(err).NotTo(HaveOccurred())
+	return client
+}
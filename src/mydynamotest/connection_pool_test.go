@@ -0,0 +1,189 @@
+package mydynamotest
+
+import (
+	"bufio"
+	"io"
+	dy "mydynamo"
+	"net"
+	"net/http"
+	"net/rpc"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Connection Pool", func() {
+	var startingPort int
+	var dynamoConfig dy.DynamoConfig
+	var servers []*dy.DynamoServer
+	var serveErrs []chan error
+
+	// Creates and serves the i-th server, replacing the server previously served on its port
+	startServer := func(i int) {
+		server := dy.NewDynamoServerWithConfig(dynamoConfig, "localhost", strconv.Itoa(startingPort+i), "s"+strconv.Itoa(i))
+		serveErr := make(chan error, 1)
+		go func() {
+			serveErr <- dy.ServeDynamoServer(server)
+		}()
+		servers[i] = &server
+		serveErrs[i] = serveErr
+
+		nodes := make([]dy.DynamoNode, 0)
+		for j := range servers {
+			nodes = append(nodes, dy.NewDynamoNode("localhost", strconv.Itoa(startingPort+j)))
+		}
+		for j := 0; j < i; j++ {
+			nodes = dy.RotateServerList(nodes)
+		}
+
+		var client *rpc.Client
+		Eventually(func() error {
+			var err error
+			client, err = rpc.DialHTTP("tcp", "localhost:"+strconv.Itoa(startingPort+i))
+			return err
+		}, 5*time.Second, 50*time.Millisecond).Should(Succeed())
+
+		var empty dy.Empty
+		Expect(client.Call("MyDynamo.SendPreferenceList", nodes, &empty)).To(Succeed())
+		Expect(client.Close()).To(Succeed())
+	}
+
+	stopServer := func(i int) {
+		servers[i].Shutdown()
+		Eventually(serveErrs[i], 5*time.Second).Should(Receive(BeNil()))
+		servers[i] = nil
+	}
+
+	getClient := func(i int) *dy.RPCClient {
+		client := dy.NewDynamoRPCClient("localhost:" + strconv.Itoa(startingPort+i))
+		Expect(client.RpcConnect()).To(Succeed())
+		return client
+	}
+
+	BeforeEach(func() {
+		startingPort = 8050 + config.GinkgoConfig.ParallelNode*100
+		dynamoConfig = dy.NewDynamoConfig()
+		dynamoConfig.WValue = 2
+		dynamoConfig.ConnectionHealthCheckIntervalMs = 0
+		servers = make([]*dy.DynamoServer, 2)
+		serveErrs = make([]chan error, 2)
+	})
+
+	AfterEach(func() {
+		for i, server := range servers {
+			if server != nil {
+				stopServer(i)
+			}
+		}
+	})
+
+	It("should reuse connections to other servers.", func() {
+		startServer(0)
+		startServer(1)
+		client0 := getClient(0)
+		defer client0.CleanConn()
+
+		for k := 0; k < 10; k++ {
+			Expect(client0.Put(MakePutFreshEntry("k"+strconv.Itoa(k), []byte("v")))).To(BeTrue())
+		}
+
+		stats := client0.GetConnectionPoolStats()
+		Expect(stats).NotTo(BeNil())
+		Expect(stats.Dials).To(Equal(int64(1)))
+		Expect(stats.Reuses).To(Equal(int64(9)))
+	})
+
+	It("should reconnect to a restarted server.", func() {
+		startServer(0)
+		startServer(1)
+		client0 := getClient(0)
+		defer client0.CleanConn()
+
+		Expect(client0.Put(MakePutFreshEntry("k0", []byte("v0")))).To(BeTrue())
+		stopServer(1)
+		startServer(1)
+		Expect(client0.Put(MakePutFreshEntry("k1", []byte("v1")))).To(BeTrue())
+
+		stats := client0.GetConnectionPoolStats()
+		Expect(stats).NotTo(BeNil())
+		Expect(stats.Dials).To(Equal(int64(2)))
+		Expect(stats.Reconnects).To(Equal(int64(1)))
+	})
+
+	It("should evict connections failing health checks.", func() {
+		dynamoConfig.ConnectionHealthCheckIntervalMs = 50
+		startServer(0)
+		startServer(1)
+		client0 := getClient(0)
+		defer client0.CleanConn()
+
+		Expect(client0.Put(MakePutFreshEntry("k0", []byte("v0")))).To(BeTrue())
+		stopServer(1)
+
+		Eventually(func() int64 {
+			stats := client0.GetConnectionPoolStats()
+			Expect(stats).NotTo(BeNil())
+			return stats.Evictions
+		}, 5*time.Second, 50*time.Millisecond).Should(Equal(int64(1)))
+	})
+
+	It("should not panic when a server is unreachable.", func() {
+		startServer(0)
+		startServer(1)
+		client0 := getClient(0)
+		defer client0.CleanConn()
+		stopServer(1)
+
+		Expect(client0.Put(MakePutFreshEntry("k0", []byte("v0")))).To(BeFalse())
+		client0.Gossip()
+
+		res := client0.Get("k0")
+		Expect(res).NotTo(BeNil())
+		Expect(GetEntryValues(res)).To(ConsistOf([][]byte{
+			[]byte("v0"),
+		}))
+	})
+
+	It("should time out calls to a server that never responds.", func() {
+		listener, err := net.Listen("tcp", "localhost:"+strconv.Itoa(startingPort))
+		Expect(err).NotTo(HaveOccurred())
+		defer listener.Close()
+		go func() {
+			// Switches the connections to RPC like a server, but never responds to the calls
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				_, _ = http.ReadRequest(bufio.NewReader(conn))
+				_, _ = io.WriteString(conn, "HTTP/1.0 200 Connected to Go RPC\n\n")
+			}
+		}()
+
+		pool := dy.NewConnectionPool(2, 200*time.Millisecond)
+		defer pool.Close()
+		var empty dy.Empty
+		done := make(chan error, 1)
+		go func() {
+			done <- pool.Call(dy.NewDynamoNode("localhost", strconv.Itoa(startingPort)), "MyDynamo.Ping", empty, &empty)
+		}()
+		Eventually(done, 2*time.Second).Should(Receive(HaveOccurred()))
+	})
+
+	Describe("Config", func() {
+		It("should load connection pool options.", func() {
+			config := dy.NewDynamoConfig()
+			Expect(config.SetOption(dy.CONNECTION_POOL_MAX_IDLE, "4")).To(Succeed())
+			Expect(config.SetOption(dy.CONNECTION_HEALTH_CHECK_INTERVAL, "1000")).To(Succeed())
+			Expect(config.ConnectionPoolMaxIdle).To(Equal(4))
+			Expect(config.ConnectionHealthCheckIntervalMs).To(Equal(1000))
+
+			Expect(config.SetOption(dy.CONNECTION_POOL_MAX_IDLE, "-1")).NotTo(Succeed())
+			Expect(config.SetOption(dy.CONNECTION_HEALTH_CHECK_INTERVAL, "-1")).NotTo(Succeed())
+		})
+	})
+})
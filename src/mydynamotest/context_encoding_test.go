@@ -0,0 +1,211 @@
+package mydynamotest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	dy "mydynamo"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// Returns a context with the clocks and timestamps of the comma separated node IDs, and the dot
+func newEncodingTestContext(nodeIDs string, counter uint64, timestamp int64, dotNodeID string, dotCounter uint64) dy.Context {
+	clock := dy.NewVectorClock()
+	for i, nodeID := range strings.Split(nodeIDs, ",") {
+		if nodeID != "" {
+			clock.NodeClocks[nodeID] = counter + uint64(i)
+			if elementTimestamp := timestamp - int64(i); elementTimestamp != 0 {
+				clock.Timestamps[nodeID] = elementTimestamp
+			}
+		}
+	}
+	context := dy.NewContext(clock)
+	context.Dot = dy.Dot{NodeID: dotNodeID, Counter: dotCounter}
+	return context
+}
+
+// Returns the payload prefixed with its CRC-32 (Castagnoli) checksum
+func withChecksum(payload []byte) []byte {
+	data := make([]byte, 4, 4+len(payload))
+	binary.BigEndian.PutUint32(data, crc32.Checksum(payload, crc32.MakeTable(crc32.Castagnoli)))
+	return append(data, payload...)
+}
+
+var _ = Describe("Context Encoding", func() {
+	It("should round-trip vector clocks and contexts.", func() {
+		context := newEncodingTestContext("s0,s1,s2", 1, 1<<40, "s1", 7)
+
+		data, err := context.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+		var decodedContext dy.Context
+		Expect(decodedContext.UnmarshalBinary(data)).To(Succeed())
+		Expect(decodedContext).To(Equal(context))
+
+		data, err = context.Clock.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+		var decodedClock dy.VectorClock
+		Expect(decodedClock.UnmarshalBinary(data)).To(Succeed())
+		Expect(decodedClock).To(Equal(context.Clock))
+	})
+
+	It("should be stable and compact.", func() {
+		context := dy.NewContext(NewVectorClockFromMap(map[string]uint64{"s1": 300, "s0": 2}))
+		data, err := context.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal([]byte{
+			dy.CONTEXT_ENCODING_VERSION,
+			2, 2, 's', '0', 2, 0, 2, 's', '1', 0xac, 0x02, 0, // Elements sorted by node ID, without timestamps
+			0, 0, // Zero dot
+		}))
+		Expect(len(data)).To(BeNumerically("<", len(context.ToJSON())))
+	})
+
+	It("should identify contexts without their timestamps.", func() {
+		context1 := newEncodingTestContext("s0,s1", 1, 100, "", 0)
+		context2 := newEncodingTestContext("s0,s1", 1, 200, "", 0)
+		Expect(context1.Identity()).To(Equal(context2.Identity()))
+		Expect(dy.NewPutRecord("k0", context1)).To(Equal(dy.NewPutRecord("k0", context2)))
+
+		Expect(context1.Identity()).NotTo(Equal(newEncodingTestContext("s0,s1", 2, 100, "", 0).Identity()))
+		Expect(context1.Identity()).NotTo(Equal(newEncodingTestContext("s0,s1", 1, 100, "s0", 3).Identity()))
+	})
+
+	It("should be used by gob.", func() {
+		putArgs := dy.NewPutArgs("k0", newEncodingTestContext("s0,s1", 1, 100, "s1", 2), []byte("v0"))
+
+		var buf bytes.Buffer
+		Expect(gob.NewEncoder(&buf).Encode(putArgs)).To(Succeed())
+		var decoded dy.PutArgs
+		Expect(gob.NewDecoder(&buf).Decode(&decoded)).To(Succeed())
+		Expect(decoded).To(Equal(putArgs))
+	})
+
+	It("should reject invalid encodings.", func() {
+		valid, err := newEncodingTestContext("s0,s1", 1, 100, "s1", 2).MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+
+		MapTestCases([][]string{
+			{"empty", ""},
+			{"unsupported version", "\x02" + string(valid[1:])},
+			{"truncated", string(valid[:len(valid)-1])},
+			{"trailing data", string(valid) + "\x00"},
+			{"duplicate node ID", "\x01\x02\x02s0\x01\x00\x02s0\x02\x00\x00\x00"},
+			{"missing dot", "\x01\x00"},
+			{"too many elements", "\x01\xff\x01"},
+		}, func(i int, c []string) {
+			var context dy.Context
+			Expect(context.UnmarshalBinary([]byte(c[1]))).NotTo(Succeed(), c[0])
+		})
+	})
+
+	It("should round-trip context tokens.", func() {
+		context := newEncodingTestContext("s0,s1", 1, 100, "s2", 3)
+		decoded, err := dy.DecodeContextToken(dy.EncodeContextToken(context))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decoded.Clock.Equals(context.Version())).To(BeTrue())
+		Expect(decoded.Clock.Timestamps).To(Equal(context.Clock.Timestamps))
+
+		_, err = dy.DecodeContextToken(base64.RawURLEncoding.EncodeToString([]byte(`{"s0":1,"s1":2}`)))
+		Expect(err).To(HaveOccurred())
+	})
+
+	Describe("Recovery", func() {
+		var dataDir string
+
+		BeforeEach(func() {
+			var err error
+			dataDir, err = ioutil.TempDir("", "mydynamo-encoding-test")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.MkdirAll(filepath.Join(dataDir, "s0"), 0755)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			var _ = os.RemoveAll(dataDir)
+		})
+
+		It("should refuse to recover from a snapshot that cannot be decoded.", func() {
+			payload := []byte("not a NodeSnapshot")
+			path := filepath.Join(dataDir, "s0", fmt.Sprintf(dy.SNAPSHOT_FILE_PATTERN, 2))
+			Expect(ioutil.WriteFile(path, append([]byte("MDSN"), withChecksum(payload)...), 0644)).To(Succeed())
+
+			config := dy.NewDynamoConfig()
+			config.DataDir = dataDir
+			server := dy.NewDynamoServerWithConfig(config, "localhost", "0", "s0")
+			Expect(server.Recover()).NotTo(Succeed())
+		})
+	})
+})
+
+// Encodes and decodes the context built from the arguments, which must round-trip to an equal context
+func FuzzContextRoundTrip(f *testing.F) {
+	f.Add("", uint64(0), int64(0), "", uint64(0))
+	f.Add("s0", uint64(1), int64(1), "", uint64(0))
+	f.Add("s0,s1,s2", uint64(1)<<63, int64(-1)<<63, "s1", uint64(1)<<63)
+	f.Add("localhost:8080,localhost:8081", uint64(300), int64(1600000000000000000), "localhost:8080", uint64(2))
+
+	f.Fuzz(func(t *testing.T, nodeIDs string, counter uint64, timestamp int64, dotNodeID string, dotCounter uint64) {
+		context := newEncodingTestContext(nodeIDs, counter, timestamp, dotNodeID, dotCounter)
+		data, err := context.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var decoded dy.Context
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("failed to decode %x: %v", data, err)
+		}
+		if !reflect.DeepEqual(decoded, context) {
+			t.Fatalf("decoded %+v, want %+v", decoded, context)
+		}
+		if !bytes.Equal(mustMarshalContext(t, decoded), data) {
+			t.Fatalf("encoding of decoded %+v differs from %x", decoded, data)
+		}
+	})
+}
+
+// Decodes arbitrary data, which must not panic, and must encode back to the data if it is decoded
+func FuzzContextDecode(f *testing.F) {
+	for _, context := range []dy.Context{
+		newEncodingTestContext("", 0, 0, "", 0),
+		newEncodingTestContext("s0,s1", 1, 100, "s1", 2),
+	} {
+		data, _ := context.MarshalBinary()
+		f.Add(data)
+	}
+	f.Add([]byte{dy.CONTEXT_ENCODING_VERSION, 0xff})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var context dy.Context
+		if err := context.UnmarshalBinary(data); err != nil {
+			return
+		}
+
+		var decoded dy.Context
+		if err := decoded.UnmarshalBinary(mustMarshalContext(t, context)); err != nil {
+			t.Fatalf("failed to decode the encoding of %+v: %v", context, err)
+		}
+		if !bytes.Equal(mustMarshalContext(t, decoded), mustMarshalContext(t, context)) {
+			t.Fatalf("decoded %+v, want %+v", decoded, context)
+		}
+	})
+}
+
+// Returns the binary encoding of the context
+func mustMarshalContext(t *testing.T, context dy.Context) []byte {
+	data, err := context.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
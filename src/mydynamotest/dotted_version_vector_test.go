@@ -0,0 +1,176 @@
+package mydynamotest
+
+import (
+	"fmt"
+	dy "mydynamo"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dotted Version Vectors", func() {
+	// Returns a context with the clock and the dot
+	newDottedContext := func(clock map[string]uint64, nodeID string, counter uint64) dy.Context {
+		context := dy.NewContext(NewVectorClockFromMap(clock))
+		context.Dot = dy.Dot{NodeID: nodeID, Counter: counter}
+		return context
+	}
+
+	Describe("Contexts", func() {
+		It("should include their dot in their version.", func() {
+			context := newDottedContext(map[string]uint64{"s0": 1, "s1": 2}, "s0", 3)
+			Expect(context.Version().NodeClocks).To(Equal(map[string]uint64{"s0": 3, "s1": 2}))
+			Expect(dy.NewContext(NewVectorClockFromMap(map[string]uint64{"s0": 1})).Version().NodeClocks).To(
+				Equal(map[string]uint64{"s0": 1}))
+		})
+
+		It("should be equal iff their dots are.", func() {
+			context1 := newDottedContext(map[string]uint64{}, "s0", 2)
+			context2 := newDottedContext(map[string]uint64{"s0": 1}, "s0", 2)
+			Expect(context1.Equals(context2)).To(BeTrue())
+			Expect(context1.Equals(newDottedContext(map[string]uint64{}, "s0", 1))).To(BeFalse())
+		})
+
+		It("should be superseded by the contexts whose clocks include their dot.", func() {
+			context := newDottedContext(map[string]uint64{}, "s0", 2)
+			Expect(context.LessThan(newDottedContext(map[string]uint64{"s0": 2}, "s0", 3))).To(BeTrue())
+			Expect(context.LessThan(newDottedContext(map[string]uint64{"s0": 2, "s1": 1}, "s1", 2))).To(BeTrue())
+		})
+
+		It("should be concurrent to later dots of the same node not including their dot.", func() {
+			// Both values were put through s0 with a context seeing only the first update of s0
+			context1 := newDottedContext(map[string]uint64{"s0": 1}, "s0", 2)
+			context2 := newDottedContext(map[string]uint64{"s0": 1}, "s0", 3)
+			Expect(context1.Concurrent(context2)).To(BeTrue())
+			Expect(context2.Concurrent(context1)).To(BeTrue())
+
+			// Vector clocks cannot tell them apart from a later update
+			Expect(context1.Version().LessThan(context2.Version())).To(BeTrue())
+		})
+	})
+
+	Describe("Stale contexts with R=3, W=3, ClusterSize=3", func() {
+		const clientsNum int = 3
+		const roundsNum int = 5
+
+		var sc ServerCoordinator
+		var causality string
+
+		JustBeforeEach(func() {
+			sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 3, 3, 3, map[string]string{
+				dy.CAUSALITY: causality,
+			})
+		})
+
+		AfterEach(func() {
+			sc.Kill()
+		})
+
+		// Returns the context combining the versions of the siblings, to put a value replacing them
+		readContext := func(res *dy.DynamoResult) dy.Context {
+			clock := dy.NewVectorClock()
+			for _, entry := range res.EntryList {
+				clock.Combine([]dy.VectorClock{entry.Context.Version()})
+			}
+			return dy.NewContext(clock)
+		}
+
+		// Lets each client read the key, and then put its own value through s0 with the context it read, for the
+		// rounds. Returns the siblings after each round.
+		readModifyWrite := func(rounds int) [][][]byte {
+			siblings := make([][][]byte, 0, rounds)
+			for round := 0; round < rounds; round++ {
+				contexts := make([]dy.Context, clientsNum)
+				for i := range contexts {
+					contexts[i] = readContext(sc.GetClient(i % 3).Get("k0"))
+				}
+				for i, context := range contexts {
+					value := []byte(fmt.Sprintf("r%dc%d", round, i))
+					Expect(sc.GetClient(0).Put(dy.NewPutArgs("k0", context, value))).To(BeTrue())
+				}
+				siblings = append(siblings, GetEntryValues(sc.GetClient(1).Get("k0")))
+			}
+			return siblings
+		}
+
+		// Returns the values the clients put in the round
+		valuesOfRound := func(round int) [][]byte {
+			values := make([][]byte, 0, clientsNum)
+			for i := 0; i < clientsNum; i++ {
+				values = append(values, []byte(fmt.Sprintf("r%dc%d", round, i)))
+			}
+			return values
+		}
+
+		Context("with vector clocks", func() {
+			BeforeEach(func() {
+				causality = dy.CAUSALITY_VECTOR_CLOCK
+			})
+
+			It("should lose the concurrent updates through the same coordinator.", func() {
+				for round, values := range readModifyWrite(roundsNum) {
+					// Every put after the first of a round gets the same clock, and is dropped as a duplicate
+					Expect(values).To(ConsistOf(valuesOfRound(round)[:1]))
+				}
+			})
+
+			It("should keep the concurrent updates through different coordinators.", func() {
+				for i := 0; i < clientsNum; i++ {
+					Expect(sc.GetClient(i).Put(MakePutFreshEntry("k0", []byte(fmt.Sprintf("r0c%d", i))))).To(BeTrue())
+				}
+				Expect(GetEntryValues(sc.GetClient(0).Get("k0"))).To(ConsistOf(valuesOfRound(0)))
+			})
+		})
+
+		Context("with dotted version vectors", func() {
+			BeforeEach(func() {
+				causality = dy.CAUSALITY_DOTTED_VERSION_VECTOR
+			})
+
+			It("should keep the concurrent updates through the same coordinator.", func() {
+				for round, values := range readModifyWrite(roundsNum) {
+					// Each round replaces exactly the siblings the clients read, so siblings do not pile up
+					Expect(values).To(ConsistOf(valuesOfRound(round)))
+				}
+			})
+
+			It("should only replace the siblings in the causal context.", func() {
+				for i := 0; i < clientsNum; i++ {
+					Expect(sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte(fmt.Sprintf("r0c%d", i))))).To(BeTrue())
+				}
+				res := sc.GetClient(1).Get("k0")
+				Expect(GetEntryValues(res)).To(ConsistOf(valuesOfRound(0)))
+
+				// Putting with the context of a single sibling replaces it only
+				replaced := res.EntryList[0]
+				Expect(sc.GetClient(2).Put(MakePutFromEntry("k0", dy.ObjectEntry{
+					Context: replaced.Context,
+					Value:   []byte("v1"),
+				}))).To(BeTrue())
+
+				expected := [][]byte{[]byte("v1")}
+				for _, entry := range res.EntryList[1:] {
+					expected = append(expected, entry.Value)
+				}
+				Expect(GetEntryValues(sc.GetClient(0).Get("k0"))).To(ConsistOf(expected))
+			})
+
+			It("should replicate the dots by gossip.", func() {
+				sc.GetClient(2).ForceCrash()
+				for i := 0; i < clientsNum; i++ {
+					Expect(sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte(fmt.Sprintf("r0c%d", i))))).To(BeFalse())
+				}
+				sc.GetClient(2).ForceRestore()
+				sc.GetClient(0).Gossip()
+
+				var res dy.DynamoResult
+				Expect(sc.GetClient(2).GetRaw("k0", &res)).To(BeTrue())
+				Expect(GetEntryValues(&res)).To(ConsistOf(valuesOfRound(0)))
+				for _, entry := range res.EntryList {
+					Expect(entry.Context.Dot.NodeID).To(Equal(sc.GetID(0)))
+				}
+			})
+		})
+	})
+})
@@ -0,0 +1,205 @@
+package mydynamotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DynamoDB API", func() {
+	var sc ServerCoordinator
+
+	// Sends the operation to the ith server and decodes the JSON response, returns the status code
+	call := func(i int, operation string, body string, response interface{}) int {
+		request, err := http.NewRequest(http.MethodPost, "http://localhost:"+strconv.Itoa(sc.StartingPort+i)+"/",
+			bytes.NewBufferString(body))
+		Expect(err).NotTo(HaveOccurred())
+		request.Header.Set("Content-Type", "application/x-amz-json-1.0")
+		request.Header.Set("X-Amz-Target", "DynamoDB_20120810."+operation)
+		resp, err := http.DefaultClient.Do(request)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.Header.Get("Content-Type")).To(Equal("application/x-amz-json-1.0"))
+		Expect(json.NewDecoder(resp.Body).Decode(response)).To(Succeed())
+		return resp.StatusCode
+	}
+
+	// Returns the type of the error response without its prefix
+	errorType := func(response map[string]interface{}) string {
+		return response["__type"].(string)[len("com.amazonaws.dynamodb.v20120810#"):]
+	}
+
+	createTable := func(i int, name string) {
+		var response map[string]interface{}
+		Expect(call(i, "CreateTable", `{
+			"TableName": "`+name+`",
+			"KeySchema": [{"AttributeName": "id", "KeyType": "HASH"}],
+			"AttributeDefinitions": [{"AttributeName": "id", "AttributeType": "S"}]
+		}`, &response)).To(Equal(http.StatusOK), "%v", response)
+	}
+
+	BeforeEach(func() {
+		sc = NewServerCoordinator(8000+config.GinkgoConfig.ParallelNode*100, 2, 2, 3)
+	})
+
+	AfterEach(func() {
+		sc.Kill()
+	})
+
+	It("should create and list tables.", func() {
+		var createResponse struct {
+			TableDescription struct {
+				TableName   string
+				TableStatus string
+				KeySchema   []struct{ AttributeName, KeyType string }
+			}
+		}
+		Expect(call(0, "CreateTable", `{
+			"TableName": "users",
+			"KeySchema": [{"AttributeName": "id", "KeyType": "HASH"}],
+			"AttributeDefinitions": [{"AttributeName": "id", "AttributeType": "S"}]
+		}`, &createResponse)).To(Equal(http.StatusOK))
+		Expect(createResponse.TableDescription.TableName).To(Equal("users"))
+		Expect(createResponse.TableDescription.TableStatus).To(Equal("ACTIVE"))
+		Expect(createResponse.TableDescription.KeySchema[0].KeyType).To(Equal("HASH"))
+		createTable(1, "orders")
+
+		var errorResponse map[string]interface{}
+		Expect(call(2, "CreateTable", `{
+			"TableName": "users",
+			"KeySchema": [{"AttributeName": "id", "KeyType": "HASH"}],
+			"AttributeDefinitions": [{"AttributeName": "id", "AttributeType": "S"}]
+		}`, &errorResponse)).To(Equal(http.StatusBadRequest))
+		Expect(errorType(errorResponse)).To(Equal("ResourceInUseException"))
+
+		var listResponse struct {
+			TableNames             []string
+			LastEvaluatedTableName string
+		}
+		Expect(call(2, "ListTables", `{}`, &listResponse)).To(Equal(http.StatusOK))
+		Expect(listResponse.TableNames).To(Equal([]string{"orders", "users"}))
+		Expect(listResponse.LastEvaluatedTableName).To(BeEmpty())
+
+		Expect(call(0, "ListTables", `{"Limit": 1}`, &listResponse)).To(Equal(http.StatusOK))
+		Expect(listResponse.TableNames).To(Equal([]string{"orders"}))
+		Expect(listResponse.LastEvaluatedTableName).To(Equal("orders"))
+		listResponse.LastEvaluatedTableName = ""
+		Expect(call(0, "ListTables", `{"Limit": 1, "ExclusiveStartTableName": "orders"}`, &listResponse)).To(Equal(http.StatusOK))
+		Expect(listResponse.TableNames).To(Equal([]string{"users"}))
+		Expect(listResponse.LastEvaluatedTableName).To(BeEmpty())
+	})
+
+	It("should put, get and delete items through any server.", func() {
+		createTable(0, "users")
+
+		var response map[string]interface{}
+		Expect(call(0, "PutItem", `{
+			"TableName": "users",
+			"Item": {"id": {"S": "u1"}, "name": {"S": "Alice"}, "age": {"N": "30"}}
+		}`, &response)).To(Equal(http.StatusOK))
+		Expect(response).To(BeEmpty())
+
+		var getResponse map[string]map[string]map[string]interface{}
+		Expect(call(1, "GetItem", `{"TableName": "users", "Key": {"id": {"S": "u1"}}}`, &getResponse)).To(Equal(http.StatusOK))
+		Expect(getResponse["Item"]).To(Equal(map[string]map[string]interface{}{
+			"id":   {"S": "u1"},
+			"name": {"S": "Alice"},
+			"age":  {"N": "30"},
+		}))
+
+		var putResponse map[string]map[string]map[string]interface{}
+		Expect(call(2, "PutItem", `{
+			"TableName": "users",
+			"Item": {"id": {"S": "u1"}, "name": {"S": "Bob"}},
+			"ReturnValues": "ALL_OLD"
+		}`, &putResponse)).To(Equal(http.StatusOK))
+		Expect(putResponse["Attributes"]["name"]).To(Equal(map[string]interface{}{"S": "Alice"}))
+
+		getResponse = nil
+		Expect(call(0, "GetItem", `{"TableName": "users", "Key": {"id": {"S": "u1"}}}`, &getResponse)).To(Equal(http.StatusOK))
+		Expect(getResponse["Item"]).To(Equal(map[string]map[string]interface{}{
+			"id":   {"S": "u1"},
+			"name": {"S": "Bob"},
+		}))
+
+		var deleteResponse map[string]map[string]map[string]interface{}
+		Expect(call(1, "DeleteItem", `{
+			"TableName": "users",
+			"Key": {"id": {"S": "u1"}},
+			"ReturnValues": "ALL_OLD"
+		}`, &deleteResponse)).To(Equal(http.StatusOK))
+		Expect(deleteResponse["Attributes"]["name"]).To(Equal(map[string]interface{}{"S": "Bob"}))
+
+		for i := 0; i < 3; i++ {
+			getResponse = nil
+			Expect(call(i, "GetItem", `{"TableName": "users", "Key": {"id": {"S": "u1"}}}`, &getResponse)).To(Equal(http.StatusOK))
+			Expect(getResponse).NotTo(HaveKey("Item"))
+		}
+	})
+
+	It("should key items by their hash and range keys.", func() {
+		var response map[string]interface{}
+		Expect(call(0, "CreateTable", `{
+			"TableName": "events",
+			"KeySchema": [{"AttributeName": "user", "KeyType": "HASH"}, {"AttributeName": "time", "KeyType": "RANGE"}],
+			"AttributeDefinitions": [{"AttributeName": "user", "AttributeType": "S"}, {"AttributeName": "time", "AttributeType": "N"}]
+		}`, &response)).To(Equal(http.StatusOK))
+
+		for _, t := range []string{"1", "2"} {
+			Expect(call(0, "PutItem", `{
+				"TableName": "events",
+				"Item": {"user": {"S": "u1"}, "time": {"N": "`+t+`"}, "event": {"S": "e`+t+`"}}
+			}`, &response)).To(Equal(http.StatusOK))
+		}
+
+		for _, t := range []string{"1", "2"} {
+			var getResponse map[string]map[string]map[string]interface{}
+			Expect(call(1, "GetItem", `{
+				"TableName": "events",
+				"Key": {"user": {"S": "u1"}, "time": {"N": "`+t+`"}}
+			}`, &getResponse)).To(Equal(http.StatusOK))
+			Expect(getResponse["Item"]["event"]).To(Equal(map[string]interface{}{"S": "e" + t}))
+		}
+
+		Expect(call(1, "GetItem", `{"TableName": "events", "Key": {"user": {"S": "u1"}}}`, &response)).To(Equal(http.StatusBadRequest))
+		Expect(errorType(response)).To(Equal("ValidationException"))
+	})
+
+	It("should reject invalid requests.", func() {
+		createTable(0, "users")
+
+		MapTestCases([][]string{
+			{"GetItem", `{"TableName": "missing", "Key": {"id": {"S": "u1"}}}`, "ResourceNotFoundException"},
+			{"GetItem", `{"TableName": "users", "Key": {"id": {"N": "1"}}}`, "ValidationException"},
+			{"PutItem", `{"TableName": "users", "Item": {"name": {"S": "Alice"}}}`, "ValidationException"},
+			{"PutItem", `{"TableName": "users", "Item": {"id": {"S": "u1"}}, "ReturnValues": "ALL_NEW"}`, "ValidationException"},
+			{"CreateTable", `{"TableName": "t", "KeySchema": [{"AttributeName": "id", "KeyType": "HASH"}]}`, "ValidationException"},
+			{"CreateTable", `{"TableName": "users2", "KeySchema": [{"AttributeName": "id", "KeyType": "RANGE"}],
+				"AttributeDefinitions": [{"AttributeName": "id", "AttributeType": "S"}]}`, "ValidationException"},
+			{"PutItem", `{"TableName": `, "SerializationException"},
+			{"Scan", `{"TableName": "users"}`, "UnknownOperationException"},
+		}, func(i int, c []string) {
+			var response map[string]interface{}
+			Expect(call(i%3, c[0], c[1], &response)).To(Equal(http.StatusBadRequest), c[1])
+			Expect(errorType(response)).To(Equal(c[2]), c[1])
+		})
+	})
+
+	It("should report unavailable servers.", func() {
+		createTable(0, "users")
+		sc.GetClient(1).ForceCrash()
+		sc.GetClient(2).ForceCrash()
+
+		var response map[string]interface{}
+		Expect(call(0, "GetItem", `{"TableName": "users", "Key": {"id": {"S": "u1"}}}`, &response)).To(Equal(http.StatusServiceUnavailable))
+		Expect(errorType(response)).To(Equal("ServiceUnavailable"))
+
+		Expect(call(1, "ListTables", `{}`, &response)).To(Equal(http.StatusServiceUnavailable))
+		Expect(errorType(response)).To(Equal("ServiceUnavailable"))
+	})
+})
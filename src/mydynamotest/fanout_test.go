@@ -0,0 +1,92 @@
+package mydynamotest
+
+import (
+	dy "mydynamo"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parallel Fan-Out", func() {
+
+	Describe("R=2, W=2, ClusterSize=3, RequestTimeout=200ms", func() {
+		var sc ServerCoordinator
+
+		BeforeEach(func() {
+			// StartingPort: 8000, R-Value: 2, W-Value: 2, ClusterSize: 3
+			sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 2, 2, 3, map[string]string{
+				dy.REQUEST_TIMEOUT: "200",
+			})
+		})
+
+		AfterEach(func() {
+			sc.Kill()
+		})
+
+		It("should bound the latency of Put and Get under a slow server.", func() {
+			sc.GetClient(1).ForceDelay(3000)
+
+			start := time.Now()
+			Expect(sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))).To(BeTrue())
+			Expect(time.Since(start)).To(BeNumerically("<", 1500*time.Millisecond))
+
+			start = time.Now()
+			res := sc.GetClient(0).Get("k0")
+			Expect(time.Since(start)).To(BeNumerically("<", 1500*time.Millisecond))
+			Expect(res).NotTo(BeNil())
+			Expect(GetEntryValues(res)).To(ConsistOf([][]byte{
+				[]byte("v0"),
+			}))
+		})
+
+		It("should let the slow server finish in the background.", func() {
+			sc.GetClient(1).ForceDelay(1000)
+			Expect(sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))).To(BeTrue())
+			sc.GetClient(1).ForceDelay(0)
+
+			Eventually(func() [][]byte {
+				var res dy.DynamoResult
+				Expect(sc.GetClient(1).GetRaw("k0", &res)).To(BeTrue())
+				return GetEntryValues(&res)
+			}, 5*time.Second, 100*time.Millisecond).Should(ConsistOf([][]byte{
+				[]byte("v0"),
+			}))
+		})
+	})
+
+	Describe("R=1, W=3, ClusterSize=3", func() {
+		var sc ServerCoordinator
+
+		BeforeEach(func() {
+			// StartingPort: 8000, R-Value: 1, W-Value: 3, ClusterSize: 3
+			sc = NewServerCoordinator(8000+config.GinkgoConfig.ParallelNode*100, 1, 3, 3)
+		})
+
+		AfterEach(func() {
+			sc.Kill()
+		})
+
+		It("should put to the other servers concurrently.", func() {
+			sc.GetClient(1).ForceDelay(800)
+			sc.GetClient(2).ForceDelay(800)
+
+			start := time.Now()
+			Expect(sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))).To(BeTrue())
+			Expect(time.Since(start)).To(BeNumerically(">=", 800*time.Millisecond))
+			Expect(time.Since(start)).To(BeNumerically("<", 1500*time.Millisecond))
+		})
+	})
+
+	Describe("Config", func() {
+		It("should load request timeout.", func() {
+			config := dy.NewDynamoConfig()
+			Expect(config.RequestTimeoutMs).To(Equal(dy.DEFAULT_REQUEST_TIMEOUT_MS))
+			Expect(config.SetOption(dy.REQUEST_TIMEOUT, "500")).To(Succeed())
+			Expect(config.RequestTimeoutMs).To(Equal(500))
+
+			Expect(config.SetOption(dy.REQUEST_TIMEOUT, "-1")).NotTo(Succeed())
+		})
+	})
+})
@@ -0,0 +1,163 @@
+package mydynamotest
+
+import (
+	"context"
+	dy "mydynamo"
+	"net/rpc"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ = Describe("gRPC", func() {
+	var sc ServerCoordinator
+	var clients []*dy.GRPCClient
+
+	// Returns a gRPC client of the server, which is closed after the test
+	getGRPCClient := func(i int, portOffset int) *dy.GRPCClient {
+		client := dy.NewDynamoGRPCClient("localhost:" + strconv.Itoa(sc.StartingPort+i+portOffset))
+		Expect(client.Connect()).To(Succeed())
+		clients = append(clients, client)
+		return client
+	}
+
+	BeforeEach(func() {
+		clients = nil
+	})
+
+	AfterEach(func() {
+		for _, client := range clients {
+			_ = client.Close()
+		}
+		sc.Kill()
+	})
+
+	Context("with both transports", func() {
+		BeforeEach(func() {
+			sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 1, 1, 3, map[string]string{
+				"transport":             "both",
+				"replication_transport": "grpc",
+				"grpc_port_offset":      "30",
+			})
+		})
+
+		It("should serve the same data through RPC and gRPC.", func() {
+			ctx := context.Background()
+			Expect(sc.GetClient(0).Put(MakePutFreshEntry("s0", []byte("abcde")))).To(BeTrue())
+
+			result, err := getGRPCClient(0, 30).Get(ctx, "s0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(GetEntryValues(result)).To(Equal([][]byte{[]byte("abcde")}))
+			Expect(result.Replies).To(Equal(1))
+
+			putResult, err := getGRPCClient(1, 30).Put(ctx, MakePutFromEntry("s1", result.EntryList[0]))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(putResult.Success).To(BeTrue())
+			Expect(putResult.Acks).To(Equal(1))
+			Expect(GetEntryValues(sc.GetClient(1).Get("s1"))).To(Equal([][]byte{[]byte("abcde")}))
+		})
+
+		It("should replicate through gRPC streams when gossiping.", func() {
+			ctx := context.Background()
+			Expect(sc.GetClient(0).Put(MakePutFreshEntry("s0", []byte("abcde")))).To(BeTrue())
+			Expect(getGRPCClient(0, 30).Gossip(ctx)).To(Succeed())
+
+			for i := 1; i < 3; i++ {
+				var result dy.DynamoResult
+				Expect(sc.GetClient(i).GetRaw("s0", &result)).To(BeTrue())
+				Expect(GetEntryValues(&result)).To(Equal([][]byte{[]byte("abcde")}))
+			}
+		})
+
+		It("should put a stream of entries.", func() {
+			client := getGRPCClient(2, 30)
+			results, err := client.PutRawStream(context.Background(), []dy.PutArgs{
+				MakePutFreshEntry("s0", []byte("abcde")),
+				MakePutFreshEntry("s1", []byte("fghij")),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal([]bool{true, true}))
+
+			result, err := client.GetRaw(context.Background(), "s1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(GetEntryValues(result)).To(Equal([][]byte{[]byte("fghij")}))
+		})
+
+		It("should stop waiting for a slow server after the deadline.", func() {
+			sc.GetClient(0).ForceDelay(1000)
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			_, err := getGRPCClient(0, 30).Get(ctx, "s0")
+			Expect(status.Code(err)).To(Equal(codes.DeadlineExceeded))
+		})
+
+		It("should return the errors of a crashed server.", func() {
+			ctx := context.Background()
+			client := getGRPCClient(0, 30)
+			Expect(client.ForceCrash(ctx)).To(Succeed())
+
+			_, err := client.Get(ctx, "s0")
+			Expect(err).To(BeAssignableToTypeOf(rpc.ServerError("")))
+
+			Expect(client.ForceRestore(ctx)).To(Succeed())
+			_, err = client.Get(ctx, "s0")
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("with a request timeout", func() {
+		BeforeEach(func() {
+			sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 1, 3, 3, map[string]string{
+				"transport":             "both",
+				"replication_transport": "grpc",
+				"grpc_port_offset":      "30",
+				dy.REQUEST_TIMEOUT:      "200",
+			})
+		})
+
+		It("should time out the replication calls to a slow server.", func() {
+			sc.GetClient(1).ForceDelay(3000)
+
+			start := time.Now()
+			res := sc.GetClient(0).PutWithResult(MakePutFreshEntry("s0", []byte("abcde")))
+			Expect(time.Since(start)).To(BeNumerically("<", 1500*time.Millisecond))
+			Expect(res.Success).To(BeFalse())
+			Expect(res.Acks).To(Equal(2))
+			Expect(res.Failures).To(HaveLen(1))
+			Expect(res.Failures[0].Node.Port).To(Equal(strconv.Itoa(sc.StartingPort + 1)))
+			Expect(res.Failures[0].Reason).To(Equal("request timed out"))
+			Expect(res.Failures[0].Unreachable).To(BeFalse())
+		})
+	})
+
+	Context("with gRPC only", func() {
+		BeforeEach(func() {
+			sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 2, 2, 3, map[string]string{
+				"transport":             "grpc",
+				"replication_transport": "grpc",
+			})
+		})
+
+		It("should put and get values replicated through gRPC.", func() {
+			ctx := context.Background()
+			putResult, err := getGRPCClient(0, 0).Put(ctx, MakePutFreshEntry("s0", []byte("abcde")))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(putResult.Success).To(BeTrue())
+			Expect(putResult.Acks).To(Equal(2))
+
+			result, err := getGRPCClient(1, 0).GetRaw(ctx, "s0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(GetEntryValues(result)).To(Equal([][]byte{[]byte("abcde")}))
+
+			result, err = getGRPCClient(2, 0).Get(ctx, "s0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(GetEntryValues(result)).To(Equal([][]byte{[]byte("abcde")}))
+		})
+	})
+})
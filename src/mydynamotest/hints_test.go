@@ -0,0 +1,163 @@
+package mydynamotest
+
+import (
+	"io/ioutil"
+	dy "mydynamo"
+	"os"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Hinted Handoff", func() {
+
+	Describe("R=1, W=2, N=2, ClusterSize=4", func() {
+		var sc ServerCoordinator
+		var handoffIntervalMs int
+
+		JustBeforeEach(func() {
+			// StartingPort: 8000, R-Value: 1, W-Value: 2, ClusterSize: 4
+			sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 1, 2, 4, map[string]string{
+				dy.N_VALUE:                 "2",
+				dy.SLOPPY_QUORUM:           "true",
+				dy.HINTED_HANDOFF_INTERVAL: strconv.Itoa(handoffIntervalMs),
+			})
+		})
+
+		AfterEach(func() {
+			sc.Kill()
+		})
+
+		Context("without background handoff", func() {
+			BeforeEach(func() {
+				handoffIntervalMs = 0
+			})
+
+			It("should put hint to the next node when a node is crashed.", func() {
+				sc.GetClient(1).ForceCrash()
+				Expect(sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))).To(BeTrue())
+
+				hints := sc.GetClient(2).GetHints()
+				Expect(hints).To(HaveLen(1))
+				Expect(hints[0].Owner).To(Equal(dy.NewDynamoNode("localhost", strconv.Itoa(sc.StartingPort+1))))
+				Expect(hints[0].PutArgs.Key).To(Equal("k0"))
+				Expect(hints[0].PutArgs.Value).To(Equal([]byte("v0")))
+				Expect(sc.GetClient(3).GetHints()).To(BeEmpty())
+
+				// Hints are not local entries of the node keeping them
+				var res dy.DynamoResult
+				Expect(sc.GetClient(2).GetRaw("k0", &res)).To(BeTrue())
+				Expect(GetEntryValues(&res)).To(BeEmpty())
+			})
+
+			It("should keep hints until the node is restored.", func() {
+				sc.GetClient(1).ForceCrash()
+				Expect(sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))).To(BeTrue())
+
+				Expect(sc.GetClient(2).DeliverHints()).To(Equal(0))
+				Expect(sc.GetClient(2).GetHints()).To(HaveLen(1))
+
+				sc.GetClient(1).ForceRestore()
+				Expect(sc.GetClient(2).DeliverHints()).To(Equal(1))
+				Expect(sc.GetClient(2).GetHints()).To(BeEmpty())
+
+				var res dy.DynamoResult
+				Expect(sc.GetClient(1).GetRaw("k0", &res)).To(BeTrue())
+				Expect(GetEntryValues(&res)).To(ConsistOf([][]byte{
+					[]byte("v0"),
+				}))
+			})
+
+			It("should not put more hints than needed for W.", func() {
+				sc.GetClient(1).ForceCrash()
+				sc.GetClient(2).ForceCrash()
+				Expect(sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))).To(BeTrue())
+
+				hints := sc.GetClient(3).GetHints()
+				Expect(hints).To(HaveLen(1))
+				Expect(hints[0].Owner).To(Equal(dy.NewDynamoNode("localhost", strconv.Itoa(sc.StartingPort+1))))
+			})
+		})
+
+		Context("with background handoff", func() {
+			BeforeEach(func() {
+				handoffIntervalMs = 100
+			})
+
+			It("should hand off hints once the node is restored.", func() {
+				sc.GetClient(1).ForceCrash()
+				Expect(sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))).To(BeTrue())
+				Expect(sc.GetClient(2).GetHints()).To(HaveLen(1))
+
+				sc.GetClient(1).ForceRestore()
+				Eventually(func() [][]byte {
+					var res dy.DynamoResult
+					Expect(sc.GetClient(1).GetRaw("k0", &res)).To(BeTrue())
+					return GetEntryValues(&res)
+				}, 5*time.Second, 100*time.Millisecond).Should(ConsistOf([][]byte{
+					[]byte("v0"),
+				}))
+				Eventually(func() []dy.Hint {
+					return sc.GetClient(2).GetHints()
+				}, 5*time.Second, 100*time.Millisecond).Should(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Hint Store", func() {
+		var dataDir string
+
+		BeforeEach(func() {
+			var err error
+			dataDir, err = ioutil.TempDir("", "mydynamo-hints-test")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			var _ = os.RemoveAll(dataDir)
+		})
+
+		makeHint := func(port string, key string, vectorClockMap map[string]uint64, value []byte) dy.Hint {
+			return dy.Hint{
+				Owner:   dy.NewDynamoNode("localhost", port),
+				PutArgs: MakePutFromVectorClockMapAndValue(key, vectorClockMap, value),
+			}
+		}
+
+		It("should restore hints after reopen.", func() {
+			hints := dy.NewHintStore(dataDir)
+			Expect(hints.Open()).To(Succeed())
+			Expect(hints.Add(makeHint("8001", "k0", map[string]uint64{"s0": 1}, []byte("v0")))).To(Succeed())
+			Expect(hints.Add(makeHint("8002", "k0", map[string]uint64{"s0": 1}, []byte("v0")))).To(Succeed())
+			Expect(hints.Close()).To(Succeed())
+
+			hints = dy.NewHintStore(dataDir)
+			Expect(hints.Open()).To(Succeed())
+			defer hints.Close()
+			list, err := hints.List()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(list).To(HaveLen(2))
+		})
+
+		It("should drop superseded hints.", func() {
+			hints := dy.NewHintStore("")
+			Expect(hints.Open()).To(Succeed())
+			Expect(hints.Add(makeHint("8001", "k0", map[string]uint64{"s0": 1}, []byte("v0-0")))).To(Succeed())
+			Expect(hints.Add(makeHint("8001", "k0", map[string]uint64{"s0": 2}, []byte("v0-1")))).To(Succeed())
+			Expect(hints.Add(makeHint("8001", "k0", map[string]uint64{"s0": 1}, []byte("v0-0")))).To(Succeed())
+
+			list, err := hints.List()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(list).To(HaveLen(1))
+			Expect(list[0].PutArgs.Value).To(Equal([]byte("v0-1")))
+
+			Expect(hints.Remove(list[0])).To(Succeed())
+			list, err = hints.List()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(list).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,146 @@
+package mydynamotest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	dy "mydynamo"
+	"net/http"
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTTP Gateway", func() {
+	var sc ServerCoordinator
+
+	type sibling struct {
+		Value   string
+		Context string
+	}
+	type getResponse struct {
+		Key      string
+		Siblings []sibling
+		Context  string
+		Failures []struct{ Node string }
+	}
+	type putResponse struct {
+		Success bool
+		Acks    int
+	}
+	type errorResponse struct {
+		Error string
+	}
+
+	url := func(i int, key string) string {
+		return "http://localhost:" + strconv.Itoa(sc.StartingPort+i) + "/kv/" + key
+	}
+
+	// Sends the request and decodes the JSON response, returns the status code
+	do := func(method string, url string, body string, response interface{}) int {
+		request, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+		Expect(err).NotTo(HaveOccurred())
+		resp, err := http.DefaultClient.Do(request)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(json.NewDecoder(resp.Body).Decode(response)).To(Succeed())
+		return resp.StatusCode
+	}
+
+	put := func(i int, key string, value string, context string, response interface{}) int {
+		body, err := json.Marshal(map[string]string{
+			"value":   base64.StdEncoding.EncodeToString([]byte(value)),
+			"context": context,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return do(http.MethodPut, url(i, key), string(body), response)
+	}
+
+	values := func(siblings []sibling) []string {
+		decoded := make([]string, 0)
+		for _, s := range siblings {
+			value, err := base64.StdEncoding.DecodeString(s.Value)
+			Expect(err).NotTo(HaveOccurred())
+			decoded = append(decoded, string(value))
+		}
+		return decoded
+	}
+
+	BeforeEach(func() {
+		sc = NewServerCoordinator(8000+config.GinkgoConfig.ParallelNode*100, 2, 2, 3)
+	})
+
+	AfterEach(func() {
+		sc.Kill()
+	})
+
+	It("should put and get values with context tokens.", func() {
+		var get getResponse
+		Expect(do(http.MethodGet, url(0, "k1"), "", &get)).To(Equal(http.StatusNotFound))
+		Expect(get.Siblings).To(BeEmpty())
+
+		var putRes putResponse
+		Expect(put(0, "k1", "v1", "", &putRes)).To(Equal(http.StatusOK))
+		Expect(putRes).To(Equal(putResponse{Success: true, Acks: 2}))
+
+		Expect(do(http.MethodGet, url(1, "k1"), "", &get)).To(Equal(http.StatusOK))
+		Expect(get.Key).To(Equal("k1"))
+		Expect(values(get.Siblings)).To(ConsistOf("v1"))
+
+		Expect(put(1, "k1", "v2", get.Context, &putRes)).To(Equal(http.StatusOK))
+		Expect(do(http.MethodGet, url(0, "k1"), "", &get)).To(Equal(http.StatusOK))
+		Expect(values(get.Siblings)).To(ConsistOf("v2"))
+
+		context, err := dy.DecodeContextToken(get.Siblings[0].Context)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(context.Clock.NodeClocks).To(Equal(map[string]uint64{"s0": 1, "s1": 1}))
+	})
+
+	It("should return and resolve siblings.", func() {
+		var putRes putResponse
+		Expect(put(0, "k1", "v1", "", &putRes)).To(Equal(http.StatusOK))
+		Expect(put(1, "k1", "v2", "", &putRes)).To(Equal(http.StatusOK))
+
+		var get getResponse
+		Expect(do(http.MethodGet, url(2, "k1"), "", &get)).To(Equal(http.StatusOK))
+		Expect(values(get.Siblings)).To(ConsistOf("v1", "v2"))
+		Expect(get.Siblings[0].Context).NotTo(Equal(get.Siblings[1].Context))
+
+		Expect(put(2, "k1", "v3", get.Context, &putRes)).To(Equal(http.StatusOK))
+		Expect(do(http.MethodGet, url(0, "k1"), "", &get)).To(Equal(http.StatusOK))
+		Expect(values(get.Siblings)).To(ConsistOf("v3"))
+	})
+
+	It("should return 503 when fewer than W or R servers respond.", func() {
+		sc.GetClient(1).ForceCrash()
+		sc.GetClient(2).ForceCrash()
+
+		var putRes putResponse
+		Expect(put(0, "k1", "v1", "", &putRes)).To(Equal(http.StatusServiceUnavailable))
+		Expect(putRes).To(Equal(putResponse{Success: false, Acks: 1}))
+
+		var get getResponse
+		Expect(do(http.MethodGet, url(0, "k1"), "", &get)).To(Equal(http.StatusServiceUnavailable))
+		Expect(get.Failures).To(HaveLen(2))
+
+		var errRes errorResponse
+		Expect(do(http.MethodGet, url(1, "k1"), "", &errRes)).To(Equal(http.StatusServiceUnavailable))
+		Expect(errRes.Error).NotTo(BeEmpty())
+	})
+
+	It("should reject malformed requests.", func() {
+		var errRes errorResponse
+		Expect(put(0, "k1", "v1", "not a token", &errRes)).To(Equal(http.StatusBadRequest))
+		Expect(errRes.Error).To(HavePrefix("invalid context"))
+
+		Expect(do(http.MethodPut, url(0, "k1"), `{"value": "%"}`, &errRes)).To(Equal(http.StatusBadRequest))
+		Expect(errRes.Error).To(HavePrefix("invalid value"))
+
+		Expect(do(http.MethodPut, url(0, "k1"), "", &errRes)).To(Equal(http.StatusBadRequest))
+		Expect(do(http.MethodDelete, url(0, "k1"), "", &errRes)).To(Equal(http.StatusMethodNotAllowed))
+		Expect(do(http.MethodGet, url(0, ""), "", &errRes)).To(Equal(http.StatusNotFound))
+	})
+})
@@ -0,0 +1,161 @@
+package mydynamotest
+
+import (
+	dy "mydynamo"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Membership", func() {
+
+	Describe("Failure Detector", func() {
+		self := dy.NewDynamoNode("localhost", "8000")
+		peer := dy.NewDynamoNode("localhost", "8001")
+
+		statusOf := func(membership *dy.Membership, node dy.DynamoNode) string {
+			membership.Detect()
+			return membership.Status(node)
+		}
+
+		It("should suspect and then declare dead nodes without new heartbeats.", func() {
+			membership := dy.NewMembership(self, 100*time.Millisecond, 300*time.Millisecond)
+			membership.AddNodes([]dy.DynamoNode{self, peer})
+			Expect(statusOf(membership, peer)).To(Equal(dy.MEMBER_ALIVE))
+
+			Eventually(func() string {
+				return statusOf(membership, peer)
+			}, time.Second, 10*time.Millisecond).Should(Equal(dy.MEMBER_SUSPECT))
+			Eventually(func() string {
+				return statusOf(membership, peer)
+			}, time.Second, 10*time.Millisecond).Should(Equal(dy.MEMBER_DEAD))
+			Expect(statusOf(membership, self)).To(Equal(dy.MEMBER_ALIVE))
+		})
+
+		It("should revive nodes with newer heartbeats only.", func() {
+			membership := dy.NewMembership(self, 10*time.Millisecond, 20*time.Millisecond)
+			membership.Merge([]dy.Member{{Node: peer, Generation: 2, Heartbeat: 5}})
+			time.Sleep(50 * time.Millisecond)
+			Expect(statusOf(membership, peer)).To(Equal(dy.MEMBER_DEAD))
+
+			membership.Merge([]dy.Member{{Node: peer, Generation: 2, Heartbeat: 5}})
+			membership.Merge([]dy.Member{{Node: peer, Generation: 1, Heartbeat: 9}})
+			Expect(statusOf(membership, peer)).To(Equal(dy.MEMBER_DEAD))
+
+			membership.Merge([]dy.Member{{Node: peer, Generation: 3, Heartbeat: 0}})
+			Expect(statusOf(membership, peer)).To(Equal(dy.MEMBER_ALIVE))
+		})
+
+		It("should evict dead nodes outside the cluster until their heartbeats increase.", func() {
+			membership := dy.NewMembership(self, 10*time.Millisecond, 20*time.Millisecond)
+			membership.SetNodes([]dy.DynamoNode{self})
+			membership.Merge([]dy.Member{{Node: peer, Generation: 1, Heartbeat: 5}})
+			Expect(membership.Members()).To(HaveLen(2))
+
+			time.Sleep(50 * time.Millisecond)
+			membership.Detect()
+			Expect(membership.Members()).To(HaveLen(1))
+
+			// Views of other nodes that have not evicted the node yet do not add it back
+			membership.Merge([]dy.Member{{Node: peer, Generation: 1, Heartbeat: 5}})
+			Expect(membership.Members()).To(HaveLen(1))
+
+			membership.Merge([]dy.Member{{Node: peer, Generation: 1, Heartbeat: 6}})
+			Expect(statusOf(membership, peer)).To(Equal(dy.MEMBER_ALIVE))
+			Expect(membership.Members()).To(HaveLen(2))
+		})
+
+		It("should sort nodes by liveness.", func() {
+			other := dy.NewDynamoNode("localhost", "8002")
+			membership := dy.NewMembership(self, 10*time.Millisecond, 20*time.Millisecond)
+			membership.AddNodes([]dy.DynamoNode{peer})
+			time.Sleep(50 * time.Millisecond)
+			membership.Detect()
+			membership.Merge([]dy.Member{{Node: other, Heartbeat: 1}})
+
+			Expect(membership.SortByLiveness([]dy.DynamoNode{peer, other})).To(Equal([]dy.DynamoNode{other, peer}))
+		})
+	})
+
+	Describe("R=1, W=2, ClusterSize=3, HeartbeatInterval=50ms", func() {
+		var sc ServerCoordinator
+
+		BeforeEach(func() {
+			// StartingPort: 8000, R-Value: 1, W-Value: 2, ClusterSize: 3
+			sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 1, 2, 3, map[string]string{
+				dy.HEARTBEAT_INTERVAL: "50",
+				dy.SUSPECT_TIMEOUT:    "300",
+				dy.DEAD_TIMEOUT:       "600",
+			})
+		})
+
+		AfterEach(func() {
+			sc.Kill()
+		})
+
+		statusOf := func(i int, j int) string {
+			for _, member := range sc.GetClient(i).GetMembership() {
+				if member.Node.Port == strconv.Itoa(sc.StartingPort+j) {
+					return member.Status
+				}
+			}
+			return ""
+		}
+
+		It("should track the heartbeats of all servers.", func() {
+			Eventually(func() []uint64 {
+				heartbeats := make([]uint64, 0)
+				for _, member := range sc.GetClient(0).GetMembership() {
+					Expect(member.Status).To(Equal(dy.MEMBER_ALIVE))
+					heartbeats = append(heartbeats, member.Heartbeat)
+				}
+				return heartbeats
+			}, 5*time.Second, 50*time.Millisecond).Should(And(HaveLen(3), Not(ContainElement(uint64(0)))))
+		})
+
+		It("should detect crashed servers and their recovery.", func() {
+			sc.GetClient(2).ForceCrash()
+			Eventually(func() string {
+				return statusOf(0, 2)
+			}, 5*time.Second, 50*time.Millisecond).Should(Equal(dy.MEMBER_DEAD))
+			Expect(statusOf(1, 2)).To(Or(Equal(dy.MEMBER_SUSPECT), Equal(dy.MEMBER_DEAD)))
+
+			sc.GetClient(2).ForceRestore()
+			Eventually(func() string {
+				return statusOf(0, 2)
+			}, 5*time.Second, 50*time.Millisecond).Should(Equal(dy.MEMBER_ALIVE))
+		})
+
+		It("should prefer alive servers for quorum.", func() {
+			sc.GetClient(1).ForceCrash()
+			Eventually(func() string {
+				return statusOf(0, 1)
+			}, 5*time.Second, 50*time.Millisecond).Should(Equal(dy.MEMBER_DEAD))
+
+			res := sc.GetClient(0).PutWithResult(MakePutFreshEntry("k0", []byte("v0")))
+			Expect(res).NotTo(BeNil())
+			Expect(res.Success).To(BeTrue())
+			Expect(res.Failures).To(BeEmpty())
+		})
+	})
+
+	Describe("Config", func() {
+		It("should load heartbeat options.", func() {
+			config := dy.NewDynamoConfig()
+			Expect(config.SetOption(dy.HEARTBEAT_INTERVAL, "100")).To(Succeed())
+			Expect(config.SetOption(dy.SUSPECT_TIMEOUT, "1000")).To(Succeed())
+			Expect(config.SetOption(dy.DEAD_TIMEOUT, "2000")).To(Succeed())
+			Expect(config.HeartbeatIntervalMs).To(Equal(100))
+			Expect(config.SuspectTimeoutMs).To(Equal(1000))
+			Expect(config.DeadTimeoutMs).To(Equal(2000))
+			Expect(config.Validate()).To(Succeed())
+
+			Expect(config.SetOption(dy.SUSPECT_TIMEOUT, "0")).NotTo(Succeed())
+			Expect(config.SetOption(dy.SUSPECT_TIMEOUT, "3000")).To(Succeed())
+			Expect(config.Validate()).NotTo(Succeed())
+		})
+	})
+})
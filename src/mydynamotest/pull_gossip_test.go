@@ -0,0 +1,111 @@
+package mydynamotest
+
+import (
+	dy "mydynamo"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pull Gossip", func() {
+
+	Describe("R=1, W=1, ClusterSize=2, GossipMode=pull", func() {
+		var sc ServerCoordinator
+
+		BeforeEach(func() {
+			// StartingPort: 8000, R-Value: 1, W-Value: 1, ClusterSize: 2
+			sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 1, 1, 2, map[string]string{
+				dy.GOSSIP_MODE: dy.GOSSIP_MODE_PULL,
+			})
+		})
+
+		AfterEach(func() {
+			sc.Kill()
+		})
+
+		It("should replicate remote entries to local.", func() {
+			sc.GetClient(1).Put(MakePutFreshEntry("k1", []byte("v1")))
+			sc.GetClient(0).Gossip()
+
+			res := sc.GetClient(0).Get("k1")
+			Expect(res).NotTo(BeNil())
+			Expect(GetEntryValues(res)).To(ConsistOf([][]byte{
+				[]byte("v1"),
+			}))
+		})
+
+		It("should not replicate local entries to remote.", func() {
+			sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))
+			sc.GetClient(0).Gossip()
+
+			res := sc.GetClient(1).Get("k0")
+			Expect(res).NotTo(BeNil())
+			Expect(GetEntryValues(res)).To(BeEmpty())
+		})
+
+		It("should catch up after being restored.", func() {
+			sc.GetClient(0).ForceCrash()
+			sc.GetClient(1).Put(MakePutFreshEntry("k1", []byte("v1")))
+			sc.GetClient(1).Gossip()
+			sc.GetClient(0).ForceRestore()
+
+			res := sc.GetClient(0).Get("k1")
+			Expect(res).NotTo(BeNil())
+			Expect(GetEntryValues(res)).To(BeEmpty())
+
+			sc.GetClient(0).Gossip()
+			res = sc.GetClient(0).Get("k1")
+			Expect(res).NotTo(BeNil())
+			Expect(GetEntryValues(res)).To(ConsistOf([][]byte{
+				[]byte("v1"),
+			}))
+		})
+	})
+
+	Describe("R=1, W=1, ClusterSize=2, GossipMode=push-pull", func() {
+		var sc ServerCoordinator
+
+		BeforeEach(func() {
+			// StartingPort: 8000, R-Value: 1, W-Value: 1, ClusterSize: 2
+			sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 1, 1, 2, map[string]string{
+				dy.GOSSIP_MODE: dy.GOSSIP_MODE_PUSH_PULL,
+			})
+		})
+
+		AfterEach(func() {
+			sc.Kill()
+		})
+
+		It("should replicate entries in both directions.", func() {
+			sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))
+			sc.GetClient(1).Put(MakePutFreshEntry("k1", []byte("v1")))
+			sc.GetClient(0).Gossip()
+
+			for i := 0; i < 2; i++ {
+				res := sc.GetClient(i).Get("k0")
+				Expect(res).NotTo(BeNil())
+				Expect(GetEntryValues(res)).To(ConsistOf([][]byte{
+					[]byte("v0"),
+				}))
+
+				res = sc.GetClient(i).Get("k1")
+				Expect(res).NotTo(BeNil())
+				Expect(GetEntryValues(res)).To(ConsistOf([][]byte{
+					[]byte("v1"),
+				}))
+			}
+		})
+	})
+
+	Describe("Config", func() {
+		It("should load gossip mode.", func() {
+			config := dy.NewDynamoConfig()
+			Expect(config.GossipMode).To(Equal(dy.GOSSIP_MODE_PUSH))
+			Expect(config.SetOption(dy.GOSSIP_MODE, dy.GOSSIP_MODE_PUSH_PULL)).To(Succeed())
+			Expect(config.GossipMode).To(Equal(dy.GOSSIP_MODE_PUSH_PULL))
+
+			Expect(config.SetOption(dy.GOSSIP_MODE, "pushpull")).NotTo(Succeed())
+		})
+	})
+})
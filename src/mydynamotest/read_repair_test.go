@@ -0,0 +1,141 @@
+package mydynamotest
+
+import (
+	dy "mydynamo"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Read Repair", func() {
+
+	getRawValues := func(client *dy.RPCClient, key string) [][]byte {
+		var res dy.DynamoResult
+		Expect(client.GetRaw(key, &res)).To(BeTrue())
+		return GetEntryValues(&res)
+	}
+
+	Describe("R=3, W=1, ClusterSize=3, ReadRepair=sync", func() {
+		var sc ServerCoordinator
+
+		BeforeEach(func() {
+			// StartingPort: 8000, R-Value: 3, W-Value: 1, ClusterSize: 3
+			sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 3, 1, 3, map[string]string{
+				dy.READ_REPAIR: dy.READ_REPAIR_SYNC,
+			})
+		})
+
+		AfterEach(func() {
+			sc.Kill()
+		})
+
+		It("should write merged entries back to stale replicas.", func() {
+			sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))
+			Expect(getRawValues(sc.GetClient(1), "k0")).To(BeEmpty())
+
+			res := sc.GetClient(0).Get("k0")
+			Expect(res).NotTo(BeNil())
+			Expect(GetEntryValues(res)).To(ConsistOf([][]byte{[]byte("v0")}))
+
+			for i := 0; i < 3; i++ {
+				Expect(getRawValues(sc.GetClient(i), "k0")).To(ConsistOf([][]byte{[]byte("v0")}))
+			}
+			stats := sc.GetClient(0).GetReplicationStats()
+			Expect(stats).NotTo(BeNil())
+			Expect(stats.ReadRepairs).To(Equal(int64(2)))
+			Expect(stats.ReadRepairFailures).To(BeZero())
+		})
+
+		It("should repair the coordinating replica with concurrent entries.", func() {
+			sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0-0")))
+			sc.GetClient(1).Put(MakePutFreshEntry("k0", []byte("v0-1")))
+
+			res := sc.GetClient(2).Get("k0")
+			Expect(res).NotTo(BeNil())
+			Expect(GetEntryValues(res)).To(ConsistOf([][]byte{[]byte("v0-0"), []byte("v0-1")}))
+
+			for i := 0; i < 3; i++ {
+				Expect(getRawValues(sc.GetClient(i), "k0")).To(ConsistOf([][]byte{[]byte("v0-0"), []byte("v0-1")}))
+			}
+			stats := sc.GetClient(2).GetReplicationStats()
+			Expect(stats).NotTo(BeNil())
+			Expect(stats.ReadRepairs).To(Equal(int64(4)))
+		})
+
+		It("should not repair up-to-date replicas.", func() {
+			sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))
+			sc.GetClient(0).Get("k0")
+			sc.GetClient(0).Get("k0")
+
+			stats := sc.GetClient(0).GetReplicationStats()
+			Expect(stats).NotTo(BeNil())
+			Expect(stats.ReadRepairs).To(Equal(int64(2)))
+		})
+	})
+
+	Describe("R=3, W=1, ClusterSize=3, ReadRepair=async", func() {
+		var sc ServerCoordinator
+
+		BeforeEach(func() {
+			// StartingPort: 8000, R-Value: 3, W-Value: 1, ClusterSize: 3
+			sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 3, 1, 3, map[string]string{
+				dy.READ_REPAIR: dy.READ_REPAIR_ASYNC,
+			})
+		})
+
+		AfterEach(func() {
+			sc.Kill()
+		})
+
+		It("should write merged entries back to stale replicas eventually.", func() {
+			sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))
+			sc.GetClient(0).Get("k0")
+
+			for i := 1; i < 3; i++ {
+				client := sc.GetClient(i)
+				Eventually(func() [][]byte {
+					return getRawValues(client, "k0")
+				}, 5*time.Second, 50*time.Millisecond).Should(ConsistOf([][]byte{[]byte("v0")}))
+			}
+			Eventually(func() int64 {
+				return sc.GetClient(0).GetReplicationStats().ReadRepairs
+			}, 5*time.Second, 50*time.Millisecond).Should(Equal(int64(2)))
+		})
+	})
+
+	Describe("R=3, W=1, ClusterSize=3", func() {
+		var sc ServerCoordinator
+
+		BeforeEach(func() {
+			// StartingPort: 8000, R-Value: 3, W-Value: 1, ClusterSize: 3
+			sc = NewServerCoordinator(8000+config.GinkgoConfig.ParallelNode*100, 3, 1, 3)
+		})
+
+		AfterEach(func() {
+			sc.Kill()
+		})
+
+		It("should not repair replicas by default.", func() {
+			sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))
+			sc.GetClient(0).Get("k0")
+
+			Expect(getRawValues(sc.GetClient(1), "k0")).To(BeEmpty())
+			stats := sc.GetClient(0).GetReplicationStats()
+			Expect(stats).NotTo(BeNil())
+			Expect(stats.ReadRepairs).To(BeZero())
+		})
+	})
+
+	Describe("Config", func() {
+		It("should load read repair mode.", func() {
+			config := dy.NewDynamoConfig()
+			Expect(config.ReadRepair).To(Equal(dy.READ_REPAIR_OFF))
+			Expect(config.SetOption(dy.READ_REPAIR, dy.READ_REPAIR_ASYNC)).To(Succeed())
+			Expect(config.ReadRepair).To(Equal(dy.READ_REPAIR_ASYNC))
+
+			Expect(config.SetOption(dy.READ_REPAIR, "on")).NotTo(Succeed())
+		})
+	})
+})
@@ -0,0 +1,155 @@
+package mydynamotest
+
+import (
+	dy "mydynamo"
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Replication Factor", func() {
+
+	Describe("R=1, W=1, N=2, ClusterSize=4", func() {
+		var sc ServerCoordinator
+
+		BeforeEach(func() {
+			// StartingPort: 8000, R-Value: 1, W-Value: 1, ClusterSize: 4
+			sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 1, 1, 4, map[string]string{
+				dy.N_VALUE: "2",
+			})
+		})
+
+		AfterEach(func() {
+			sc.Kill()
+		})
+
+		It("should only gossip entries to the top N nodes.", func() {
+			for k := 0; k < 10; k++ {
+				key := "k" + strconv.Itoa(k)
+				Expect(sc.GetClient(k % sc.ClusterSize).Put(MakePutFreshEntry(key, []byte("v")))).To(BeTrue())
+			}
+			for i := 0; i < sc.ClusterSize; i++ {
+				sc.GetClient(i).Gossip()
+			}
+
+			// All servers agree on the top N nodes of each key, so gossip does not spread keys past them
+			for k := 0; k < 10; k++ {
+				key := "k" + strconv.Itoa(k)
+				storedCount := 0
+				for i := 0; i < sc.ClusterSize; i++ {
+					var res dy.DynamoResult
+					Expect(sc.GetClient(i).GetRaw(key, &res)).To(BeTrue())
+					if len(res.EntryList) > 0 {
+						Expect(GetEntryValues(&res)).To(ConsistOf([][]byte{[]byte("v")}))
+						storedCount++
+					}
+				}
+				Expect(storedCount).To(Equal(2), key)
+			}
+		})
+	})
+
+	Describe("R=1, W=2, N=2, ClusterSize=5, VirtualNodes=16", func() {
+		var sc ServerCoordinator
+
+		BeforeEach(func() {
+			// StartingPort: 8000, R-Value: 1, W-Value: 2, ClusterSize: 5
+			sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 1, 2, 5, map[string]string{
+				dy.N_VALUE:       "2",
+				dy.VIRTUAL_NODES: "16",
+			})
+		})
+
+		AfterEach(func() {
+			sc.Kill()
+		})
+
+		// Returns the indices of servers in the top N nodes of the key
+		topNodeIndices := func(key string, n int) map[int]bool {
+			nodes := make([]dy.DynamoNode, 0)
+			for i := 0; i < sc.ClusterSize; i++ {
+				nodes = append(nodes, dy.NewDynamoNode("localhost", strconv.Itoa(sc.StartingPort+i)))
+			}
+			ring := dy.NewHashRing(nodes, 16)
+
+			indices := make(map[int]bool)
+			for _, node := range ring.PreferenceList(key, n) {
+				port, _ := strconv.Atoi(node.Port)
+				indices[port-sc.StartingPort] = true
+			}
+			return indices
+		}
+
+		It("should only store entries in the top N nodes of the key.", func() {
+			for k := 0; k < 10; k++ {
+				key := "k" + strconv.Itoa(k)
+				// Put through any server, the Put is forwarded if the server is not in the top N nodes
+				Expect(sc.GetClient(k % sc.ClusterSize).Put(MakePutFreshEntry(key, []byte("v")))).To(BeTrue())
+			}
+			for i := 0; i < sc.ClusterSize; i++ {
+				sc.GetClient(i).Gossip()
+			}
+
+			for k := 0; k < 10; k++ {
+				key := "k" + strconv.Itoa(k)
+				indices := topNodeIndices(key, 2)
+				Expect(indices).To(HaveLen(2))
+
+				for i := 0; i < sc.ClusterSize; i++ {
+					var res dy.DynamoResult
+					Expect(sc.GetClient(i).GetRaw(key, &res)).To(BeTrue())
+					if indices[i] {
+						Expect(GetEntryValues(&res)).To(ConsistOf([][]byte{[]byte("v")}))
+					} else {
+						Expect(GetEntryValues(&res)).To(BeEmpty())
+					}
+				}
+			}
+		})
+
+		It("should get entries through servers not in the top N nodes of the key.", func() {
+			Expect(sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))).To(BeTrue())
+
+			for i := 0; i < sc.ClusterSize; i++ {
+				res := sc.GetClient(i).Get("k0")
+				Expect(res).NotTo(BeNil())
+				Expect(GetEntryValues(res)).To(ConsistOf([][]byte{
+					[]byte("v0"),
+				}))
+			}
+		})
+	})
+
+	Describe("Config", func() {
+		It("should default N to the cluster size.", func() {
+			config := dy.NewDynamoConfig()
+			Expect(config.SetOption(dy.CLUSTER_SIZE, "5")).To(Succeed())
+			Expect(config.ReplicationFactor()).To(Equal(5))
+			Expect(config.SetOption(dy.N_VALUE, "3")).To(Succeed())
+			Expect(config.ReplicationFactor()).To(Equal(3))
+		})
+
+		It("should require R, W <= N <= cluster size.", func() {
+			config := dy.NewDynamoConfig()
+			Expect(config.SetOption(dy.CLUSTER_SIZE, "5")).To(Succeed())
+			Expect(config.SetOption(dy.N_VALUE, "3")).To(Succeed())
+			Expect(config.SetOption(dy.R_VALUE, "2")).To(Succeed())
+			Expect(config.SetOption(dy.W_VALUE, "3")).To(Succeed())
+			Expect(config.Validate()).To(Succeed())
+
+			Expect(config.SetOption(dy.R_VALUE, "4")).To(Succeed())
+			Expect(config.Validate()).NotTo(Succeed())
+
+			Expect(config.SetOption(dy.R_VALUE, "2")).To(Succeed())
+			Expect(config.SetOption(dy.W_VALUE, "4")).To(Succeed())
+			Expect(config.Validate()).NotTo(Succeed())
+
+			Expect(config.SetOption(dy.N_VALUE, "6")).To(Succeed())
+			Expect(config.Validate()).NotTo(Succeed())
+
+			Expect(config.SetOption(dy.N_VALUE, "0")).NotTo(Succeed())
+		})
+	})
+})
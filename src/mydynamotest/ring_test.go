@@ -0,0 +1,128 @@
+package mydynamotest
+
+import (
+	dy "mydynamo"
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Hash Ring", func() {
+
+	// Returns the DynamoNodes listening on the given number of ports from the starting port
+	makeNodes := func(startingPort int, count int) []dy.DynamoNode {
+		nodes := make([]dy.DynamoNode, 0)
+		for i := 0; i < count; i++ {
+			nodes = append(nodes, dy.DynamoNode{Address: "localhost", Port: strconv.Itoa(startingPort + i)})
+		}
+		return nodes
+	}
+
+	It("should return distinct nodes for each key.", func() {
+		nodes := makeNodes(8080, 5)
+		ring := dy.NewHashRing(nodes, 16)
+
+		for i := 0; i < 100; i++ {
+			preferenceList := ring.PreferenceList("k"+strconv.Itoa(i), 0)
+			Expect(preferenceList).To(ConsistOf(nodes))
+
+			preferenceList = ring.PreferenceList("k"+strconv.Itoa(i), 3)
+			Expect(preferenceList).To(HaveLen(3))
+			Expect(nodes).To(ContainElements(preferenceList))
+		}
+	})
+
+	It("should return all nodes if N exceeds the number of nodes.", func() {
+		nodes := makeNodes(8080, 3)
+		ring := dy.NewHashRing(append(nodes, nodes[0]), 4)
+
+		Expect(ring.PreferenceList("k0", 5)).To(ConsistOf(nodes))
+	})
+
+	It("should not depend on the order of nodes.", func() {
+		nodes := makeNodes(8080, 5)
+		ring := dy.NewHashRing(nodes, 16)
+		rotatedRing := dy.NewHashRing(dy.RotateServerList(dy.RotateServerList(nodes)), 16)
+
+		for i := 0; i < 100; i++ {
+			key := "k" + strconv.Itoa(i)
+			Expect(rotatedRing.PreferenceList(key, 0)).To(Equal(ring.PreferenceList(key, 0)))
+		}
+	})
+
+	It("should spread keys over the nodes.", func() {
+		nodes := makeNodes(8080, 5)
+		ring := dy.NewHashRing(nodes, 64)
+
+		keyCounts := make(map[dy.DynamoNode]int)
+		for i := 0; i < 5000; i++ {
+			keyCounts[ring.PreferenceList("k"+strconv.Itoa(i), 1)[0]]++
+		}
+
+		Expect(keyCounts).To(HaveLen(5))
+		for _, node := range nodes {
+			Expect(keyCounts[node]).To(BeNumerically("~", 1000, 400))
+		}
+	})
+
+	Describe("R=1, W=2, ClusterSize=5, VirtualNodes=16", func() {
+		var sc ServerCoordinator
+
+		BeforeEach(func() {
+			// StartingPort: 8000, R-Value: 1, W-Value: 2, ClusterSize: 5
+			sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 1, 2, 5, map[string]string{
+				dy.VIRTUAL_NODES: "16",
+			})
+		})
+
+		AfterEach(func() {
+			sc.Kill()
+		})
+
+		It("should replicate put entry to the next node of the key on the ring.", func() {
+			nodes := makeNodes(sc.StartingPort, sc.ClusterSize)
+			ring := dy.NewHashRing(nodes, 16)
+
+			replicaIndices := make(map[int]bool)
+			for k := 0; k < 10; k++ {
+				key := "k" + strconv.Itoa(k)
+				sc.GetClient(0).Put(MakePutFreshEntry(key, []byte("v")))
+
+				// Server 0 coordinates the Put, and replicates the entry to the first other node of the key
+				replicaIndex := -1
+				for _, node := range ring.PreferenceList(key, 0) {
+					if node != nodes[0] {
+						replicaIndex, _ = strconv.Atoi(node.Port)
+						replicaIndex -= sc.StartingPort
+						break
+					}
+				}
+				replicaIndices[replicaIndex] = true
+
+				for i := 0; i < sc.ClusterSize; i++ {
+					res := sc.GetClient(i).Get(key)
+					Expect(res).NotTo(BeNil())
+					if i == 0 || i == replicaIndex {
+						Expect(GetEntryValues(res)).To(ConsistOf([][]byte{[]byte("v")}))
+					} else {
+						Expect(GetEntryValues(res)).To(BeEmpty())
+					}
+				}
+			}
+
+			// Keys are not all replicated to the same node
+			Expect(len(replicaIndices)).To(BeNumerically(">", 1))
+		})
+	})
+
+	Describe("Config", func() {
+		It("should reject negative virtual nodes.", func() {
+			config := dy.NewDynamoConfig()
+			Expect(config.SetOption(dy.VIRTUAL_NODES, "16")).To(Succeed())
+			Expect(config.VirtualNodes).To(Equal(16))
+			Expect(config.SetOption(dy.VIRTUAL_NODES, "-1")).NotTo(Succeed())
+		})
+	})
+})
@@ -0,0 +1,207 @@
+package mydynamotest
+
+import (
+	"io/ioutil"
+	dy "mydynamo"
+	"os"
+	"path/filepath"
+	"sort"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Snapshot", func() {
+
+	var dataDir string
+	var snapshotRetention int
+
+	// Create a server persisting its data in dataDir and recover its state from the disk
+	startServer := func() *dy.DynamoServer {
+		config := dy.NewDynamoConfig()
+		config.DataDir = dataDir
+		config.SnapshotRetention = snapshotRetention
+
+		server := dy.NewDynamoServerWithConfig(config, "localhost", "0", "s0")
+		Expect(server.Recover()).To(Succeed())
+		return &server
+	}
+
+	put := func(server *dy.DynamoServer, putArgs dy.PutArgs) {
+		var result bool
+		Expect(server.Put(putArgs, &result)).To(Succeed())
+		Expect(result).To(BeTrue())
+	}
+
+	get := func(server *dy.DynamoServer, key string) *dy.DynamoResult {
+		var result dy.DynamoResult
+		Expect(server.Get(key, &result)).To(Succeed())
+		return &result
+	}
+
+	// Returns the sorted paths of files in the node's data directory matching the pattern
+	nodeFiles := func(pattern string) []string {
+		paths, err := filepath.Glob(filepath.Join(dataDir, "s0", pattern))
+		Expect(err).NotTo(HaveOccurred())
+		sort.Strings(paths)
+		return paths
+	}
+
+	BeforeEach(func() {
+		var err error
+		dataDir, err = ioutil.TempDir("", "mydynamo-snapshot-test")
+		Expect(err).NotTo(HaveOccurred())
+		snapshotRetention = 1
+	})
+
+	AfterEach(func() {
+		var _ = os.RemoveAll(dataDir)
+	})
+
+	It("should restore entries from snapshot and the log after it.", func() {
+		server := startServer()
+		put(server, MakePutFreshEntry("k0", []byte("v0")))
+		Expect(server.TakeSnapshot()).To(Succeed())
+		put(server, MakePutFreshEntry("k1", []byte("v1")))
+
+		server = startServer()
+		Expect(GetEntryValues(get(server, "k0"))).To(ConsistOf([][]byte{
+			[]byte("v0"),
+		}))
+		Expect(GetEntryValues(get(server, "k1"))).To(ConsistOf([][]byte{
+			[]byte("v1"),
+		}))
+	})
+
+	It("should not restore entries superseded after snapshot.", func() {
+		server := startServer()
+		put(server, MakePutFreshEntry("k0", []byte("v0-0")))
+		Expect(server.TakeSnapshot()).To(Succeed())
+		put(server, MakePutFromVectorClockMapAndValue("k0", map[string]uint64{"s0": 1}, []byte("v0-1")))
+
+		server = startServer()
+		Expect(GetEntryValues(get(server, "k0"))).To(ConsistOf([][]byte{
+			[]byte("v0-1"),
+		}))
+	})
+
+	It("should truncate the log up to the snapshot.", func() {
+		server := startServer()
+		put(server, MakePutFreshEntry("k0", []byte("v0")))
+		Expect(server.TakeSnapshot()).To(Succeed())
+		put(server, MakePutFreshEntry("k1", []byte("v1")))
+		Expect(server.TakeSnapshot()).To(Succeed())
+
+		Expect(nodeFiles("snapshot-*.snap")).To(HaveLen(1))
+		Expect(nodeFiles("wal-*.log")).To(HaveLen(1))
+
+		server = startServer()
+		Expect(GetEntryValues(get(server, "k0"))).To(ConsistOf([][]byte{
+			[]byte("v0"),
+		}))
+		Expect(GetEntryValues(get(server, "k1"))).To(ConsistOf([][]byte{
+			[]byte("v1"),
+		}))
+	})
+
+	It("should restore from snapshot after restart again.", func() {
+		server := startServer()
+		put(server, MakePutFreshEntry("k0", []byte("v0")))
+		Expect(server.TakeSnapshot()).To(Succeed())
+
+		server = startServer()
+		put(server, MakePutFreshEntry("k1", []byte("v1")))
+
+		server = startServer()
+		Expect(GetEntryValues(get(server, "k0"))).To(ConsistOf([][]byte{
+			[]byte("v0"),
+		}))
+		Expect(GetEntryValues(get(server, "k1"))).To(ConsistOf([][]byte{
+			[]byte("v1"),
+		}))
+	})
+
+	Context("when keeping 2 snapshots", func() {
+		BeforeEach(func() {
+			snapshotRetention = 2
+		})
+
+		It("should keep the log after the oldest kept snapshot.", func() {
+			server := startServer()
+			for i := 0; i < 3; i++ {
+				put(server, MakePutFreshEntry("k0", []byte("v0")))
+				Expect(server.TakeSnapshot()).To(Succeed())
+			}
+
+			Expect(nodeFiles("snapshot-*.snap")).To(HaveLen(2))
+			Expect(nodeFiles("wal-*.log")).To(HaveLen(2))
+		})
+
+		It("should restore from the older snapshot if the latest one is corrupted.", func() {
+			server := startServer()
+			put(server, MakePutFreshEntry("k0", []byte("v0")))
+			Expect(server.TakeSnapshot()).To(Succeed())
+			put(server, MakePutFreshEntry("k1", []byte("v1")))
+			Expect(server.TakeSnapshot()).To(Succeed())
+			put(server, MakePutFreshEntry("k2", []byte("v2")))
+
+			snapshotPaths := nodeFiles("snapshot-*.snap")
+			Expect(snapshotPaths).To(HaveLen(2))
+			data, err := ioutil.ReadFile(snapshotPaths[1])
+			Expect(err).NotTo(HaveOccurred())
+			data[len(data)-1] ^= 0xff
+			Expect(ioutil.WriteFile(snapshotPaths[1], data, 0644)).To(Succeed())
+
+			server = startServer()
+			Expect(GetEntryValues(get(server, "k0"))).To(ConsistOf([][]byte{
+				[]byte("v0"),
+			}))
+			Expect(GetEntryValues(get(server, "k1"))).To(ConsistOf([][]byte{
+				[]byte("v1"),
+			}))
+			Expect(GetEntryValues(get(server, "k2"))).To(ConsistOf([][]byte{
+				[]byte("v2"),
+			}))
+		})
+	})
+
+	It("should refuse to recover if no snapshot is valid.", func() {
+		server := startServer()
+		put(server, MakePutFreshEntry("k0", []byte("v0")))
+		Expect(server.TakeSnapshot()).To(Succeed())
+
+		snapshotPaths := nodeFiles("snapshot-*.snap")
+		Expect(snapshotPaths).To(HaveLen(1))
+		data, err := ioutil.ReadFile(snapshotPaths[0])
+		Expect(err).NotTo(HaveOccurred())
+		data[len(data)-1] ^= 0xff
+		Expect(ioutil.WriteFile(snapshotPaths[0], data, 0644)).To(Succeed())
+
+		config := dy.NewDynamoConfig()
+		config.DataDir = dataDir
+		server2 := dy.NewDynamoServerWithConfig(config, "localhost", "0", "s0")
+		Expect(server2.Recover()).NotTo(Succeed())
+	})
+
+	It("should fail when data directory is not configured.", func() {
+		server := dy.NewDynamoServer(1, 1, "localhost", "0", "s0")
+		Expect(server.TakeSnapshot()).NotTo(Succeed())
+	})
+
+	Describe("Config", func() {
+		It("should load snapshot interval and retention.", func() {
+			config := dy.NewDynamoConfig()
+			Expect(config.SetOption(dy.SNAPSHOT_INTERVAL, "60")).To(Succeed())
+			Expect(config.SetOption(dy.SNAPSHOT_RETENTION, "3")).To(Succeed())
+			Expect(config.SnapshotIntervalSeconds).To(Equal(60))
+			Expect(config.SnapshotRetention).To(Equal(3))
+		})
+
+		It("should reject invalid snapshot interval and retention.", func() {
+			config := dy.NewDynamoConfig()
+			Expect(config.SetOption(dy.SNAPSHOT_INTERVAL, "-1")).NotTo(Succeed())
+			Expect(config.SetOption(dy.SNAPSHOT_INTERVAL, "soon")).NotTo(Succeed())
+			Expect(config.SetOption(dy.SNAPSHOT_RETENTION, "0")).NotTo(Succeed())
+		})
+	})
+})
@@ -0,0 +1,231 @@
+package mydynamotest
+
+import (
+	"bytes"
+	"io/ioutil"
+	dy "mydynamo"
+	"os"
+	"path/filepath"
+	"sort"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Storage Engine", func() {
+
+	var dataDir string
+
+	// Returns a single entry with the given value and a vector clock of node s0
+	makeEntries := func(value []byte) []dy.ObjectEntry {
+		return []dy.ObjectEntry{{
+			Context: dy.NewContext(NewVectorClockFromMap(map[string]uint64{"s0": 1})),
+			Value:   value,
+		}}
+	}
+
+	// Create a disk storage in dataDir and open it
+	openDiskStorage := func() *dy.DiskStorageEngine {
+		storage := dy.NewDiskStorageEngine(dataDir)
+		Expect(storage.Open()).To(Succeed())
+		return storage
+	}
+
+	getValues := func(storage dy.StorageEngine, key string) [][]byte {
+		entries, err := storage.Get(key)
+		Expect(err).NotTo(HaveOccurred())
+		values := make([][]byte, 0)
+		for _, entry := range entries {
+			values = append(values, entry.Value)
+		}
+		return values
+	}
+
+	// Returns the sorted paths of the segment files of the disk storage
+	segmentPaths := func() []string {
+		paths, err := filepath.Glob(filepath.Join(dataDir, "storage-*.data"))
+		Expect(err).NotTo(HaveOccurred())
+		sort.Strings(paths)
+		return paths
+	}
+
+	BeforeEach(func() {
+		var err error
+		dataDir, err = ioutil.TempDir("", "mydynamo-storage-test")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		var _ = os.RemoveAll(dataDir)
+	})
+
+	for _, name := range []string{dy.STORAGE_ENGINE_MEMORY, dy.STORAGE_ENGINE_DISK} {
+		name := name
+
+		Context("with "+name+" storage engine", func() {
+			var storage dy.StorageEngine
+
+			BeforeEach(func() {
+				var err error
+				storage, err = dy.NewStorageEngine(name, dataDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(storage.Open()).To(Succeed())
+			})
+
+			AfterEach(func() {
+				Expect(storage.Close()).To(Succeed())
+			})
+
+			It("should get the entries put.", func() {
+				Expect(storage.Put("k0", makeEntries([]byte("v0")))).To(Succeed())
+				Expect(storage.Put("k1", makeEntries([]byte("v1")))).To(Succeed())
+				Expect(storage.Put("k0", makeEntries([]byte("v0-1")))).To(Succeed())
+
+				Expect(getValues(storage, "k0")).To(ConsistOf([][]byte{[]byte("v0-1")}))
+				Expect(getValues(storage, "k1")).To(ConsistOf([][]byte{[]byte("v1")}))
+				Expect(getValues(storage, "k2")).To(BeEmpty())
+				Expect(storage.GetKeys()).To(ConsistOf("k0", "k1"))
+			})
+
+			It("should delete the entries.", func() {
+				Expect(storage.Put("k0", makeEntries([]byte("v0")))).To(Succeed())
+				Expect(storage.Delete("k0")).To(Succeed())
+				Expect(storage.Delete("k1")).To(Succeed())
+
+				Expect(getValues(storage, "k0")).To(BeEmpty())
+				Expect(storage.GetKeys()).To(BeEmpty())
+			})
+		})
+	}
+
+	It("should fail to create unknown storage engine.", func() {
+		_, err := dy.NewStorageEngine("tape", dataDir)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail to create disk storage engine without data directory.", func() {
+		_, err := dy.NewStorageEngine(dy.STORAGE_ENGINE_DISK, "")
+		Expect(err).To(HaveOccurred())
+	})
+
+	Describe("Disk", func() {
+		It("should restore entries after reopen.", func() {
+			storage := openDiskStorage()
+			Expect(storage.Put("k0", makeEntries([]byte("v0")))).To(Succeed())
+			Expect(storage.Put("k1", makeEntries([]byte("v1")))).To(Succeed())
+			Expect(storage.Put("k1", makeEntries([]byte("v1-1")))).To(Succeed())
+			Expect(storage.Put("k2", makeEntries([]byte("v2")))).To(Succeed())
+			Expect(storage.Delete("k2")).To(Succeed())
+			Expect(storage.Close()).To(Succeed())
+
+			storage = openDiskStorage()
+			defer storage.Close()
+			Expect(getValues(storage, "k0")).To(ConsistOf([][]byte{[]byte("v0")}))
+			Expect(getValues(storage, "k1")).To(ConsistOf([][]byte{[]byte("v1-1")}))
+			Expect(storage.GetKeys()).To(ConsistOf("k0", "k1"))
+		})
+
+		It("should truncate torn record at the tail.", func() {
+			storage := openDiskStorage()
+			Expect(storage.Put("k0", makeEntries([]byte("v0")))).To(Succeed())
+			Expect(storage.Put("k1", makeEntries([]byte("v1")))).To(Succeed())
+			Expect(storage.Close()).To(Succeed())
+
+			paths := segmentPaths()
+			Expect(paths).To(HaveLen(1))
+			info, err := os.Stat(paths[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.Truncate(paths[0], info.Size()-3)).To(Succeed())
+
+			storage = openDiskStorage()
+			Expect(getValues(storage, "k0")).To(ConsistOf([][]byte{[]byte("v0")}))
+			Expect(getValues(storage, "k1")).To(BeEmpty())
+			Expect(storage.Put("k2", makeEntries([]byte("v2")))).To(Succeed())
+			Expect(storage.Close()).To(Succeed())
+
+			storage = openDiskStorage()
+			defer storage.Close()
+			Expect(getValues(storage, "k0")).To(ConsistOf([][]byte{[]byte("v0")}))
+			Expect(getValues(storage, "k2")).To(ConsistOf([][]byte{[]byte("v2")}))
+		})
+
+		It("should compact overwritten records.", func() {
+			storage := openDiskStorage()
+			value := bytes.Repeat([]byte("v"), 64*1024)
+			for i := 0; i < 40; i++ {
+				value[0] = byte(i)
+				Expect(storage.Put("k0", makeEntries(value))).To(Succeed())
+			}
+			Expect(storage.Close()).To(Succeed())
+
+			paths := segmentPaths()
+			Expect(paths).To(HaveLen(1))
+			info, err := os.Stat(paths[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Size()).To(BeNumerically("<", 40*64*1024/2))
+
+			storage = openDiskStorage()
+			defer storage.Close()
+			Expect(getValues(storage, "k0")).To(ConsistOf([][]byte{value}))
+		})
+	})
+
+	Describe("Server", func() {
+		// Create a server storing its entries with the disk storage engine and recover its state from the disk
+		startServer := func() *dy.DynamoServer {
+			config := dy.NewDynamoConfig()
+			config.DataDir = dataDir
+			Expect(config.SetOption(dy.STORAGE_ENGINE, dy.STORAGE_ENGINE_DISK)).To(Succeed())
+
+			server := dy.NewDynamoServerWithConfig(config, "localhost", "0", "s0")
+			Expect(server.Recover()).To(Succeed())
+			return &server
+		}
+
+		It("should restore entries from disk storage after restart.", func() {
+			server := startServer()
+			var result bool
+			Expect(server.Put(MakePutFreshEntry("k0", []byte("v0")), &result)).To(Succeed())
+			Expect(result).To(BeTrue())
+			Expect(server.Put(MakePutFromVectorClockMapAndValue("k0", map[string]uint64{"s0": 1}, []byte("v0-1")), &result)).To(Succeed())
+			Expect(result).To(BeTrue())
+
+			server = startServer()
+			var dynamoResult dy.DynamoResult
+			Expect(server.Get("k0", &dynamoResult)).To(Succeed())
+			Expect(GetEntryValues(&dynamoResult)).To(ConsistOf([][]byte{
+				[]byte("v0-1"),
+			}))
+
+			walPaths, err := filepath.Glob(filepath.Join(dataDir, "s0", "wal-*.log"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(walPaths).To(BeEmpty())
+		})
+	})
+
+	Describe("Config", func() {
+		It("should override storage engine of a node.", func() {
+			config := dy.NewDynamoConfig()
+			config.DataDir = dataDir
+			Expect(config.SetOption(dy.STORAGE_ENGINE+".1", dy.STORAGE_ENGINE_DISK)).To(Succeed())
+			Expect(config.StorageEngineOfNode("0")).To(Equal(dy.STORAGE_ENGINE_MEMORY))
+			Expect(config.StorageEngineOfNode("1")).To(Equal(dy.STORAGE_ENGINE_DISK))
+			Expect(config.Validate()).To(Succeed())
+		})
+
+		It("should reject unknown storage engine.", func() {
+			config := dy.NewDynamoConfig()
+			Expect(config.SetOption(dy.STORAGE_ENGINE, "tape")).NotTo(Succeed())
+			Expect(config.SetOption(dy.STORAGE_ENGINE+".0", "tape")).NotTo(Succeed())
+		})
+
+		It("should require data directory for disk storage engine.", func() {
+			config := dy.NewDynamoConfig()
+			Expect(config.SetOption(dy.STORAGE_ENGINE+".0", dy.STORAGE_ENGINE_DISK)).To(Succeed())
+			Expect(config.Validate()).NotTo(Succeed())
+
+			config.DataDir = dataDir
+			Expect(config.Validate()).To(Succeed())
+		})
+	})
+})
@@ -25,8 +25,19 @@ type ServerCoordinator struct {
 
 // Create (run) a new server coordinator process with given configs.
 func NewServerCoordinator(startingPort int, rValue int, wValue int, clusterSize int) ServerCoordinator {
-	coordinatorCmd := exec.Command("DynamoTestCoordinator",
-		strconv.Itoa(startingPort), strconv.Itoa(rValue), strconv.Itoa(wValue), strconv.Itoa(clusterSize))
+	return NewServerCoordinatorWithOptions(startingPort, rValue, wValue, clusterSize, nil)
+}
+
+// Create (run) a new server coordinator process with given configs and extra config options.
+// The options are labeled as in the config file, e.g. {"virtual_nodes": "16"}.
+func NewServerCoordinatorWithOptions(
+	startingPort int, rValue int, wValue int, clusterSize int, options map[string]string,
+) ServerCoordinator {
+	args := []string{strconv.Itoa(startingPort), strconv.Itoa(rValue), strconv.Itoa(wValue), strconv.Itoa(clusterSize)}
+	for label, value := range options {
+		args = append(args, label+"="+value)
+	}
+	coordinatorCmd := exec.Command("DynamoTestCoordinator", args...)
 
 	session, err := gexec.Start(coordinatorCmd, GinkgoWriter, GinkgoWriter)
 	if err != nil {
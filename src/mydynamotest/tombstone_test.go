@@ -0,0 +1,177 @@
+package mydynamotest
+
+import (
+	dy "mydynamo"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tombstones", func() {
+	var sc ServerCoordinator
+
+	AfterEach(func() {
+		sc.Kill()
+	})
+
+	// Returns the raw entries of the key on the ith server
+	getRaw := func(i int, key string) []dy.ObjectEntry {
+		var res dy.DynamoResult
+		Expect(sc.GetClient(i).GetRaw(key, &res)).To(BeTrue())
+		return res.EntryList
+	}
+
+	Describe("R=3, W=3, ClusterSize=3", func() {
+		BeforeEach(func() {
+			sc = NewServerCoordinator(8000+config.GinkgoConfig.ParallelNode*100, 3, 3, 3)
+		})
+
+		It("should hide deleted keys from Get on all servers.", func() {
+			Expect(sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))).To(BeTrue())
+			res := sc.GetClient(1).Get("k0")
+			Expect(res.EntryList).To(HaveLen(1))
+			Expect(sc.GetClient(1).Delete(dy.NewDeleteArgs("k0", res.EntryList[0].Context))).To(BeTrue())
+
+			for i := 0; i < 3; i++ {
+				Expect(GetEntryValues(sc.GetClient(i).Get("k0"))).To(BeEmpty())
+
+				entries := getRaw(i, "k0")
+				Expect(entries).To(HaveLen(1))
+				Expect(entries[0].Deleted).To(BeTrue())
+				Expect(entries[0].DeletedAt).To(BeNumerically(">", 0))
+			}
+		})
+
+		It("should keep values concurrent with the delete.", func() {
+			Expect(sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))).To(BeTrue())
+			res := sc.GetClient(0).Get("k0")
+			Expect(res.EntryList).To(HaveLen(1))
+			Expect(sc.GetClient(1).Put(MakePutFreshEntry("k0", []byte("v1")))).To(BeTrue())
+
+			Expect(sc.GetClient(2).Delete(dy.NewDeleteArgs("k0", res.EntryList[0].Context))).To(BeTrue())
+
+			for i := 0; i < 3; i++ {
+				Expect(GetEntryValues(sc.GetClient(i).Get("k0"))).To(ConsistOf([][]byte{
+					[]byte("v1"),
+				}))
+			}
+		})
+	})
+
+	Describe("R=1, W=1, ClusterSize=2", func() {
+		BeforeEach(func() {
+			sc = NewServerCoordinator(8000+config.GinkgoConfig.ParallelNode*100, 1, 1, 2)
+		})
+
+		It("should replicate tombstones by gossip.", func() {
+			Expect(sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))).To(BeTrue())
+			sc.GetClient(0).Gossip()
+			res := sc.GetClient(0).Get("k0")
+			Expect(res.EntryList).To(HaveLen(1))
+
+			Expect(sc.GetClient(0).Delete(dy.NewDeleteArgs("k0", res.EntryList[0].Context))).To(BeTrue())
+			Expect(GetEntryValues(sc.GetClient(1).Get("k0"))).To(ConsistOf([][]byte{
+				[]byte("v0"),
+			}))
+
+			sc.GetClient(0).Gossip()
+			Expect(GetEntryValues(sc.GetClient(1).Get("k0"))).To(BeEmpty())
+			Expect(getRaw(1, "k0")).To(HaveLen(1))
+		})
+	})
+
+	Describe("Garbage collection", func() {
+		const gracePeriodMs = 500
+
+		// Puts a value to the key through the ith server and deletes it
+		putAndDelete := func(i int, key string) {
+			Expect(sc.GetClient(i).Put(MakePutFreshEntry(key, []byte("v0")))).To(BeTrue())
+			sc.GetClient(i).Gossip()
+			res := sc.GetClient(i).Get(key)
+			Expect(res.EntryList).To(HaveLen(1))
+			Expect(sc.GetClient(i).Delete(dy.NewDeleteArgs(key, res.EntryList[0].Context))).To(BeTrue())
+		}
+
+		Context("with all replicas acknowledging the delete", func() {
+			BeforeEach(func() {
+				// StartingPort: 8000, R-Value: 1, W-Value: 3, ClusterSize: 3
+				sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 1, 3, 3, map[string]string{
+					dy.TOMBSTONE_GRACE_PERIOD: strconv.Itoa(gracePeriodMs),
+				})
+			})
+
+			It("should collect tombstones after the grace period.", func() {
+				putAndDelete(0, "k0")
+				Expect(sc.GetClient(0).CollectTombstones()).To(Equal(0))
+
+				time.Sleep(gracePeriodMs * time.Millisecond)
+				Expect(sc.GetClient(0).CollectTombstones()).To(Equal(1))
+				Expect(getRaw(0, "k0")).To(BeEmpty())
+
+				// Other servers have not put the tombstone to all replicas yet
+				Expect(sc.GetClient(1).CollectTombstones()).To(Equal(0))
+				Expect(getRaw(1, "k0")).To(HaveLen(1))
+
+				// Gossip does not bring back the collected tombstone
+				sc.GetClient(1).Gossip()
+				Expect(getRaw(0, "k0")).To(BeEmpty())
+				Expect(sc.GetClient(1).CollectTombstones()).To(Equal(1))
+				Expect(getRaw(1, "k0")).To(BeEmpty())
+				Expect(GetEntryValues(sc.GetClient(2).Get("k0"))).To(BeEmpty())
+			})
+		})
+
+		Context("with a replica missing the delete", func() {
+			BeforeEach(func() {
+				// StartingPort: 8000, R-Value: 1, W-Value: 2, ClusterSize: 3
+				sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 1, 2, 3, map[string]string{
+					dy.TOMBSTONE_GRACE_PERIOD: strconv.Itoa(gracePeriodMs),
+				})
+			})
+
+			It("should keep tombstones until the replica has them.", func() {
+				Expect(sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))).To(BeTrue())
+				sc.GetClient(0).Gossip()
+				res := sc.GetClient(0).Get("k0")
+				Expect(res.EntryList).To(HaveLen(1))
+
+				sc.GetClient(2).ForceCrash()
+				Expect(sc.GetClient(0).Delete(dy.NewDeleteArgs("k0", res.EntryList[0].Context))).To(BeTrue())
+
+				time.Sleep(gracePeriodMs * time.Millisecond)
+				Expect(sc.GetClient(0).CollectTombstones()).To(Equal(0))
+
+				sc.GetClient(2).ForceRestore()
+				Expect(GetEntryValues(sc.GetClient(2).Get("k0"))).To(ConsistOf([][]byte{
+					[]byte("v0"),
+				}))
+
+				sc.GetClient(0).Gossip()
+				Expect(GetEntryValues(sc.GetClient(2).Get("k0"))).To(BeEmpty())
+				Expect(sc.GetClient(0).CollectTombstones()).To(Equal(1))
+				Expect(getRaw(0, "k0")).To(BeEmpty())
+			})
+		})
+
+		Context("in the background", func() {
+			BeforeEach(func() {
+				sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 1, 3, 3, map[string]string{
+					dy.TOMBSTONE_GRACE_PERIOD: strconv.Itoa(gracePeriodMs),
+					dy.TOMBSTONE_GC_INTERVAL:  "100",
+				})
+			})
+
+			It("should collect tombstones periodically.", func() {
+				putAndDelete(0, "k0")
+				Expect(getRaw(0, "k0")).To(HaveLen(1))
+
+				Eventually(func() []dy.ObjectEntry {
+					return getRaw(0, "k0")
+				}, 5*time.Second).Should(BeEmpty())
+			})
+		})
+	})
+})
@@ -0,0 +1,191 @@
+package mydynamotest
+
+import (
+	dy "mydynamo"
+	"net/rpc"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Unreachable Servers", func() {
+	var startingPort int
+	var dynamoConfig dy.DynamoConfig
+	var servers []*dy.DynamoServer
+	var serveErrs []chan error
+
+	// Creates and serves `count` servers with the config, then sends them their preference lists
+	startServers := func(count int) {
+		nodes := make([]dy.DynamoNode, 0)
+		for i := 0; i < count; i++ {
+			port := strconv.Itoa(startingPort + i)
+			server := dy.NewDynamoServerWithConfig(dynamoConfig, "localhost", port, "s"+strconv.Itoa(i))
+			serveErr := make(chan error, 1)
+			go func() {
+				serveErr <- dy.ServeDynamoServer(server)
+			}()
+
+			servers = append(servers, &server)
+			serveErrs = append(serveErrs, serveErr)
+			nodes = append(nodes, dy.NewDynamoNode("localhost", port))
+		}
+
+		for _, node := range nodes {
+			var client *rpc.Client
+			Eventually(func() error {
+				var err error
+				client, err = rpc.DialHTTP("tcp", node.Address+":"+node.Port)
+				return err
+			}, 5*time.Second, 50*time.Millisecond).Should(Succeed())
+
+			var empty dy.Empty
+			Expect(client.Call("MyDynamo.SendPreferenceList", nodes, &empty)).To(Succeed())
+			Expect(client.Close()).To(Succeed())
+			nodes = dy.RotateServerList(nodes)
+		}
+	}
+
+	// Kills the listener of the i-th server together with its connections
+	killServer := func(i int) {
+		servers[i].Shutdown()
+		Eventually(serveErrs[i], 5*time.Second).Should(Receive(BeNil()))
+		servers[i] = nil
+	}
+
+	getClient := func(i int) *dy.RPCClient {
+		client := dy.NewDynamoRPCClient("localhost:" + strconv.Itoa(startingPort+i))
+		Expect(client.RpcConnect()).To(Succeed())
+		return client
+	}
+
+	node := func(i int) dy.DynamoNode {
+		return dy.NewDynamoNode("localhost", strconv.Itoa(startingPort+i))
+	}
+
+	BeforeEach(func() {
+		startingPort = 8050 + config.GinkgoConfig.ParallelNode*100
+		dynamoConfig = dy.NewDynamoConfig()
+		servers = make([]*dy.DynamoServer, 0)
+		serveErrs = make([]chan error, 0)
+	})
+
+	AfterEach(func() {
+		for i, server := range servers {
+			if server != nil {
+				server.Shutdown()
+				Eventually(serveErrs[i], 5*time.Second).Should(Receive(BeNil()))
+			}
+		}
+	})
+
+	It("should report unreachable servers on Put.", func() {
+		dynamoConfig.WValue = 3
+		startServers(3)
+		client0 := getClient(0)
+		defer client0.CleanConn()
+		killServer(2)
+
+		res := client0.PutWithResult(MakePutFreshEntry("k0", []byte("v0")))
+		Expect(res).NotTo(BeNil())
+		Expect(res.Success).To(BeFalse())
+		Expect(res.Acks).To(Equal(2))
+		Expect(res.Failures).To(HaveLen(1))
+		Expect(res.Failures[0].Node).To(Equal(node(2)))
+		Expect(res.Failures[0].Unreachable).To(BeTrue())
+
+		stats := client0.GetConnectionPoolStats()
+		Expect(stats).NotTo(BeNil())
+		Expect(stats.Unreachable).To(Equal(int64(1)))
+	})
+
+	It("should distinguish crashed and unreachable servers.", func() {
+		dynamoConfig.WValue = 3
+		startServers(3)
+		client0 := getClient(0)
+		defer client0.CleanConn()
+		client1 := getClient(1)
+		defer client1.CleanConn()
+		client1.ForceCrash()
+		killServer(2)
+
+		res := client0.PutWithResult(MakePutFreshEntry("k0", []byte("v0")))
+		Expect(res).NotTo(BeNil())
+		Expect(res.Success).To(BeFalse())
+		Expect(res.Acks).To(Equal(1))
+		unreachable := make(map[dy.DynamoNode]bool)
+		for _, failure := range res.Failures {
+			unreachable[failure.Node] = failure.Unreachable
+		}
+		Expect(unreachable).To(Equal(map[dy.DynamoNode]bool{
+			node(1): false,
+			node(2): true,
+		}))
+	})
+
+	It("should skip unreachable servers for quorum.", func() {
+		dynamoConfig.WValue = 2
+		dynamoConfig.RValue = 2
+		startServers(3)
+		client0 := getClient(0)
+		defer client0.CleanConn()
+		killServer(1)
+
+		res := client0.PutWithResult(MakePutFreshEntry("k0", []byte("v0")))
+		Expect(res).NotTo(BeNil())
+		Expect(res.Success).To(BeTrue())
+		Expect(res.Acks).To(Equal(2))
+
+		getRes := client0.Get("k0")
+		Expect(getRes).NotTo(BeNil())
+		Expect(GetEntryValues(getRes)).To(ConsistOf([][]byte{
+			[]byte("v0"),
+		}))
+		Expect(getRes.Failures).To(HaveLen(1))
+		Expect(getRes.Failures[0].Node).To(Equal(node(1)))
+		Expect(getRes.Failures[0].Unreachable).To(BeTrue())
+	})
+
+	It("should put hints for unreachable servers with sloppy quorum.", func() {
+		dynamoConfig.WValue = 2
+		dynamoConfig.NValue = 2
+		dynamoConfig.ClusterSize = 3
+		dynamoConfig.SloppyQuorum = true
+		dynamoConfig.HintedHandoffIntervalMs = 0
+		startServers(3)
+		client0 := getClient(0)
+		defer client0.CleanConn()
+		killServer(1)
+
+		// The top N nodes of k7 are s0 and s1
+		res := client0.PutWithResult(MakePutFreshEntry("k7", []byte("v0")))
+		Expect(res).NotTo(BeNil())
+		Expect(res.Success).To(BeTrue())
+
+		client2 := getClient(2)
+		defer client2.CleanConn()
+		hints := client2.GetHints()
+		Expect(hints).To(HaveLen(1))
+		Expect(hints[0].Owner).To(Equal(node(1)))
+	})
+
+	It("should keep serving after gossiping to an unreachable server.", func() {
+		startServers(2)
+		client0 := getClient(0)
+		defer client0.CleanConn()
+		Expect(client0.Put(MakePutFreshEntry("k0", []byte("v0")))).To(BeTrue())
+		killServer(1)
+
+		client0.Gossip()
+		client0.AntiEntropy()
+		Expect(client0.DeliverHints()).To(Equal(0))
+
+		res := client0.Get("k0")
+		Expect(res).NotTo(BeNil())
+		Expect(GetEntryValues(res)).To(ConsistOf([][]byte{
+			[]byte("v0"),
+		}))
+	})
+})
@@ -4,11 +4,24 @@ import (
 	"fmt"
 	dy "mydynamo"
 	"strings"
+	"testing"
 
 	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
 	. "github.com/onsi/gomega"
 )
 
+// Returns a new vector clock incremented at the comma separated node IDs
+func newIncrementedClock(increments string) dy.VectorClock {
+	vClock := dy.NewVectorClock()
+	for _, nodeID := range strings.Split(increments, ",") {
+		if nodeID != "" {
+			vClock.Increment(nodeID)
+		}
+	}
+	return vClock
+}
+
 var _ = Describe("VectorClock", func() {
 	Describe("Empty vector clocks", func() {
 		var vClock1 dy.VectorClock
@@ -418,4 +431,292 @@ var _ = Describe("VectorClock", func() {
 			})
 		})
 	})
+
+	Describe("Compare", func() {
+		testCases := [][]string{
+			{"", "", "equal"},
+			{"s1", "s1", "equal"},
+			{"", "s1", "before"},
+			{"s1", "s1,s1", "before"},
+			{"s1", "s1,s2", "before"},
+			{"s1,s1,s2", "s1", "after"},
+			{"s1", "s2", "concurrent"},
+			{"s1,s1,s2", "s1,s2,s2", "concurrent"},
+			{"s1,s2,s3", "s2,s2,s2,s3,s4,s5", "concurrent"},
+		}
+		orders := map[string]dy.ClockOrder{
+			"equal":      dy.CLOCK_EQUAL,
+			"before":     dy.CLOCK_BEFORE,
+			"after":      dy.CLOCK_AFTER,
+			"concurrent": dy.CLOCK_CONCURRENT,
+		}
+		reversedOrders := map[dy.ClockOrder]dy.ClockOrder{
+			dy.CLOCK_EQUAL:      dy.CLOCK_EQUAL,
+			dy.CLOCK_BEFORE:     dy.CLOCK_AFTER,
+			dy.CLOCK_AFTER:      dy.CLOCK_BEFORE,
+			dy.CLOCK_CONCURRENT: dy.CLOCK_CONCURRENT,
+		}
+
+		MapTestCases(testCases, func(i int, testCase []string) {
+			It(fmt.Sprintf("should order case %d like LessThan, Equals and Concurrent.", i+1), func() {
+				vClock1 := newIncrementedClock(testCase[0])
+				vClock2 := newIncrementedClock(testCase[1])
+
+				order := vClock1.Compare(vClock2)
+				Expect(order).To(Equal(orders[testCase[2]]))
+				Expect(vClock2.Compare(vClock1)).To(Equal(reversedOrders[order]))
+
+				Expect(order == dy.CLOCK_EQUAL).To(Equal(vClock1.Equals(vClock2)))
+				Expect(order == dy.CLOCK_BEFORE).To(Equal(vClock1.LessThan(vClock2)))
+				Expect(order == dy.CLOCK_AFTER).To(Equal(vClock2.LessThan(vClock1)))
+				Expect(order == dy.CLOCK_CONCURRENT).To(Equal(vClock1.Concurrent(vClock2)))
+			})
+		})
+
+		It("should order a missing element before an element of zero.", func() {
+			vClock := NewVectorClockFromMap(map[string]uint64{"s1": 0})
+			Expect(dy.NewVectorClock().Compare(vClock)).To(Equal(dy.CLOCK_BEFORE))
+			Expect(dy.NewVectorClock().LessThan(vClock)).To(BeTrue())
+		})
+	})
+
+	Describe("Merge", func() {
+		It("should return a clock descended from both clocks without changing them.", func() {
+			vClock1 := newIncrementedClock("s1,s1,s2")
+			vClock2 := newIncrementedClock("s2,s2,s3")
+
+			merged := vClock1.Merge(vClock2)
+			Expect(merged.NodeClocks).To(Equal(map[string]uint64{"s1": 2, "s2": 2, "s3": 1}))
+			Expect(merged.Timestamps).To(HaveLen(3))
+			Expect(vClock1.Compare(merged)).To(Equal(dy.CLOCK_BEFORE))
+			Expect(vClock2.Compare(merged)).To(Equal(dy.CLOCK_BEFORE))
+
+			Expect(vClock1.NodeClocks).To(Equal(map[string]uint64{"s1": 2, "s2": 1}))
+			Expect(vClock2.NodeClocks).To(Equal(map[string]uint64{"s2": 2, "s3": 1}))
+			merged.Increment("s1")
+			Expect(vClock1.NodeClocks["s1"]).To(Equal(uint64(2)))
+		})
+
+		It("should equal Combine.", func() {
+			vClock1 := newIncrementedClock("s1,s2,s3")
+			vClock2 := newIncrementedClock("s2,s2,s4")
+			merged := vClock1.Merge(vClock2)
+
+			vClock1.Combine([]dy.VectorClock{vClock2})
+			Expect(merged.Equals(vClock1)).To(BeTrue())
+		})
+	})
+
+	Describe("Pruned vector clocks", func() {
+		// Returns a vector clock with the given clocks, whose timestamps are their order in nodeIDs
+		newClock := func(nodeIDs []string, clocks []uint64) dy.VectorClock {
+			vClock := dy.NewVectorClock()
+			for i, nodeID := range nodeIDs {
+				vClock.NodeClocks[nodeID] = clocks[i]
+				vClock.Timestamps[nodeID] = int64(i + 1)
+			}
+			return vClock
+		}
+
+		It("should not change within the maximum number of entries.", func() {
+			vClock := newClock([]string{"s1", "s2"}, []uint64{1, 2})
+			vClock.Prune(2)
+			Expect(vClock.Equals(newClock([]string{"s1", "s2"}, []uint64{1, 2}))).To(BeTrue())
+		})
+
+		It("should remove the least recently updated entries.", func() {
+			vClock := newClock([]string{"s3", "s1", "s2"}, []uint64{5, 1, 1})
+			vClock.Prune(2)
+			Expect(vClock.NodeClocks).To(Equal(map[string]uint64{"s1": 1, "s2": 1}))
+			Expect(vClock.Timestamps).To(HaveLen(2))
+		})
+
+		It("should remove the entries without timestamps first.", func() {
+			vClock := NewVectorClockFromMap(map[string]uint64{"s1": 1, "s2": 1})
+			vClock.Increment("s3")
+			vClock.Prune(2)
+			Expect(vClock.NodeClocks).To(Equal(map[string]uint64{"s2": 1, "s3": 1}))
+		})
+
+		It("should ignore timestamps when compared.", func() {
+			vClock1 := newClock([]string{"s1", "s2"}, []uint64{1, 1})
+			vClock2 := newClock([]string{"s2", "s1"}, []uint64{1, 1})
+			Expect(vClock1.Equals(vClock2)).To(BeTrue())
+			Expect(vClock1.Concurrent(vClock2)).To(BeFalse())
+		})
+
+		Context("after incrementing a descendant past the maximum number of entries", func() {
+			var ancestor dy.VectorClock
+			var descendant dy.VectorClock
+
+			BeforeEach(func() {
+				ancestor = newClock([]string{"s1", "s2", "s3"}, []uint64{1, 1, 1})
+				descendant = dy.NewVectorClock()
+				descendant.Combine([]dy.VectorClock{ancestor})
+				descendant.Increment("s4")
+			})
+
+			It("should descend from the ancestor without pruning.", func() {
+				Expect(ancestor.LessThan(descendant)).To(BeTrue())
+			})
+
+			It("should be falsely concurrent to the ancestor after pruning.", func() {
+				descendant.Prune(3)
+				Expect(descendant.NodeClocks).To(Equal(map[string]uint64{"s2": 1, "s3": 1, "s4": 1}))
+				Expect(ancestor.LessThan(descendant)).To(BeFalse())
+				Expect(ancestor.Concurrent(descendant)).To(BeTrue())
+			})
+
+			It("should still descend from the ancestors sharing the remaining entries.", func() {
+				descendant.Prune(3)
+				Expect(newClock([]string{"s2", "s3"}, []uint64{1, 1}).LessThan(descendant)).To(BeTrue())
+			})
+		})
+
+		Context("after pruning the clocks of two updates", func() {
+			It("should be falsely equal if they only differ in the pruned entries.", func() {
+				vClock1 := newClock([]string{"s1", "s2", "s3"}, []uint64{1, 1, 1})
+				vClock2 := newClock([]string{"s1", "s2", "s3"}, []uint64{2, 1, 1})
+				Expect(vClock1.LessThan(vClock2)).To(BeTrue())
+
+				vClock1.Prune(2)
+				vClock2.Prune(2)
+				Expect(vClock1.Equals(vClock2)).To(BeTrue())
+			})
+		})
+
+		Describe("in puts with R=3, W=3, ClusterSize=3", func() {
+			var sc ServerCoordinator
+			var maxEntries string
+
+			JustBeforeEach(func() {
+				sc = NewServerCoordinatorWithOptions(8000+config.GinkgoConfig.ParallelNode*100, 3, 3, 3, map[string]string{
+					dy.VECTOR_CLOCK_MAX_ENTRIES: maxEntries,
+				})
+			})
+
+			AfterEach(func() {
+				sc.Kill()
+			})
+
+			// Puts v0 through s0, then v1 through s1 with the context of v0, and returns the siblings of the key
+			putTwice := func() *dy.DynamoResult {
+				Expect(sc.GetClient(0).Put(MakePutFreshEntry("k0", []byte("v0")))).To(BeTrue())
+				res := sc.GetClient(1).Get("k0")
+				Expect(res.EntryList).To(HaveLen(1))
+				Expect(sc.GetClient(1).Put(MakePutFromEntry("k0", dy.ObjectEntry{
+					Context: res.EntryList[0].Context,
+					Value:   []byte("v1"),
+				}))).To(BeTrue())
+				return sc.GetClient(2).Get("k0")
+			}
+
+			Context("with enough entries", func() {
+				BeforeEach(func() {
+					maxEntries = "2"
+				})
+
+				It("should replace the older value.", func() {
+					res := putTwice()
+					Expect(GetEntryValues(res)).To(ConsistOf([][]byte{
+						[]byte("v1"),
+					}))
+					Expect(res.EntryList[0].Context.Clock.NodeClocks).To(HaveLen(2))
+				})
+
+				It("should remove the entry of the least recently updated server of the key.", func() {
+					// Updates the key through the server with the context of its value
+					putThrough := func(key string, i int, value string) {
+						res := sc.GetClient(i).Get(key)
+						context := dy.NewContext(dy.NewVectorClock())
+						if len(res.EntryList) > 0 {
+							context = res.EntryList[0].Context
+						}
+						Expect(sc.GetClient(i).Put(MakePutFromEntry(key, dy.ObjectEntry{
+							Context: context,
+							Value:   []byte(value),
+						}))).To(BeTrue())
+					}
+
+					// s0 has updated k1 more often than k0 before the other servers update k0
+					for i := 0; i < 3; i++ {
+						putThrough("k1", 0, "v")
+					}
+					putThrough("k0", 1, "v0")
+					putThrough("k0", 0, "v1")
+					putThrough("k0", 2, "v2")
+
+					// The entry of s1 is the least recently updated one in the clock of k0, whatever s0 did to k1
+					res := sc.GetClient(0).Get("k0")
+					Expect(GetEntryValues(res)).To(ContainElement([]byte("v2")))
+					for _, entry := range res.EntryList {
+						if string(entry.Value) == "v2" {
+							Expect(entry.Context.Clock.NodeClocks).To(Equal(map[string]uint64{
+								sc.GetID(0): 1,
+								sc.GetID(2): 1,
+							}))
+						}
+					}
+				})
+			})
+
+			Context("with too few entries", func() {
+				BeforeEach(func() {
+					maxEntries = "1"
+				})
+
+				It("should keep the older value as a false conflict.", func() {
+					res := putTwice()
+					Expect(GetEntryValues(res)).To(ConsistOf([][]byte{
+						[]byte("v0"),
+						[]byte("v1"),
+					}))
+					for _, entry := range res.EntryList {
+						Expect(entry.Context.Clock.NodeClocks).To(HaveLen(1))
+					}
+				})
+			})
+		})
+	})
 })
+
+// Returns two concurrent vector clocks of the nodes, which only differ in the clocks of the last two nodes
+func newConcurrentClocks(nodesNum int) (dy.VectorClock, dy.VectorClock) {
+	vClock1 := dy.NewVectorClock()
+	for i := 0; i < nodesNum; i++ {
+		vClock1.Increment(fmt.Sprintf("s%d", i))
+	}
+	vClock2 := vClock1.Merge(dy.NewVectorClock())
+	vClock1.Increment(fmt.Sprintf("s%d", nodesNum-2))
+	vClock2.Increment(fmt.Sprintf("s%d", nodesNum-1))
+	return vClock1, vClock2
+}
+
+// Classifies concurrent clocks by chaining LessThan, Equals and Concurrent, as the reconciliation in PutRaw did
+func BenchmarkVectorClockChainedComparison(b *testing.B) {
+	for _, nodesNum := range []int{10, 100, 1000} {
+		vClock1, vClock2 := newConcurrentClocks(nodesNum)
+		b.Run(fmt.Sprintf("%d nodes", nodesNum), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if vClock1.LessThan(vClock2) || vClock1.Equals(vClock2) || vClock2.LessThan(vClock1) ||
+					!vClock1.Concurrent(vClock2) {
+					b.Fatal("clocks are not concurrent")
+				}
+			}
+		})
+	}
+}
+
+// Classifies concurrent clocks with Compare
+func BenchmarkVectorClockCompare(b *testing.B) {
+	for _, nodesNum := range []int{10, 100, 1000} {
+		vClock1, vClock2 := newConcurrentClocks(nodesNum)
+		b.Run(fmt.Sprintf("%d nodes", nodesNum), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if vClock1.Compare(vClock2) != dy.CLOCK_CONCURRENT {
+					b.Fatal("clocks are not concurrent")
+				}
+			}
+		})
+	}
+}
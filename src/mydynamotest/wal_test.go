@@ -0,0 +1,195 @@
+package mydynamotest
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io/ioutil"
+	dy "mydynamo"
+	"os"
+	"path/filepath"
+	"sort"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Write-Ahead Log", func() {
+
+	var dataDir string
+
+	// Create a server persisting its data in dataDir and recover its state from the disk
+	startServer := func() *dy.DynamoServer {
+		config := dy.NewDynamoConfig()
+		config.DataDir = dataDir
+
+		server := dy.NewDynamoServerWithConfig(config, "localhost", "0", "s0")
+		Expect(server.Recover()).To(Succeed())
+		return &server
+	}
+
+	put := func(server *dy.DynamoServer, putArgs dy.PutArgs) {
+		var result bool
+		Expect(server.Put(putArgs, &result)).To(Succeed())
+		Expect(result).To(BeTrue())
+	}
+
+	get := func(server *dy.DynamoServer, key string) *dy.DynamoResult {
+		var result dy.DynamoResult
+		Expect(server.Get(key, &result)).To(Succeed())
+		return &result
+	}
+
+	// Returns the path of the write-ahead log segment that records are appended to
+	activeWALSegmentPath := func() string {
+		segmentPaths, err := filepath.Glob(filepath.Join(dataDir, "s0", "wal-*.log"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(segmentPaths).NotTo(BeEmpty())
+		sort.Strings(segmentPaths)
+		return segmentPaths[len(segmentPaths)-1]
+	}
+
+	BeforeEach(func() {
+		var err error
+		dataDir, err = ioutil.TempDir("", "mydynamo-wal-test")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		var _ = os.RemoveAll(dataDir)
+	})
+
+	It("should restore entries after restart.", func() {
+		server := startServer()
+		put(server, MakePutFreshEntry("k0", []byte("v0")))
+		put(server, MakePutFreshEntry("k1", []byte("v1")))
+
+		server = startServer()
+		Expect(GetEntryValues(get(server, "k0"))).To(ConsistOf([][]byte{
+			[]byte("v0"),
+		}))
+		Expect(GetEntryValues(get(server, "k1"))).To(ConsistOf([][]byte{
+			[]byte("v1"),
+		}))
+	})
+
+	It("should restore the vector clocks of entries after restart.", func() {
+		server := startServer()
+		put(server, MakePutFreshEntry("k0", []byte("v0")))
+
+		server = startServer()
+		clocks := GetEntryContextClocks(get(server, "k0"))
+		Expect(clocks).To(HaveLen(1))
+		Expect(clocks[0].Equals(NewVectorClockFromMap(map[string]uint64{"s0": 1}))).To(BeTrue())
+	})
+
+	It("should not restore superseded entries after restart.", func() {
+		server := startServer()
+		put(server, MakePutFreshEntry("k0", []byte("v0-0")))
+		put(server, MakePutFromVectorClockMapAndValue("k0", map[string]uint64{"s0": 1}, []byte("v0-1")))
+
+		server = startServer()
+		Expect(GetEntryValues(get(server, "k0"))).To(ConsistOf([][]byte{
+			[]byte("v0-1"),
+		}))
+	})
+
+	It("should restore concurrent entries after restart.", func() {
+		server := startServer()
+		var result bool
+		Expect(server.PutRaw(MakePutFromVectorClockMapAndValue(
+			"k0", map[string]uint64{"s1": 1}, []byte("v0-1"),
+		), &result)).To(Succeed())
+		Expect(server.PutRaw(MakePutFromVectorClockMapAndValue(
+			"k0", map[string]uint64{"s2": 1}, []byte("v0-2"),
+		), &result)).To(Succeed())
+
+		server = startServer()
+		Expect(GetEntryValues(get(server, "k0"))).To(ConsistOf([][]byte{
+			[]byte("v0-1"),
+			[]byte("v0-2"),
+		}))
+	})
+
+	It("should ignore a torn record at the tail of the log and keep appending.", func() {
+		server := startServer()
+		put(server, MakePutFreshEntry("k0", []byte("v0")))
+		put(server, MakePutFreshEntry("k1", []byte("v1")))
+
+		// Cut the last record in the middle, as if the process was killed during the append
+		walPath := activeWALSegmentPath()
+		info, err := os.Stat(walPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Truncate(walPath, info.Size()-3)).To(Succeed())
+
+		server = startServer()
+		Expect(GetEntryValues(get(server, "k0"))).To(ConsistOf([][]byte{
+			[]byte("v0"),
+		}))
+		Expect(GetEntryValues(get(server, "k1"))).To(ConsistOf([][]byte{}))
+
+		put(server, MakePutFreshEntry("k2", []byte("v2")))
+
+		server = startServer()
+		Expect(GetEntryValues(get(server, "k0"))).To(ConsistOf([][]byte{
+			[]byte("v0"),
+		}))
+		Expect(GetEntryValues(get(server, "k2"))).To(ConsistOf([][]byte{
+			[]byte("v2"),
+		}))
+	})
+
+	It("should ignore a corrupted record at the tail of the log.", func() {
+		server := startServer()
+		put(server, MakePutFreshEntry("k0", []byte("v0")))
+		put(server, MakePutFreshEntry("k1", []byte("v1")))
+
+		// Flip the last byte of the log so the checksum of the last record mismatches
+		walPath := activeWALSegmentPath()
+		data, err := ioutil.ReadFile(walPath)
+		Expect(err).NotTo(HaveOccurred())
+		data[len(data)-1] ^= 0xff
+		Expect(ioutil.WriteFile(walPath, data, 0644)).To(Succeed())
+
+		server = startServer()
+		Expect(GetEntryValues(get(server, "k0"))).To(ConsistOf([][]byte{
+			[]byte("v0"),
+		}))
+		Expect(GetEntryValues(get(server, "k1"))).To(ConsistOf([][]byte{}))
+	})
+
+	It("should refuse to recover from a record that cannot be decoded.", func() {
+		server := startServer()
+		put(server, MakePutFreshEntry("k0", []byte("v0")))
+
+		// Append a record with a valid checksum whose payload is not a gob-encoded PutArgs
+		payload := []byte("not a PutArgs")
+		record := make([]byte, 8, 8+len(payload))
+		binary.BigEndian.PutUint32(record[0:4], crc32.Checksum(payload, crc32.MakeTable(crc32.Castagnoli)))
+		binary.BigEndian.PutUint32(record[4:8], uint32(len(payload)))
+		walPath := activeWALSegmentPath()
+		data, err := ioutil.ReadFile(walPath)
+		Expect(err).NotTo(HaveOccurred())
+		data = append(data, append(record, payload...)...)
+		Expect(ioutil.WriteFile(walPath, data, 0644)).To(Succeed())
+
+		config := dy.NewDynamoConfig()
+		config.DataDir = dataDir
+		server2 := dy.NewDynamoServerWithConfig(config, "localhost", "0", "s0")
+		Expect(server2.Recover()).NotTo(Succeed())
+
+		// The log is left as it was
+		recovered, err := ioutil.ReadFile(walPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recovered).To(Equal(data))
+	})
+
+	It("should not persist anything when data directory is not configured.", func() {
+		server := dy.NewDynamoServer(1, 1, "localhost", "0", "s0")
+		Expect(server.Recover()).To(Succeed())
+		put(&server, MakePutFreshEntry("k0", []byte("v0")))
+
+		files, err := ioutil.ReadDir(dataDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(BeEmpty())
+	})
+})